@@ -0,0 +1,34 @@
+// Package audit records who changed what. Repositories write events with repository.Record
+// in the transaction making the change; handlers describe the request with OriginFromContext.
+package audit
+
+import (
+	"go-backend-valos-id/core/audit/model"
+	"go-backend-valos-id/core/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRequestIDLength bounds client supplied X-Request-ID values stored with events
+const maxRequestIDLength = 64
+
+// OriginFromContext describes the request behind c: its authenticated principal, if any,
+// the request ID set by middleware.RequestID, the client IP and the user agent
+func OriginFromContext(c *gin.Context) *model.Origin {
+	origin := &model.Origin{
+		RequestID: middleware.GetRequestID(c),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if len(origin.RequestID) > maxRequestIDLength {
+		origin.RequestID = origin.RequestID[:maxRequestIDLength]
+	}
+
+	if principal, ok := middleware.GetPrincipal(c); ok {
+		origin.ActorID = &principal.UserID
+		if principal.APIKeyID != 0 {
+			origin.ActorAPIKeyID = &principal.APIKeyID
+		}
+	}
+	return origin
+}
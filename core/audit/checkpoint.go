@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go-backend-valos-id/core/audit/model"
+	"go-backend-valos-id/core/audit/repository"
+)
+
+// Signer signs checkpoints; CheckpointKeys signs them with the audit checkpoint key
+type Signer interface {
+	Issuer() string
+	SignClaims(claims any) (string, error)
+}
+
+// Checkpointer periodically signs the hash of the newest audit event, so that the chain up to it can
+// be verified against a copy kept outside the database
+type Checkpointer struct {
+	auditRepo *repository.AuditRepository
+	signer    Signer
+	interval  time.Duration
+}
+
+func NewCheckpointer(auditRepo *repository.AuditRepository, signer Signer, interval time.Duration) *Checkpointer {
+	return &Checkpointer{
+		auditRepo: auditRepo,
+		signer:    signer,
+		interval:  interval,
+	}
+}
+
+// Checkpoint signs the newest chained event. It returns nil when there is none or the latest checkpoint already covers it.
+func (c *Checkpointer) Checkpoint() (*model.Checkpoint, error) {
+	eventID, hash, err := c.auditRepo.LastEvent()
+	if err != nil {
+		return nil, err
+	}
+	if hash == "" {
+		return nil, nil
+	}
+
+	latest, err := c.auditRepo.GetLatestCheckpoint()
+	if err != nil && !errors.Is(err, repository.ErrCheckpointNotFound) {
+		return nil, err
+	}
+	if latest != nil && latest.EventID == eventID {
+		return nil, nil
+	}
+
+	now := time.Now()
+	signature, err := c.signer.SignClaims(model.CheckpointClaims{
+		Issuer:    c.signer.Issuer(),
+		IssuedAt:  now.Unix(),
+		Purpose:   model.CheckpointPurpose,
+		EventID:   eventID,
+		EventHash: hash,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint := &model.Checkpoint{
+		EventID:   eventID,
+		EventHash: hash,
+		Signature: signature,
+		CreatedAt: now,
+	}
+	if err := c.auditRepo.CreateCheckpoint(checkpoint); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// Run makes a checkpoint every interval until ctx is cancelled. It returns at once if the interval is 0.
+func (c *Checkpointer) Run(ctx context.Context) {
+	if c.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := c.Checkpoint(); err != nil {
+			log.Printf("Failed to create audit checkpoint: %v", err)
+		}
+	}
+}
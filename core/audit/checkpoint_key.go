@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"go-backend-valos-id/core/audit/model"
+	"go-backend-valos-id/core/auth/token"
+	"go-backend-valos-id/core/config"
+)
+
+var (
+	// ErrNoCheckpointKey is returned when signing a checkpoint without AUDIT_CHECKPOINT_KEY_FILE
+	ErrNoCheckpointKey = errors.New("no audit checkpoint key is configured")
+	// ErrInvalidCheckpoint is returned for checkpoints whose signature does not hold
+	ErrInvalidCheckpoint = errors.New("invalid audit checkpoint signature")
+)
+
+// CheckpointKeys signs checkpoints and verifies them against trusted Ed25519 keys. The keys are kept
+// apart from the token signing keys, which are rotated and deleted: a checkpoint must stay verifiable for
+// as long as the audit log is kept, including by someone holding only an export and the public key.
+type CheckpointKeys struct {
+	issuer  string
+	signing *token.Key
+	trusted []*token.Key
+}
+
+// NewCheckpointKeys signs with signing, which may be nil to only verify, and trusts its public key and trusted
+func NewCheckpointKeys(issuer string, signing *token.Key, trusted []*token.Key) *CheckpointKeys {
+	keys := &CheckpointKeys{issuer: issuer, signing: signing}
+	if signing != nil {
+		keys.trusted = append(keys.trusted, signing)
+	}
+	keys.trusted = append(keys.trusted, trusted...)
+	return keys
+}
+
+// NewCheckpointKeysFromConfig loads the signing key from AUDIT_CHECKPOINT_KEY_FILE and the further
+// trusted public keys from AUDIT_CHECKPOINT_PUBLIC_KEYS_FILE. Without a signing key checkpoints can
+// only be verified.
+func NewCheckpointKeysFromConfig(cfg *config.AuditConfig, issuer string) (*CheckpointKeys, error) {
+	var signing *token.Key
+	if cfg.CheckpointKeyFile != "" {
+		signer, err := token.LoadPrivateKey(cfg.CheckpointKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := token.CheckKeyType(token.AlgEdDSA, signer); err != nil {
+			return nil, fmt.Errorf("AUDIT_CHECKPOINT_KEY_FILE: %w", err)
+		}
+		signing = newCheckpointKey(signer.Public().(ed25519.PublicKey))
+		signing.Private = signer
+	} else if cfg.CheckpointInterval > 0 {
+		log.Println("AUDIT_CHECKPOINT_KEY_FILE is not set, audit checkpoints are disabled")
+	}
+
+	var trusted []*token.Key
+	if cfg.CheckpointPublicKeysFile != "" {
+		var err error
+		trusted, err = loadPublicKeys(cfg.CheckpointPublicKeysFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return NewCheckpointKeys(issuer, signing, trusted), nil
+}
+
+// CanSign reports whether a signing key is configured
+func (k *CheckpointKeys) CanSign() bool {
+	return k.signing != nil
+}
+
+func (k *CheckpointKeys) Issuer() string {
+	return k.issuer
+}
+
+// SignClaims signs claims as a compact JWS carrying the key ID of the signing key
+func (k *CheckpointKeys) SignClaims(claims any) (string, error) {
+	if k.signing == nil {
+		return "", ErrNoCheckpointKey
+	}
+	return token.Sign(claims, k.signing)
+}
+
+func (k *CheckpointKeys) SigningKey() (*token.Key, error) {
+	if k.signing == nil {
+		return nil, ErrNoCheckpointKey
+	}
+	return k.signing, nil
+}
+
+// VerificationKey returns the trusted key with ID kid. Checkpoints always name their key.
+func (k *CheckpointKeys) VerificationKey(kid string) (*token.Key, error) {
+	for _, key := range k.trusted {
+		if key.ID == kid {
+			return key, nil
+		}
+	}
+	return nil, token.ErrUnknownKey
+}
+
+func (k *CheckpointKeys) VerificationKeys() ([]*token.Key, error) {
+	return k.trusted, nil
+}
+
+// PublicKeys returns the trusted keys as a JWK set, for exports
+func (k *CheckpointKeys) PublicKeys() *token.JWKSet {
+	set := &token.JWKSet{Keys: []token.JWK{}}
+	for _, key := range k.trusted {
+		if jwk, ok := token.NewJWK(key); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// VerifyCheckpoint checks that the signature of checkpoint was made with a trusted key over its event ID and hash
+func (k *CheckpointKeys) VerifyCheckpoint(checkpoint *model.Checkpoint) error {
+	var claims model.CheckpointClaims
+	if err := token.Parse(checkpoint.Signature, k, &claims); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCheckpoint, err)
+	}
+	if claims.Purpose != model.CheckpointPurpose || claims.EventID != checkpoint.EventID || claims.EventHash != checkpoint.EventHash {
+		return ErrInvalidCheckpoint
+	}
+	return nil
+}
+
+// newCheckpointKey returns the verification key of pub, identified by its JWK thumbprint
+func newCheckpointKey(pub ed25519.PublicKey) *token.Key {
+	key := &token.Key{
+		Algorithm: token.AlgEdDSA,
+		Public:    pub,
+	}
+	key.ID, _ = token.Thumbprint(key)
+	return key
+}
+
+// loadPublicKeys reads every PEM encoded Ed25519 public key in path
+func loadPublicKeys(path string) ([]*token.Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit checkpoint public keys: %w", err)
+	}
+
+	var keys []*token.Key
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse audit checkpoint public key: %w", err)
+		}
+		pub, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("audit checkpoint public keys must be Ed25519, got %T", parsed)
+		}
+		keys = append(keys, newCheckpointKey(pub))
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no PEM public keys found in %s", path)
+	}
+	return keys, nil
+}
@@ -0,0 +1,186 @@
+package audit_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-backend-valos-id/core/audit"
+	"go-backend-valos-id/core/audit/model"
+	"go-backend-valos-id/core/config"
+)
+
+// writeKey writes a new Ed25519 private key and its public key as PEM files and returns their paths
+func writeKey(t *testing.T) (string, string) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "checkpoint.pem")
+	publicPath := filepath.Join(dir, "checkpoint.pub.pem")
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return privatePath, publicPath
+}
+
+func newKeys(t *testing.T, cfg *config.AuditConfig) *audit.CheckpointKeys {
+	t.Helper()
+	keys, err := audit.NewCheckpointKeysFromConfig(cfg, "valos-id")
+	if err != nil {
+		t.Fatalf("NewCheckpointKeysFromConfig: %v", err)
+	}
+	return keys
+}
+
+// sign returns a checkpoint of eventID and hash signed with keys
+func sign(t *testing.T, keys *audit.CheckpointKeys, eventID int64, hash string) *model.Checkpoint {
+	t.Helper()
+	signature, err := keys.SignClaims(model.CheckpointClaims{
+		Issuer:    keys.Issuer(),
+		Purpose:   model.CheckpointPurpose,
+		EventID:   eventID,
+		EventHash: hash,
+	})
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	return &model.Checkpoint{ID: 1, EventID: eventID, EventHash: hash, Signature: signature}
+}
+
+func TestVerifyCheckpoint(t *testing.T) {
+	signingPath, publicPath := writeKey(t)
+	otherPath, otherPublicPath := writeKey(t)
+	signing := newKeys(t, &config.AuditConfig{CheckpointKeyFile: signingPath})
+	other := newKeys(t, &config.AuditConfig{CheckpointKeyFile: otherPath})
+
+	tests := []struct {
+		name       string
+		verifier   *audit.CheckpointKeys
+		checkpoint func() *model.Checkpoint
+		wantErr    bool
+	}{
+		{
+			name:       "signing key",
+			verifier:   signing,
+			checkpoint: func() *model.Checkpoint { return sign(t, signing, 5, "hash") },
+		},
+		{
+			name:       "public key only",
+			verifier:   newKeys(t, &config.AuditConfig{CheckpointPublicKeysFile: publicPath}),
+			checkpoint: func() *model.Checkpoint { return sign(t, signing, 5, "hash") },
+		},
+		{
+			name:       "former key",
+			verifier:   newKeys(t, &config.AuditConfig{CheckpointKeyFile: signingPath, CheckpointPublicKeysFile: otherPublicPath}),
+			checkpoint: func() *model.Checkpoint { return sign(t, other, 5, "hash") },
+		},
+		{
+			name:       "another key",
+			verifier:   signing,
+			checkpoint: func() *model.Checkpoint { return sign(t, other, 5, "hash") },
+			wantErr:    true,
+		},
+		{
+			name:       "no trusted key",
+			verifier:   audit.NewCheckpointKeys("valos-id", nil, nil),
+			checkpoint: func() *model.Checkpoint { return sign(t, signing, 5, "hash") },
+			wantErr:    true,
+		},
+		{
+			name:     "hash changed",
+			verifier: signing,
+			checkpoint: func() *model.Checkpoint {
+				checkpoint := sign(t, signing, 5, "hash")
+				checkpoint.EventHash = "rewritten"
+				return checkpoint
+			},
+			wantErr: true,
+		},
+		{
+			name:     "event changed",
+			verifier: signing,
+			checkpoint: func() *model.Checkpoint {
+				checkpoint := sign(t, signing, 5, "hash")
+				checkpoint.EventID = 4
+				return checkpoint
+			},
+			wantErr: true,
+		},
+		{
+			name:     "signature of another checkpoint",
+			verifier: signing,
+			checkpoint: func() *model.Checkpoint {
+				checkpoint := sign(t, signing, 5, "hash")
+				checkpoint.Signature = sign(t, signing, 6, "next").Signature
+				return checkpoint
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.verifier.VerifyCheckpoint(tt.checkpoint())
+			if tt.wantErr {
+				if !errors.Is(err, audit.ErrInvalidCheckpoint) {
+					t.Fatalf("VerifyCheckpoint error = %v, want %v", err, audit.ErrInvalidCheckpoint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyCheckpoint: %v", err)
+			}
+		})
+	}
+}
+
+func TestSignWithoutKey(t *testing.T) {
+	keys := newKeys(t, &config.AuditConfig{})
+	if keys.CanSign() {
+		t.Fatal("CanSign = true without a key")
+	}
+	if _, err := keys.SignClaims(model.CheckpointClaims{}); !errors.Is(err, audit.ErrNoCheckpointKey) {
+		t.Fatalf("SignClaims error = %v, want %v", err, audit.ErrNoCheckpointKey)
+	}
+}
+
+func TestRejectsNonEd25519Key(t *testing.T) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "checkpoint.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := audit.NewCheckpointKeysFromConfig(&config.AuditConfig{CheckpointKeyFile: path}, "valos-id"); err == nil {
+		t.Fatal("NewCheckpointKeysFromConfig accepted a P-256 key")
+	}
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"go-backend-valos-id/core/audit/model"
+	"go-backend-valos-id/core/audit/repository"
+	"go-backend-valos-id/core/auth/token"
+)
+
+// Export is a self-contained copy of the audit log. It holds every event, every checkpoint and the public
+// checkpoint keys, so the chain can be verified without the database. Verifiers should trust the keys
+// they already hold, compared by key ID, rather than the ones in the export.
+type Export struct {
+	Keys        *token.JWKSet      `json:"keys"`
+	Checkpoints []model.Checkpoint `json:"checkpoints"`
+	Events      []*model.Event     `json:"events"`
+}
+
+// NewExport reads the whole audit log from auditRepo
+func NewExport(auditRepo *repository.AuditRepository, keys *CheckpointKeys) (*Export, error) {
+	checkpoints, err := auditRepo.ListCheckpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*model.Event{}
+	err = auditRepo.WalkEvents(func(event *model.Event) (bool, error) {
+		events = append(events, event)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Export{
+		Keys:        keys.PublicKeys(),
+		Checkpoints: checkpoints,
+		Events:      events,
+	}, nil
+}
+
+// Verify walks the exported chain like repository.AuditRepository.VerifyChain, checking checkpoint
+// signatures with verifier
+func (e *Export) Verify(verifier model.CheckpointVerifier) (*model.Verification, error) {
+	chain := model.NewChainVerifier(e.Checkpoints, verifier)
+	for _, event := range e.Events {
+		more, err := chain.Add(event)
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			break
+		}
+	}
+	return chain.Result(), nil
+}
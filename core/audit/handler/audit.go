@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-backend-valos-id/core/audit/model"
+	"go-backend-valos-id/core/audit/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// AuditHandler serves the audit log to administrators
+type AuditHandler struct {
+	auditRepo   *repository.AuditRepository
+	checkpoints model.CheckpointVerifier
+}
+
+func NewAuditHandler(auditRepo *repository.AuditRepository, checkpoints model.CheckpointVerifier) *AuditHandler {
+	return &AuditHandler{
+		auditRepo:   auditRepo,
+		checkpoints: checkpoints,
+	}
+}
+
+// ListEvents lists audit events newest first, filtered by actor_id, action, target_type, target_id and
+// an RFC 3339 from/to time range. A page that is followed by more events carries a next_cursor to pass
+// as cursor for the next one.
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	filter, err := h.parseFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Fetching one event more than asked tells whether another page follows
+	pageSize := filter.Limit
+	filter.Limit++
+	events, err := h.auditRepo.ListEvents(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve audit events",
+		})
+		return
+	}
+
+	var nextCursor *string
+	if len(events) > pageSize {
+		events = events[:pageSize]
+		cursor := encodeCursor(events[pageSize-1].ID)
+		nextCursor = &cursor
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        events,
+		"count":       len(events),
+		"next_cursor": nextCursor,
+	})
+}
+
+// VerifyChain walks the hash chain of the audit log and reports the first break, if any
+func (h *AuditHandler) VerifyChain(c *gin.Context) {
+	verification, err := h.auditRepo.VerifyChain(h.checkpoints)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to verify audit log",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": verification,
+	})
+}
+
+// ListCheckpoints lists the signed checkpoints of the audit log, oldest first
+func (h *AuditHandler) ListCheckpoints(c *gin.Context) {
+	checkpoints, err := h.auditRepo.ListCheckpoints()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve audit checkpoints",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  checkpoints,
+		"count": len(checkpoints),
+	})
+}
+
+// Helper methods
+
+func (h *AuditHandler) parseFilter(c *gin.Context) (*model.EventFilter, error) {
+	filter := &model.EventFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		Limit:      defaultPageSize,
+	}
+
+	if value := c.Query("actor_id"); value != "" {
+		actorID, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid actor_id")
+		}
+		id := int32(actorID)
+		filter.ActorID = &id
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		return nil, err
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("invalid limit")
+		}
+		filter.Limit = min(limit, maxPageSize)
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		beforeID, err := decodeCursor(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		filter.BeforeID = beforeID
+	}
+
+	return filter, nil
+}
+
+// parseTimeQuery parses an optional RFC 3339 query parameter
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, expected an RFC 3339 time", name)
+	}
+	return &t, nil
+}
+
+// encodeCursor returns the opaque cursor continuing a listing after the event with the given ID
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(string(decoded), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	return id, nil
+}
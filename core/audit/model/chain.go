@@ -0,0 +1,196 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// CheckpointPurpose is the purpose claim of checkpoint signatures
+const CheckpointPurpose = "audit_checkpoint"
+
+// chainedContent is what an event's hash covers, in a fixed field order
+type chainedContent struct {
+	PrevHash      string `json:"prev_hash"`
+	ActorID       *int32 `json:"actor_id"`
+	ActorAPIKeyID *int32 `json:"actor_api_key_id"`
+	Action        string `json:"action"`
+	TargetType    string `json:"target_type"`
+	TargetID      string `json:"target_id"`
+	RequestID     string `json:"request_id"`
+	IPAddress     string `json:"ip_address"`
+	UserAgent     string `json:"user_agent"`
+	Changes       any    `json:"changes"`
+	CreatedAt     int64  `json:"created_at"`
+}
+
+// ChainHash returns the hex SHA-256 hash of e chained to prevHash, the hash of the event before it or ""
+// for the first one. Changes are hashed decoded and re-encoded, since the database does not keep the
+// JSON text it was given.
+func ChainHash(prevHash string, e *Event) (string, error) {
+	var changes any
+	if err := json.Unmarshal(e.Changes, &changes); err != nil {
+		return "", err
+	}
+
+	content, err := json.Marshal(chainedContent{
+		PrevHash:      prevHash,
+		ActorID:       e.ActorID,
+		ActorAPIKeyID: e.ActorAPIKeyID,
+		Action:        e.Action,
+		TargetType:    e.TargetType,
+		TargetID:      e.TargetID,
+		RequestID:     e.RequestID,
+		IPAddress:     e.IPAddress,
+		UserAgent:     e.UserAgent,
+		Changes:       changes,
+		CreatedAt:     e.CreatedAt.UnixMilli(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Checkpoint vouches for the hash of the chain up to an event. Signature is a compact EdDSA JWS of
+// CheckpointClaims made with the audit checkpoint key, whose header names the key by its JWK thumbprint.
+type Checkpoint struct {
+	ID        int64     `json:"id" db:"id"`
+	EventID   int64     `json:"event_id" db:"event_id"`
+	EventHash string    `json:"event_hash" db:"event_hash"`
+	Signature string    `json:"signature" db:"signature"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// CheckpointClaims is the payload of a checkpoint signature
+type CheckpointClaims struct {
+	Issuer    string `json:"iss"`
+	IssuedAt  int64  `json:"iat"`
+	Purpose   string `json:"purpose"`
+	EventID   int64  `json:"event_id"`
+	EventHash string `json:"event_hash"`
+}
+
+// Verification is the outcome of walking the chain
+type Verification struct {
+	Valid bool `json:"valid"`
+	// EventsChecked counts the chained events checked; UnchainedEvents those recorded before chaining
+	EventsChecked      int64 `json:"events_checked"`
+	UnchainedEvents    int64 `json:"unchained_events"`
+	CheckpointsChecked int   `json:"checkpoints_checked"`
+	// LastEventID is the last event found intact
+	LastEventID int64       `json:"last_event_id"`
+	Break       *ChainBreak `json:"break,omitempty"`
+}
+
+// ChainBreak locates the first place the chain does not hold
+type ChainBreak struct {
+	EventID      int64  `json:"event_id"`
+	CheckpointID int64  `json:"checkpoint_id,omitempty"`
+	Reason       string `json:"reason"`
+}
+
+// CheckpointVerifier checks that the signature of a checkpoint was made with a trusted key over its event ID and hash
+type CheckpointVerifier interface {
+	VerifyCheckpoint(checkpoint *Checkpoint) error
+}
+
+// ChainVerifier walks the events of the chain oldest first, checking that each one's hash covers its
+// content and the hash of the event before it, and that each checkpoint is validly signed and matches the
+// event it names. It stops at the first break. Deleting the newest events leaves the chain intact; only
+// a later checkpoint detects it.
+type ChainVerifier struct {
+	checkpoints []Checkpoint
+	pending     map[int64][]Checkpoint
+	verifier    CheckpointVerifier
+	prevHash    string
+	result      Verification
+}
+
+// NewChainVerifier verifies the chain against checkpoints, listed oldest first, checking their signatures with verifier
+func NewChainVerifier(checkpoints []Checkpoint, verifier CheckpointVerifier) *ChainVerifier {
+	pending := make(map[int64][]Checkpoint)
+	for _, checkpoint := range checkpoints {
+		pending[checkpoint.EventID] = append(pending[checkpoint.EventID], checkpoint)
+	}
+	return &ChainVerifier{
+		checkpoints: checkpoints,
+		pending:     pending,
+		verifier:    verifier,
+	}
+}
+
+// Add checks the next event of the chain. It reports false once the chain is broken.
+func (v *ChainVerifier) Add(event *Event) (bool, error) {
+	if v.result.Break != nil {
+		return false, nil
+	}
+
+	if event.Hash == "" {
+		// Events recorded before chaining may only precede the chain
+		if v.result.EventsChecked > 0 {
+			return v.fail(event.ID, 0, "event has no hash")
+		}
+		v.result.UnchainedEvents++
+		v.result.LastEventID = event.ID
+		return true, nil
+	}
+
+	if event.PrevHash != v.prevHash {
+		return v.fail(event.ID, 0, "previous hash does not match the event before it")
+	}
+	hash, err := ChainHash(event.PrevHash, event)
+	if err != nil {
+		return false, err
+	}
+	if hash != event.Hash {
+		return v.fail(event.ID, 0, "event content does not match its hash")
+	}
+
+	for _, checkpoint := range v.pending[event.ID] {
+		if err := v.verifier.VerifyCheckpoint(&checkpoint); err != nil {
+			return v.fail(event.ID, checkpoint.ID, "checkpoint signature is not valid")
+		}
+		if checkpoint.EventHash != event.Hash {
+			return v.fail(event.ID, checkpoint.ID, "checkpoint does not match the event hash")
+		}
+		v.result.CheckpointsChecked++
+	}
+	delete(v.pending, event.ID)
+
+	v.prevHash = event.Hash
+	v.result.EventsChecked++
+	v.result.LastEventID = event.ID
+	return true, nil
+}
+
+// Result ends the walk, checking that every checkpointed event was seen
+func (v *ChainVerifier) Result() *Verification {
+	if v.result.Break != nil {
+		return &v.result
+	}
+
+	// Checkpoints are listed oldest first, so the first one left names the earliest missing event
+	for _, checkpoint := range v.checkpoints {
+		if _, missing := v.pending[checkpoint.EventID]; !missing {
+			continue
+		}
+		if err := v.verifier.VerifyCheckpoint(&checkpoint); err != nil {
+			v.fail(checkpoint.EventID, checkpoint.ID, "checkpoint signature is not valid")
+		} else {
+			v.fail(checkpoint.EventID, checkpoint.ID, "checkpointed event is missing")
+		}
+		return &v.result
+	}
+
+	v.result.Valid = true
+	return &v.result
+}
+
+func (v *ChainVerifier) fail(eventID, checkpointID int64, reason string) (bool, error) {
+	v.result.Break = &ChainBreak{EventID: eventID, CheckpointID: checkpointID, Reason: reason}
+	return false, nil
+}
@@ -0,0 +1,204 @@
+package model_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go-backend-valos-id/core/audit/model"
+)
+
+// trustAll accepts every checkpoint signature, so that these tests exercise the chain alone
+type trustAll struct{}
+
+func (trustAll) VerifyCheckpoint(*model.Checkpoint) error { return nil }
+
+// trustNone rejects every checkpoint signature
+type trustNone struct{}
+
+func (trustNone) VerifyCheckpoint(*model.Checkpoint) error { return errors.New("untrusted") }
+
+// newChain returns n chained events with IDs 1 to n
+func newChain(t *testing.T, n int) []*model.Event {
+	t.Helper()
+	events := make([]*model.Event, n)
+	prevHash := ""
+	for i := range events {
+		actorID := int32(7)
+		event := &model.Event{
+			ID:         int64(i + 1),
+			ActorID:    &actorID,
+			Action:     model.ActionUserUpdated,
+			TargetType: model.TargetUser,
+			TargetID:   "42",
+			RequestID:  "req",
+			IPAddress:  "203.0.113.1",
+			Changes:    json.RawMessage(`{"before":{"status":"active"},"after":{"status":"suspended"}}`),
+			CreatedAt:  time.UnixMilli(int64(1_700_000_000_000 + i)),
+			PrevHash:   prevHash,
+		}
+		hash, err := model.ChainHash(prevHash, event)
+		if err != nil {
+			t.Fatalf("ChainHash: %v", err)
+		}
+		event.Hash = hash
+		prevHash = hash
+		events[i] = event
+	}
+	return events
+}
+
+// checkpointAt returns a checkpoint of the event with ID eventID
+func checkpointAt(events []*model.Event, eventID int64) model.Checkpoint {
+	return model.Checkpoint{ID: 1, EventID: eventID, EventHash: events[eventID-1].Hash}
+}
+
+func verify(t *testing.T, events []*model.Event, checkpoints []model.Checkpoint, verifier model.CheckpointVerifier) *model.Verification {
+	t.Helper()
+	chain := model.NewChainVerifier(checkpoints, verifier)
+	for _, event := range events {
+		more, err := chain.Add(event)
+		if err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		if !more {
+			break
+		}
+	}
+	return chain.Result()
+}
+
+func TestChainVerifier(t *testing.T) {
+	tests := []struct {
+		name string
+		// tamper changes the intact chain of five events, checkpointed at its last one
+		tamper   func(events []*model.Event) []*model.Event
+		verifier model.CheckpointVerifier
+		// wantBreak is nil for a valid chain
+		wantBreak *model.ChainBreak
+	}{
+		{
+			name:   "intact",
+			tamper: func(events []*model.Event) []*model.Event { return events },
+		},
+		{
+			name: "changes re-encoded",
+			tamper: func(events []*model.Event) []*model.Event {
+				// The database returns JSONB with its own spacing and key order
+				events[2].Changes = json.RawMessage(`{ "after": {"status": "suspended"}, "before": {"status": "active"} }`)
+				return events
+			},
+		},
+		{
+			name: "edited event",
+			tamper: func(events []*model.Event) []*model.Event {
+				events[2].TargetID = "43"
+				return events
+			},
+			wantBreak: &model.ChainBreak{EventID: 3, Reason: "event content does not match its hash"},
+		},
+		{
+			name: "edited changes",
+			tamper: func(events []*model.Event) []*model.Event {
+				events[1].Changes = json.RawMessage(`{"before":{"status":"active"},"after":{"status":"active"}}`)
+				return events
+			},
+			wantBreak: &model.ChainBreak{EventID: 2, Reason: "event content does not match its hash"},
+		},
+		{
+			name: "deleted event",
+			tamper: func(events []*model.Event) []*model.Event {
+				return append(events[:2:2], events[3:]...)
+			},
+			wantBreak: &model.ChainBreak{EventID: 4, Reason: "previous hash does not match the event before it"},
+		},
+		{
+			name: "reordered events",
+			tamper: func(events []*model.Event) []*model.Event {
+				events[1], events[2] = events[2], events[1]
+				return events
+			},
+			wantBreak: &model.ChainBreak{EventID: 3, Reason: "previous hash does not match the event before it"},
+		},
+		{
+			name: "deleted newest event",
+			tamper: func(events []*model.Event) []*model.Event {
+				return events[:4]
+			},
+			wantBreak: &model.ChainBreak{EventID: 5, CheckpointID: 1, Reason: "checkpointed event is missing"},
+		},
+		{
+			name:      "untrusted checkpoint",
+			tamper:    func(events []*model.Event) []*model.Event { return events },
+			verifier:  trustNone{},
+			wantBreak: &model.ChainBreak{EventID: 5, CheckpointID: 1, Reason: "checkpoint signature is not valid"},
+		},
+		{
+			name: "rehashed chain",
+			tamper: func(events []*model.Event) []*model.Event {
+				// Rewriting an event and every hash after it keeps the chain consistent, but not the checkpoint
+				events[1].TargetID = "43"
+				prevHash := events[0].Hash
+				for _, event := range events[1:] {
+					event.PrevHash = prevHash
+					event.Hash, _ = model.ChainHash(prevHash, event)
+					prevHash = event.Hash
+				}
+				return events
+			},
+			wantBreak: &model.ChainBreak{EventID: 5, CheckpointID: 1, Reason: "checkpoint does not match the event hash"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := newChain(t, 5)
+			checkpoints := []model.Checkpoint{checkpointAt(events, 5)}
+			verifier := tt.verifier
+			if verifier == nil {
+				verifier = trustAll{}
+			}
+
+			result := verify(t, tt.tamper(events), checkpoints, verifier)
+
+			if tt.wantBreak == nil {
+				if !result.Valid || result.Break != nil {
+					t.Fatalf("chain broken at %+v, want valid", result.Break)
+				}
+				if result.EventsChecked != 5 || result.CheckpointsChecked != 1 {
+					t.Fatalf("checked %d events and %d checkpoints, want 5 and 1", result.EventsChecked, result.CheckpointsChecked)
+				}
+				return
+			}
+			if result.Valid || result.Break == nil {
+				t.Fatalf("chain valid, want break %+v", tt.wantBreak)
+			}
+			if *result.Break != *tt.wantBreak {
+				t.Fatalf("break = %+v, want %+v", result.Break, tt.wantBreak)
+			}
+		})
+	}
+}
+
+func TestChainVerifierUnchainedEvents(t *testing.T) {
+	chained := newChain(t, 2)
+	legacy := &model.Event{ID: 1, Action: model.ActionUserCreated, Changes: json.RawMessage(`{}`)}
+
+	// Events recorded before chaining may precede the chain
+	for _, event := range chained {
+		event.ID++
+	}
+	result := verify(t, append([]*model.Event{legacy}, chained...), nil, trustAll{})
+	if !result.Valid || result.UnchainedEvents != 1 || result.EventsChecked != 2 {
+		t.Fatalf("result = %+v, want valid with 1 unchained and 2 chained events", result)
+	}
+
+	// but not follow it
+	legacy.ID = 4
+	result = verify(t, append(chained, legacy), nil, trustAll{})
+	want := model.ChainBreak{EventID: 4, Reason: "event has no hash"}
+	if result.Break == nil || *result.Break != want {
+		t.Fatalf("break = %+v, want %+v", result.Break, want)
+	}
+}
@@ -0,0 +1,158 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// Audit actions, named <target type>.<what happened>
+const (
+	ActionUserCreated         = "user.created"
+	ActionUserUpdated         = "user.updated"
+	ActionUserDeleted         = "user.deleted"
+	ActionUserRoleAssigned    = "user.role_assigned"
+	ActionUserRoleRemoved     = "user.role_removed"
+	ActionUserRestored        = "user.restored"
+	ActionUserPurged          = "user.purged"
+	ActionUserStatusChanged   = "user.status_changed"
+	ActionUserPasswordChanged = "user.password_changed"
+	ActionUserPasswordReset   = "user.password_reset"
+
+	ActionRoleCreated = "role.created"
+	ActionRoleUpdated = "role.updated"
+	ActionRoleDeleted = "role.deleted"
+
+	ActionOrganizationCreated = "organization.created"
+
+	ActionOrganizationMemberAdded       = "organization_member.added"
+	ActionOrganizationMemberRoleChanged = "organization_member.role_changed"
+	ActionOrganizationMemberRemoved     = "organization_member.removed"
+
+	ActionInvitationCreated  = "organization_invitation.created"
+	ActionInvitationRenewed  = "organization_invitation.renewed"
+	ActionInvitationRevoked  = "organization_invitation.revoked"
+	ActionInvitationAccepted = "organization_invitation.accepted"
+	ActionInvitationDeclined = "organization_invitation.declined"
+
+	ActionAPIKeyCreated = "api_key.created"
+	ActionAPIKeyRevoked = "api_key.revoked"
+
+	ActionOAuthClientCreated = "oauth_client.created"
+	ActionOAuthClientDeleted = "oauth_client.deleted"
+
+	ActionLockoutLifted = "lockout.lifted"
+
+	ActionSigningKeyRotated = "signing_key.rotated"
+)
+
+// Target types
+const (
+	TargetUser         = "user"
+	TargetRole         = "role"
+	TargetOrganization = "organization"
+	// TargetOrganizationMember is identified by "<organization ID>:<user ID>"
+	TargetOrganizationMember = "organization_member"
+	TargetInvitation         = "organization_invitation"
+	TargetAPIKey             = "api_key"
+	TargetOAuthClient        = "oauth_client"
+	// TargetLockout is a brute-force protection subject such as "account:42" or "ip:192.0.2.1"
+	TargetLockout    = "lockout"
+	TargetSigningKey = "signing_key"
+)
+
+// Origin describes who made a change and through which request
+type Origin struct {
+	// ActorID is the authenticated user, or nil for unauthenticated requests such as registration
+	ActorID *int32
+	// ActorAPIKeyID is set when the actor authenticated with an API key
+	ActorAPIKeyID *int32
+	RequestID     string
+	IPAddress     string
+	UserAgent     string
+}
+
+type Event struct {
+	ID            int64           `json:"id" db:"id"`
+	ActorID       *int32          `json:"actor_id" db:"actor_id"`
+	ActorAPIKeyID *int32          `json:"actor_api_key_id,omitempty" db:"actor_api_key_id"`
+	Action        string          `json:"action" db:"action"`
+	TargetType    string          `json:"target_type" db:"target_type"`
+	TargetID      string          `json:"target_id" db:"target_id"`
+	RequestID     string          `json:"request_id,omitempty" db:"request_id"`
+	IPAddress     string          `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent     string          `json:"user_agent,omitempty" db:"user_agent"`
+	Changes       json.RawMessage `json:"changes" db:"changes"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	// PrevHash and Hash chain the event to the one before it; both are empty for events recorded before chaining
+	PrevHash string `json:"prev_hash,omitempty" db:"prev_hash"`
+	Hash     string `json:"hash,omitempty" db:"hash"`
+}
+
+// Changes holds the fields a change affected with their values before and after it.
+// Before is empty for creations and After for deletions.
+type Changes struct {
+	Before map[string]any `json:"before,omitempty"`
+	After  map[string]any `json:"after,omitempty"`
+}
+
+// Diff compares the JSON encodings of before and after, either of which may be nil, and keeps the fields that differ
+func Diff(before, after any) (*Changes, error) {
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+	if beforeFields == nil || afterFields == nil {
+		return &Changes{Before: beforeFields, After: afterFields}, nil
+	}
+
+	changes := &Changes{Before: map[string]any{}, After: map[string]any{}}
+	for name, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[name]) {
+			changes.Before[name] = value
+			changes.After[name] = afterFields[name]
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes.Before[name] = nil
+			changes.After[name] = value
+		}
+	}
+	return changes, nil
+}
+
+// EventFilter selects audit events; zero fields match every event
+type EventFilter struct {
+	ActorID    *int32
+	Action     string
+	TargetType string
+	TargetID   string
+	// From and To bound the creation time, From inclusive and To exclusive
+	From *time.Time
+	To   *time.Time
+	// BeforeID continues a listing with the events older than this one
+	BeforeID int64
+	Limit    int
+}
+
+// fields returns the JSON object v encodes to, or nil for nil
+func fields(v any) (map[string]any, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
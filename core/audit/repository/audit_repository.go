@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go-backend-valos-id/core/audit/model"
+	"go-backend-valos-id/core/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrCheckpointNotFound is returned when no checkpoint has been made yet
+var ErrCheckpointNotFound = errors.New("audit checkpoint not found")
+
+// walkBatchSize is how many events WalkEvents loads at a time
+const walkBatchSize = 1000
+
+type AuditRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// Record writes an audit event with q, which should be the transaction making the change so that
+// the event is stored if and only if the change is. before and after are the target's state around
+// the change, nil for creations and deletions; only the fields that differ are kept. A nil origin
+// records a change made outside any request, such as from the command line.
+//
+// The event is chained to the newest event by hash. Appends are serialized by a lock held until the
+// transaction ends, so q must not be used outside a transaction.
+func Record(ctx context.Context, q *repository.Queries, origin *model.Origin, action, targetType, targetID string, before, after any) error {
+	changes, err := model.Diff(before, after)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	event := &model.Event{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Changes:    encoded,
+		CreatedAt:  time.Now(),
+	}
+	if origin != nil {
+		event.ActorID = origin.ActorID
+		event.ActorAPIKeyID = origin.ActorAPIKeyID
+		event.RequestID = origin.RequestID
+		event.IPAddress = origin.IPAddress
+		event.UserAgent = origin.UserAgent
+	}
+
+	if err := q.LockAuditChain(ctx); err != nil {
+		return err
+	}
+	last, err := q.GetLastAuditEvent(ctx)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	event.PrevHash = last.Hash.String
+	if event.Hash, err = model.ChainHash(event.PrevHash, event); err != nil {
+		return err
+	}
+
+	return q.CreateAuditEvent(ctx, repository.CreateAuditEventParams{
+		ActorID:       nullableInt4(event.ActorID),
+		ActorApiKeyID: nullableInt4(event.ActorAPIKeyID),
+		Action:        event.Action,
+		TargetType:    event.TargetType,
+		TargetID:      event.TargetID,
+		RequestID:     nullableText(event.RequestID),
+		IpAddress:     nullableText(event.IPAddress),
+		UserAgent:     nullableText(event.UserAgent),
+		Changes:       event.Changes,
+		CreatedAt:     event.CreatedAt.UnixMilli(),
+		PrevHash:      nullableText(event.PrevHash),
+		Hash:          nullableText(event.Hash),
+	})
+}
+
+// RecordEvent writes an audit event in a transaction of its own, for changes that are not stored in the
+// database and so cannot share a transaction with the event
+func (r *AuditRepository) RecordEvent(origin *model.Origin, action, targetType, targetID string, before, after any) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := Record(ctx, r.queries.WithTx(tx), origin, action, targetType, targetID, before, after); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ListEvents returns the events matching filter, newest first
+func (r *AuditRepository) ListEvents(filter *model.EventFilter) ([]model.Event, error) {
+	ctx := context.Background()
+
+	params := repository.ListAuditEventsParams{
+		ActorID:    nullableInt4(filter.ActorID),
+		Action:     nullableText(filter.Action),
+		TargetType: nullableText(filter.TargetType),
+		TargetID:   nullableText(filter.TargetID),
+		LimitCount: int32(filter.Limit),
+	}
+	if filter.From != nil {
+		params.CreatedFrom = pgtype.Int8{Int64: filter.From.UnixMilli(), Valid: true}
+	}
+	if filter.To != nil {
+		params.CreatedTo = pgtype.Int8{Int64: filter.To.UnixMilli(), Valid: true}
+	}
+	if filter.BeforeID != 0 {
+		params.BeforeID = pgtype.Int8{Int64: filter.BeforeID, Valid: true}
+	}
+
+	results, err := r.queries.ListAuditEvents(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]model.Event, len(results))
+	for i := range results {
+		events[i] = *r.sqlcEventToModel(&results[i])
+	}
+
+	return events, nil
+}
+
+// LastEvent returns the ID and hash of the newest event, with an empty hash if it was recorded
+// before chaining. The ID is 0 if there are no events.
+func (r *AuditRepository) LastEvent() (int64, string, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetLastAuditEvent(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, "", nil
+		}
+		return 0, "", err
+	}
+
+	return result.ID, result.Hash.String, nil
+}
+
+// VerifyChain walks every event from the oldest with a model.ChainVerifier, checking checkpoint
+// signatures with verifier, and reports the first break
+func (r *AuditRepository) VerifyChain(verifier model.CheckpointVerifier) (*model.Verification, error) {
+	checkpoints, err := r.ListCheckpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	chain := model.NewChainVerifier(checkpoints, verifier)
+	err = r.WalkEvents(func(event *model.Event) (bool, error) {
+		return chain.Add(event)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chain.Result(), nil
+}
+
+// WalkEvents calls fn with every event from the oldest, loading them in batches, until fn returns false
+func (r *AuditRepository) WalkEvents(fn func(event *model.Event) (bool, error)) error {
+	ctx := context.Background()
+
+	var afterID int64
+	for {
+		results, err := r.queries.ListAuditEventsAfter(ctx, repository.ListAuditEventsAfterParams{
+			ID:    afterID,
+			Limit: walkBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := range results {
+			event := r.sqlcEventToModel(&results[i])
+			afterID = event.ID
+
+			more, err := fn(event)
+			if err != nil || !more {
+				return err
+			}
+		}
+
+		if len(results) < walkBatchSize {
+			return nil
+		}
+	}
+}
+
+// CreateCheckpoint stores a signed checkpoint
+func (r *AuditRepository) CreateCheckpoint(checkpoint *model.Checkpoint) error {
+	ctx := context.Background()
+
+	result, err := r.queries.CreateAuditCheckpoint(ctx, repository.CreateAuditCheckpointParams{
+		EventID:   checkpoint.EventID,
+		EventHash: checkpoint.EventHash,
+		Signature: checkpoint.Signature,
+		CreatedAt: checkpoint.CreatedAt.UnixMilli(),
+	})
+	if err != nil {
+		return err
+	}
+
+	*checkpoint = *r.sqlcCheckpointToModel(&result)
+	return nil
+}
+
+// GetLatestCheckpoint returns the newest checkpoint
+func (r *AuditRepository) GetLatestCheckpoint() (*model.Checkpoint, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetLatestAuditCheckpoint(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCheckpointNotFound
+		}
+		return nil, err
+	}
+
+	return r.sqlcCheckpointToModel(&result), nil
+}
+
+// ListCheckpoints returns every checkpoint, oldest first
+func (r *AuditRepository) ListCheckpoints() ([]model.Checkpoint, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListAuditCheckpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints := make([]model.Checkpoint, len(results))
+	for i := range results {
+		checkpoints[i] = *r.sqlcCheckpointToModel(&results[i])
+	}
+
+	return checkpoints, nil
+}
+
+// Helper method to convert sqlc AuditEvent to model Event
+func (r *AuditRepository) sqlcEventToModel(sqlcEvent *repository.AuditEvent) *model.Event {
+	event := &model.Event{
+		ID:         sqlcEvent.ID,
+		Action:     sqlcEvent.Action,
+		TargetType: sqlcEvent.TargetType,
+		TargetID:   sqlcEvent.TargetID,
+		RequestID:  sqlcEvent.RequestID.String,
+		IPAddress:  sqlcEvent.IpAddress.String,
+		UserAgent:  sqlcEvent.UserAgent.String,
+		Changes:    sqlcEvent.Changes,
+		CreatedAt:  time.UnixMilli(sqlcEvent.CreatedAt),
+		PrevHash:   sqlcEvent.PrevHash.String,
+		Hash:       sqlcEvent.Hash.String,
+	}
+	if sqlcEvent.ActorID.Valid {
+		event.ActorID = &sqlcEvent.ActorID.Int32
+	}
+	if sqlcEvent.ActorApiKeyID.Valid {
+		event.ActorAPIKeyID = &sqlcEvent.ActorApiKeyID.Int32
+	}
+	return event
+}
+
+// Helper method to convert sqlc AuditCheckpoint to model Checkpoint
+func (r *AuditRepository) sqlcCheckpointToModel(sqlcCheckpoint *repository.AuditCheckpoint) *model.Checkpoint {
+	return &model.Checkpoint{
+		ID:        sqlcCheckpoint.ID,
+		EventID:   sqlcCheckpoint.EventID,
+		EventHash: sqlcCheckpoint.EventHash,
+		Signature: sqlcCheckpoint.Signature,
+		CreatedAt: time.UnixMilli(sqlcCheckpoint.CreatedAt),
+	}
+}
+
+func nullableInt4(value *int32) pgtype.Int4 {
+	if value == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *value, Valid: true}
+}
+
+func nullableText(value string) pgtype.Text {
+	return pgtype.Text{String: value, Valid: value != ""}
+}
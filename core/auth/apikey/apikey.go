@@ -0,0 +1,49 @@
+package apikey
+
+import (
+	"errors"
+
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/middleware"
+	"go-backend-valos-id/core/utils"
+)
+
+// Generate returns a new API key and its visible prefix. Keys look like vk_<8 hex digits>_<secret>;
+// the part up to the second underscore is the prefix.
+func Generate() (key, prefix string, err error) {
+	id, err := utils.RandomHex(4)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := utils.RandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	prefix = middleware.APIKeyPrefix + id
+	return prefix + "_" + secret, prefix, nil
+}
+
+// Hash returns the digest API keys are stored and looked up by
+func Hash(key string) string {
+	return utils.HashToken(key)
+}
+
+// Validator authenticates API keys for middleware.AuthenticateAPIKey
+type Validator struct {
+	apiKeyRepo *repository.APIKeyRepository
+}
+
+func NewValidator(apiKeyRepo *repository.APIKeyRepository) *Validator {
+	return &Validator{apiKeyRepo: apiKeyRepo}
+}
+
+// ValidateAPIKey returns the API key and records its use, or middleware.ErrInvalidAPIKey
+func (v *Validator) ValidateAPIKey(raw, ipAddress string) (*model.APIKey, error) {
+	key, err := v.apiKeyRepo.UseAPIKey(Hash(raw), ipAddress)
+	if errors.Is(err, repository.ErrAPIKeyInvalid) {
+		return nil, middleware.ErrInvalidAPIKey
+	}
+	return key, err
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-backend-valos-id/core/audit"
+	"go-backend-valos-id/core/auth/apikey"
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/middleware"
+	user_handler "go-backend-valos-id/core/user/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler manages the personal API keys of the authenticated user,
+// and for administrators the API keys of service accounts
+type APIKeyHandler struct {
+	apiKeyRepo *repository.APIKeyRepository
+}
+
+func NewAPIKeyHandler(apiKeyRepo *repository.APIKeyRepository) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// CreateAPIKey creates an API key for the authenticated user. The key is only returned in this response.
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+	h.createAPIKey(c, principal.UserID)
+}
+
+// ListAPIKeys lists the authenticated user's API keys that have not been revoked
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+	h.listAPIKeys(c, principal.UserID)
+}
+
+// RevokeAPIKey revokes one of the authenticated user's API keys
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+	h.revokeAPIKey(c, principal.UserID, c.Param("id"))
+}
+
+// CreateServiceAccountAPIKey creates an API key for the service account loaded by
+// user_handler.LoadServiceAccount. The key is only returned in this response.
+func (h *APIKeyHandler) CreateServiceAccountAPIKey(c *gin.Context) {
+	account, _ := user_handler.ServiceAccountFromContext(c)
+	h.createAPIKey(c, account.ID)
+}
+
+// ListServiceAccountAPIKeys lists the API keys of a service account that have not been revoked
+func (h *APIKeyHandler) ListServiceAccountAPIKeys(c *gin.Context) {
+	account, _ := user_handler.ServiceAccountFromContext(c)
+	h.listAPIKeys(c, account.ID)
+}
+
+// RevokeServiceAccountAPIKey revokes one of the API keys of a service account
+func (h *APIKeyHandler) RevokeServiceAccountAPIKey(c *gin.Context) {
+	account, _ := user_handler.ServiceAccountFromContext(c)
+	h.revokeAPIKey(c, account.ID, c.Param("key_id"))
+}
+
+// Helper methods
+
+func (h *APIKeyHandler) createAPIKey(c *gin.Context, userID int32) {
+	var req model.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Expiry must be in the future",
+		})
+		return
+	}
+
+	key, prefix, err := apikey.Generate()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate API key",
+		})
+		return
+	}
+
+	apiKey := &model.APIKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := h.apiKeyRepo.CreateAPIKey(apiKey, apikey.Hash(key), audit.OriginFromContext(c)); err != nil {
+		if errors.Is(err, repository.ErrUnknownScope) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Unknown scope; scopes must be permission names",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create API key",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created successfully; store the key now, it will not be shown again",
+		"data": model.APIKeyCreateResponse{
+			APIKey: *apiKey,
+			Key:    key,
+		},
+	})
+}
+
+func (h *APIKeyHandler) listAPIKeys(c *gin.Context, userID int32) {
+	keys, err := h.apiKeyRepo.ListAPIKeys(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve API keys",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  keys,
+		"count": len(keys),
+	})
+}
+
+func (h *APIKeyHandler) revokeAPIKey(c *gin.Context, userID int32, keyID string) {
+	id, err := strconv.ParseInt(keyID, 10, 32)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid API key ID",
+		})
+		return
+	}
+
+	revoked, err := h.apiKeyRepo.RevokeAPIKey(userID, int32(id), audit.OriginFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke API key",
+		})
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "API key not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked successfully",
+	})
+}
@@ -0,0 +1,541 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-backend-valos-id/core/auth/lockout"
+	"go-backend-valos-id/core/auth/mfa"
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/auth/session"
+	"go-backend-valos-id/core/auth/token"
+	"go-backend-valos-id/core/middleware"
+	user_model "go-backend-valos-id/core/user/model"
+	user_repository "go-backend-valos-id/core/user/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MembershipChecker reports whether a user belongs to an organization
+type MembershipChecker interface {
+	IsMember(organizationID, userID int32) (bool, error)
+}
+
+type AuthHandler struct {
+	userRepo         *user_repository.UserRepository
+	refreshTokenRepo *repository.RefreshTokenRepository
+	sessionRepo      *repository.SessionRepository
+	guard            *session.Guard
+	tokens           *token.Manager
+	mfa              *mfa.Service
+	memberships      MembershipChecker
+	lockouts         *lockout.Tracker
+	refreshTokenTTL  time.Duration
+	// requireVerifiedEmail blocks login and refresh for accounts whose email is not verified
+	requireVerifiedEmail bool
+}
+
+func NewAuthHandler(
+	userRepo *user_repository.UserRepository,
+	refreshTokenRepo *repository.RefreshTokenRepository,
+	sessionRepo *repository.SessionRepository,
+	guard *session.Guard,
+	tokens *token.Manager,
+	mfaService *mfa.Service,
+	memberships MembershipChecker,
+	lockouts *lockout.Tracker,
+	refreshTokenTTL time.Duration,
+	requireVerifiedEmail bool,
+) *AuthHandler {
+	return &AuthHandler{
+		userRepo:             userRepo,
+		refreshTokenRepo:     refreshTokenRepo,
+		sessionRepo:          sessionRepo,
+		guard:                guard,
+		tokens:               tokens,
+		mfa:                  mfaService,
+		memberships:          memberships,
+		lockouts:             lockouts,
+		refreshTokenTTL:      refreshTokenTTL,
+		requireVerifiedEmail: requireVerifiedEmail,
+	}
+}
+
+// Login authenticates a user by email or username and password and issues an access token.
+// Users with MFA enabled get an MFA challenge token instead, to be exchanged at VerifyMFA.
+// Accounts and IP addresses that failed too often are refused with 429 until their lockout ends.
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req model.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.findUser(req.Identifier)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+
+	attempt := lockout.ForIdentifier(req.Identifier, c.ClientIP())
+	if user != nil {
+		attempt = lockout.ForUser(user.ID, c.ClientIP())
+	}
+	if !checkLockout(c, h.lockouts, attempt) {
+		return
+	}
+
+	if user == nil {
+		utils.CheckPasswordHash(req.Password, utils.DummyPasswordHash)
+		recordLoginFailure(h.lockouts, attempt)
+		h.invalidCredentials(c)
+		return
+	}
+
+	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		recordLoginFailure(h.lockouts, attempt)
+		h.invalidCredentials(c)
+		return
+	}
+
+	h.completeLogin(c, user, false)
+}
+
+// VerifyMFA completes a login by exchanging an MFA challenge token and a TOTP or recovery code for tokens
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req model.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	claims, err := h.tokens.ValidateMFAChallenge(req.MFAToken)
+	if err != nil {
+		h.invalidMFAToken(c)
+		return
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 32)
+	if err != nil {
+		h.invalidMFAToken(c)
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(int32(userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.invalidMFAToken(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+
+	// A password change since the challenge was issued invalidates it
+	if user.CredentialVersion != claims.CredentialVersion {
+		h.invalidMFAToken(c)
+		return
+	}
+	if !user.IsActive() {
+		inactiveAccount(c, user)
+		return
+	}
+
+	// Wrong codes count against the account like wrong passwords
+	attempt := lockout.ForUser(user.ID, c.ClientIP())
+	if !checkLockout(c, h.lockouts, attempt) {
+		return
+	}
+
+	if err := h.mfa.Verify(user.ID, req.Code); err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) || errors.Is(err, mfa.ErrTOTPNotEnabled) {
+			recordLoginFailure(h.lockouts, attempt)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid MFA code",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to verify MFA code",
+		})
+		return
+	}
+
+	h.startSession(c, user)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token pair.
+// Each refresh token can be used once; reusing one revokes every token of the same login,
+// whatever the state of the account.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req model.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	current, err := h.refreshTokenRepo.GetRefreshToken(utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid refresh token",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to refresh token",
+		})
+		return
+	}
+
+	// A consumed token goes straight to rotation, which detects the reuse and revokes its family.
+	// An unused one is only consumed once the account may refresh, so a disabled user keeps it
+	// instead of having it rotated into one they cannot use.
+	var user *user_model.User
+	if current.UsedAt == nil {
+		var ok bool
+		if user, ok = h.refreshingUser(c, current.UserID); !ok {
+			return
+		}
+	}
+
+	refreshToken, err := utils.RandomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue refresh token",
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(h.refreshTokenTTL)
+	rotated, err := h.refreshTokenRepo.RotateRefreshToken(utils.HashToken(req.RefreshToken), utils.HashToken(refreshToken), expiresAt)
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenReused) {
+			// The whole family was revoked, drop its session from the guard cache too
+			h.guard.Revoked(current.FamilyID)
+		}
+		if errors.Is(err, repository.ErrRefreshTokenInvalid) || errors.Is(err, repository.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid refresh token",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to refresh token",
+		})
+		return
+	}
+
+	// The refresh token family is the session
+	session, err := h.sessionRepo.TouchSession(rotated.FamilyID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionInactive) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid refresh token",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to refresh token",
+		})
+		return
+	}
+
+	h.respondWithTokens(c, user, session.ID, sessionOrganization(session), refreshToken)
+}
+
+// SwitchOrganization sets the organization the current session acts within and issues an access token
+// carrying it. Sending no organization leaves the current one. The refresh token stays valid and
+// keeps issuing tokens for the session's organization.
+func (h *AuthHandler) SwitchOrganization(c *gin.Context) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok || principal.SessionID == "" {
+		middleware.AbortUnauthorized(c, "Unauthorized")
+		return
+	}
+
+	var req model.OrganizationSwitchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var organizationID int32
+	if req.OrganizationID != nil {
+		organizationID = *req.OrganizationID
+	}
+
+	if organizationID != 0 {
+		member, err := h.memberships.IsMember(organizationID, principal.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check organization membership",
+			})
+			return
+		}
+		if !member {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Not a member of this organization",
+			})
+			return
+		}
+	}
+
+	var sessionOrganizationID *int32
+	if organizationID != 0 {
+		sessionOrganizationID = &organizationID
+	}
+	updated, err := h.sessionRepo.SetSessionOrganization(principal.SessionID, principal.UserID, sessionOrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to switch organization",
+		})
+		return
+	}
+	if !updated {
+		middleware.AbortUnauthorized(c, "Session is no longer active")
+		return
+	}
+
+	accessToken, _, err := h.tokens.IssueAccessToken(principal.UserID, principal.SessionID, organizationID, principal.Claims.CredentialVersion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue access token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(h.tokens.AccessTokenTTL().Seconds()),
+	})
+}
+
+// Helper methods
+
+// findUser looks up the user logging in. Service accounts cannot log in, so they are not found.
+func (h *AuthHandler) findUser(identifier string) (*user_model.User, error) {
+	user, err := h.userRepo.GetUserByIdentifier(identifier)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsServiceAccount() {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+// refreshingUser returns the user a refresh token was issued to if they may still refresh it: they must exist,
+// be active and, when required, have verified their email. It writes the error response otherwise.
+func (h *AuthHandler) refreshingUser(c *gin.Context, userID int32) (*user_model.User, bool) {
+	user, err := h.userRepo.GetUserByID(userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid refresh token",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return nil, false
+	}
+	if h.requireVerifiedEmail && !user.EmailVerified() {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Email address is not verified",
+		})
+		return nil, false
+	}
+	if !user.IsActive() {
+		inactiveAccount(c, user)
+		return nil, false
+	}
+	return user, true
+}
+
+// completeLogin finishes a login once the user has proven one factor. Users that are not active are refused.
+// multiFactor is true when that proof already counts as multi-factor, like a user-verified passkey;
+// otherwise users with MFA enabled get an MFA challenge instead of tokens.
+func (h *AuthHandler) completeLogin(c *gin.Context, user *user_model.User, multiFactor bool) {
+	if h.requireVerifiedEmail && !user.EmailVerified() {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Email address is not verified",
+		})
+		return
+	}
+	if !user.IsActive() {
+		inactiveAccount(c, user)
+		return
+	}
+
+	if !multiFactor {
+		mfaEnabled, err := h.mfa.Enabled(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check MFA status",
+			})
+			return
+		}
+		if mfaEnabled {
+			h.respondWithMFAChallenge(c, user)
+			return
+		}
+	}
+
+	h.startSession(c, user)
+}
+
+// startSession records a new session for the user and responds with its first token pair.
+// The login succeeded, so the failures counted against the account are forgotten.
+func (h *AuthHandler) startSession(c *gin.Context, user *user_model.User) {
+	if err := h.lockouts.Succeeded(lockout.ForUser(user.ID, c.ClientIP())); err != nil {
+		log.Printf("Failed to reset failed login attempts: %v", err)
+	}
+
+	sessionID, err := utils.RandomHex(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create session",
+		})
+		return
+	}
+
+	session := &model.Session{
+		ID:        sessionID,
+		UserID:    user.ID,
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+	if err := h.sessionRepo.CreateSession(session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create session",
+		})
+		return
+	}
+
+	refreshToken, err := utils.RandomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue refresh token",
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(h.refreshTokenTTL)
+	if _, err := h.refreshTokenRepo.CreateRefreshToken(user.ID, utils.HashToken(refreshToken), session.ID, expiresAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue refresh token",
+		})
+		return
+	}
+
+	h.respondWithTokens(c, user, session.ID, 0, refreshToken)
+}
+
+func (h *AuthHandler) respondWithTokens(c *gin.Context, user *user_model.User, sessionID string, organizationID int32, refreshToken string) {
+	accessToken, _, err := h.tokens.IssueAccessToken(user.ID, sessionID, organizationID, user.CredentialVersion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue access token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(h.tokens.AccessTokenTTL().Seconds()),
+		RefreshToken: refreshToken,
+	})
+}
+
+func (h *AuthHandler) respondWithMFAChallenge(c *gin.Context, user *user_model.User) {
+	mfaToken, err := h.tokens.IssueMFAChallenge(user.ID, user.CredentialVersion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue MFA challenge",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.MFAChallengeResponse{
+		MFARequired: true,
+		MFAToken:    mfaToken,
+		ExpiresIn:   int64(h.tokens.MFAChallengeTTL().Seconds()),
+	})
+}
+
+func (h *AuthHandler) invalidMFAToken(c *gin.Context) {
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error": "Invalid or expired MFA token",
+	})
+}
+
+func (h *AuthHandler) invalidCredentials(c *gin.Context) {
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error": "Invalid credentials",
+	})
+}
+
+// inactiveAccount answers 403 for a user whose status does not allow them to authenticate
+func inactiveAccount(c *gin.Context, user *user_model.User) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":  "Account is not active",
+		"status": user.Status,
+	})
+}
+
+// checkLockout answers 429 if the attempt's account or IP address is locked out, returning false
+func checkLockout(c *gin.Context, lockouts *lockout.Tracker, attempt lockout.Attempt) bool {
+	retryAfter, err := lockouts.Check(attempt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check login attempts",
+		})
+		return false
+	}
+	if retryAfter > 0 {
+		middleware.AbortTooManyRequests(c, retryAfter, "Too many failed attempts, try again later")
+		return false
+	}
+	return true
+}
+
+// recordLoginFailure counts a failed attempt. The caller still refuses the attempt if counting fails.
+func recordLoginFailure(lockouts *lockout.Tracker, attempt lockout.Attempt) {
+	if err := lockouts.Failed(attempt); err != nil {
+		log.Printf("Failed to record failed login attempt: %v", err)
+	}
+}
+
+// sessionOrganization returns the organization a session acts within, or 0 for none
+func sessionOrganization(session *model.Session) int32 {
+	if session.OrganizationID == nil {
+		return 0
+	}
+	return *session.OrganizationID
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"go-backend-valos-id/core/audit"
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/auth/verification"
+	user_model "go-backend-valos-id/core/user/model"
+	user_repository "go-backend-valos-id/core/user/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailVerificationHandler struct {
+	userRepo         *user_repository.UserRepository
+	verificationRepo *repository.EmailVerificationRepository
+	verifier         *verification.EmailVerifier
+}
+
+func NewEmailVerificationHandler(userRepo *user_repository.UserRepository, verificationRepo *repository.EmailVerificationRepository, verifier *verification.EmailVerifier) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		verifier:         verifier,
+	}
+}
+
+// VerifyEmail marks the user's email as verified using the token from the verification link,
+// activating the user if they were pending until their address was verified
+func (h *EmailVerificationHandler) VerifyEmail(c *gin.Context) {
+	var req model.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	verified, err := h.verificationRepo.VerifyEmail(utils.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, repository.ErrEmailVerificationTokenInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid or expired verification token",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to verify email",
+		})
+		return
+	}
+
+	if err := h.activatePending(c, verified.UserID); err != nil {
+		log.Printf("Failed to activate user %d after email verification: %v", verified.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to activate account",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+	})
+}
+
+// ResendVerification sends a new verification link if the address belongs to an unverified account.
+// The response is the same whether or not the email is registered.
+func (h *EmailVerificationHandler) ResendVerification(c *gin.Context) {
+	var req model.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	go h.resend(req.Email)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an unverified account with that email exists, a verification link has been sent",
+	})
+}
+
+// Helper methods
+
+// activatePending activates a user that is pending, leaving users with any other status as they are
+func (h *EmailVerificationHandler) activatePending(c *gin.Context, userID int32) error {
+	user, err := h.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Status != user_model.StatusPending {
+		return nil
+	}
+
+	_, err = h.userRepo.ChangeStatus(userID, user_model.StatusActive, "email address verified", audit.OriginFromContext(c))
+	if errors.Is(err, user_repository.ErrInvalidStatusTransition) {
+		return nil
+	}
+	return err
+}
+
+func (h *EmailVerificationHandler) resend(email string) {
+	user, err := h.userRepo.GetUserByEmail(email)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Failed to look up user for email verification: %v", err)
+		}
+		return
+	}
+	if user.EmailVerified() {
+		return
+	}
+
+	if err := h.verifier.SendVerification(user); err != nil {
+		log.Printf("Failed to send verification email: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"go-backend-valos-id/core/audit"
+	"go-backend-valos-id/core/auth/lockout"
+	user_model "go-backend-valos-id/core/user/model"
+	user_repository "go-backend-valos-id/core/user/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LockoutHandler lets administrators inspect and lift lockouts caused by failed logins
+type LockoutHandler struct {
+	userRepo *user_repository.UserRepository
+	lockouts *lockout.Tracker
+}
+
+func NewLockoutHandler(userRepo *user_repository.UserRepository, lockouts *lockout.Tracker) *LockoutHandler {
+	return &LockoutHandler{
+		userRepo: userRepo,
+		lockouts: lockouts,
+	}
+}
+
+// GetUserLockout returns the failed logins counted against a user and when a lockout ends
+func (h *LockoutHandler) GetUserLockout(c *gin.Context) {
+	user, ok := h.userParam(c)
+	if !ok {
+		return
+	}
+
+	attempt, err := h.lockouts.AccountStatus(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve login attempts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": attempt,
+	})
+}
+
+// UnlockUser lifts a lockout of a user's account and forgets its failed logins.
+// A user whose status was set to locked after failing too often is reactivated.
+func (h *LockoutHandler) UnlockUser(c *gin.Context) {
+	user, ok := h.userParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.lockouts.UnlockAccount(user.ID, audit.OriginFromContext(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to unlock user",
+		})
+		return
+	}
+
+	if user.Status == user_model.StatusLocked {
+		_, err := h.userRepo.ChangeStatus(user.ID, user_model.StatusActive, "unlocked", audit.OriginFromContext(c))
+		if err != nil && !errors.Is(err, user_repository.ErrInvalidStatusTransition) {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to unlock user",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unlocked successfully",
+	})
+}
+
+// UnlockIP lifts a block of an IP address and forgets its failed logins
+func (h *LockoutHandler) UnlockIP(c *gin.Context) {
+	ip := net.ParseIP(c.Param("ip"))
+	if ip == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid IP address",
+		})
+		return
+	}
+
+	if err := h.lockouts.UnlockIP(ip.String(), audit.OriginFromContext(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to unlock IP address",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "IP address unlocked successfully",
+	})
+}
+
+// Helper methods
+
+// userParam loads the user named by the :id parameter
+func (h *LockoutHandler) userParam(c *gin.Context) (*user_model.User, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetUserByID(int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"go-backend-valos-id/core/auth/lockout"
+	"go-backend-valos-id/core/auth/mfa"
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/middleware"
+	user_model "go-backend-valos-id/core/user/model"
+	user_repository "go-backend-valos-id/core/user/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAHandler struct {
+	userRepo *user_repository.UserRepository
+	mfa      *mfa.Service
+	lockouts *lockout.Tracker
+}
+
+func NewMFAHandler(userRepo *user_repository.UserRepository, mfaService *mfa.Service, lockouts *lockout.Tracker) *MFAHandler {
+	return &MFAHandler{
+		userRepo: userRepo,
+		mfa:      mfaService,
+		lockouts: lockouts,
+	}
+}
+
+// GetStatus reports whether the authenticated user has TOTP enabled
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	totp, err := h.mfa.ConfirmedTOTP(principal.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve MFA status",
+		})
+		return
+	}
+
+	response := model.MFAStatusResponse{}
+	if totp != nil {
+		remaining, err := h.mfa.RecoveryCodesRemaining(principal.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve MFA status",
+			})
+			return
+		}
+		response.TOTPEnabled = true
+		response.TOTPConfirmedAt = totp.ConfirmedAt
+		response.RecoveryCodesRemaining = remaining
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": response,
+	})
+}
+
+// EnrollTOTP starts TOTP enrollment and returns the secret to add to an authenticator app.
+// TOTP is only enabled once ConfirmTOTP receives a valid code.
+func (h *MFAHandler) EnrollTOTP(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	secret, uri, err := h.mfa.BeginEnrollment(user)
+	if err != nil {
+		if errors.Is(err, mfa.ErrTOTPAlreadyEnabled) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "TOTP is already enabled",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to start TOTP enrollment",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": model.TOTPEnrollmentResponse{
+			Secret:     secret,
+			OTPAuthURI: uri,
+		},
+	})
+}
+
+// ConfirmTOTP enables TOTP with the first code from the authenticator app.
+// The recovery codes in the response are shown once and cannot be retrieved later.
+func (h *MFAHandler) ConfirmTOTP(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	var req model.ConfirmTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	recoveryCodes, err := h.mfa.ConfirmEnrollment(principal.UserID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, mfa.ErrInvalidCode):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid MFA code",
+			})
+		case errors.Is(err, mfa.ErrTOTPNotEnabled):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "No pending TOTP enrollment",
+			})
+		case errors.Is(err, mfa.ErrTOTPAlreadyEnabled):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "TOTP is already enabled",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to confirm TOTP enrollment",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "TOTP enabled successfully",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// DisableTOTP turns TOTP off after checking the password and a current TOTP or recovery code
+func (h *MFAHandler) DisableTOTP(c *gin.Context) {
+	var req model.DisableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	attempt := lockout.ForUser(user.ID, c.ClientIP())
+	if !checkLockout(c, h.lockouts, attempt) {
+		return
+	}
+
+	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		recordLoginFailure(h.lockouts, attempt)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Password is incorrect",
+		})
+		return
+	}
+
+	if err := h.mfa.Verify(user.ID, req.Code); err != nil {
+		switch {
+		case errors.Is(err, mfa.ErrInvalidCode):
+			recordLoginFailure(h.lockouts, attempt)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid MFA code",
+			})
+		case errors.Is(err, mfa.ErrTOTPNotEnabled):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "TOTP is not enabled",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to verify MFA code",
+			})
+		}
+		return
+	}
+
+	if err := h.mfa.Disable(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to disable TOTP",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "TOTP disabled successfully",
+	})
+}
+
+// Helper methods
+
+func (h *MFAHandler) currentUser(c *gin.Context) (*user_model.User, bool) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	user, err := h.userRepo.GetUserByID(principal.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return nil, false
+	}
+	return user, true
+}
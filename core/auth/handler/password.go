@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go-backend-valos-id/core/audit"
+	"go-backend-valos-id/core/auth/lockout"
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/auth/session"
+	"go-backend-valos-id/core/auth/token"
+	"go-backend-valos-id/core/mail"
+	"go-backend-valos-id/core/middleware"
+	user_model "go-backend-valos-id/core/user/model"
+	user_repository "go-backend-valos-id/core/user/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordHandler struct {
+	userRepo          *user_repository.UserRepository
+	sessionRepo       *repository.SessionRepository
+	passwordResetRepo *repository.PasswordResetRepository
+	guard             *session.Guard
+	tokens            *token.Manager
+	lockouts          *lockout.Tracker
+	mailer            mail.Sender
+	appBaseURL        string
+	passwordResetTTL  time.Duration
+}
+
+func NewPasswordHandler(
+	userRepo *user_repository.UserRepository,
+	sessionRepo *repository.SessionRepository,
+	passwordResetRepo *repository.PasswordResetRepository,
+	guard *session.Guard,
+	tokens *token.Manager,
+	lockouts *lockout.Tracker,
+	mailer mail.Sender,
+	appBaseURL string,
+	passwordResetTTL time.Duration,
+) *PasswordHandler {
+	return &PasswordHandler{
+		userRepo:          userRepo,
+		sessionRepo:       sessionRepo,
+		passwordResetRepo: passwordResetRepo,
+		guard:             guard,
+		tokens:            tokens,
+		lockouts:          lockouts,
+		mailer:            mailer,
+		appBaseURL:        appBaseURL,
+		passwordResetTTL:  passwordResetTTL,
+	}
+}
+
+// ChangePassword changes the authenticated user's password.
+// Every other session is revoked and tokens issued before the change stop working,
+// so the response carries a fresh access token for the current session.
+func (h *PasswordHandler) ChangePassword(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	var req user_model.PasswordChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(principal.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+
+	// Wrong current passwords count as failed logins, so a stolen session cannot be used to guess the password
+	attempt := lockout.ForUser(user.ID, c.ClientIP())
+	if !checkLockout(c, h.lockouts, attempt) {
+		return
+	}
+
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		recordLoginFailure(h.lockouts, attempt)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Current password is incorrect",
+		})
+		return
+	}
+
+	if err := utils.ValidatePasswordStrength(req.NewPassword, user.Username, user.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Password is too weak",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "New password must be different from the current password",
+		})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to hash password",
+		})
+		return
+	}
+
+	credentialVersion, err := h.userRepo.UpdatePassword(user.ID, hashedPassword, audit.OriginFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update password",
+		})
+		return
+	}
+	h.guard.CredentialsChanged(user.ID, credentialVersion)
+
+	revoked, err := h.sessionRepo.RevokeOtherSessions(user.ID, principal.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke other sessions",
+		})
+		return
+	}
+	h.guard.Revoked(revoked...)
+
+	accessToken, _, err := h.tokens.IssueAccessToken(user.ID, principal.SessionID, principal.OrganizationID, credentialVersion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue access token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Password changed successfully",
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int64(h.tokens.AccessTokenTTL().Seconds()),
+	})
+}
+
+// ForgotPassword emails a single-use password reset link.
+// The response is the same whether or not the email is registered.
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	var req model.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Issue the token in the background so response time does not reveal whether the account exists
+	go h.sendPasswordReset(req.Email)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an account with that email exists, a password reset link has been sent",
+	})
+}
+
+// ResetPassword sets a new password using a reset token and revokes every session of the user.
+// Proving access to the email address also lifts a lockout of the account.
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	var req model.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	reset, err := h.passwordResetRepo.GetPasswordResetToken(utils.HashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, repository.ErrPasswordResetTokenInvalid) {
+			h.invalidResetToken(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to reset password",
+		})
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(reset.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.invalidResetToken(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+
+	if err := utils.ValidatePasswordStrength(req.NewPassword, user.Username, user.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Password is too weak",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to hash password",
+		})
+		return
+	}
+
+	credentialVersion, revoked, err := h.passwordResetRepo.ResetPassword(reset, hashedPassword, audit.OriginFromContext(c))
+	if err != nil {
+		if errors.Is(err, repository.ErrPasswordResetTokenInvalid) {
+			h.invalidResetToken(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to reset password",
+		})
+		return
+	}
+	h.guard.CredentialsChanged(user.ID, credentialVersion)
+	h.guard.Revoked(revoked...)
+	if err := h.lockouts.UnlockAccount(user.ID, audit.OriginFromContext(c)); err != nil {
+		log.Printf("Failed to unlock account after password reset: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password reset successfully",
+	})
+}
+
+// Helper methods
+
+func (h *PasswordHandler) sendPasswordReset(email string) {
+	user, err := h.userRepo.GetUserByEmail(email)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Failed to look up user for password reset: %v", err)
+		}
+		return
+	}
+
+	resetToken, err := utils.RandomToken(32)
+	if err != nil {
+		log.Printf("Failed to generate password reset token: %v", err)
+		return
+	}
+
+	expiresAt := time.Now().Add(h.passwordResetTTL)
+	if err := h.passwordResetRepo.CreatePasswordResetToken(user.ID, utils.HashToken(resetToken), expiresAt); err != nil {
+		log.Printf("Failed to store password reset token: %v", err)
+		return
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", h.appBaseURL, url.QueryEscape(resetToken))
+	err = h.mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s and can only be used once.\n\n%s\n\nIf you did not request a password reset you can ignore this email.\n",
+			user.Username, h.passwordResetTTL, link),
+	})
+	if err != nil {
+		log.Printf("Failed to send password reset email: %v", err)
+	}
+}
+
+func (h *PasswordHandler) invalidResetToken(c *gin.Context) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": "Invalid or expired reset token",
+	})
+}
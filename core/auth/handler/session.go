@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/auth/session"
+	"go-backend-valos-id/core/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessionRepo *repository.SessionRepository
+	guard       *session.Guard
+}
+
+func NewSessionHandler(sessionRepo *repository.SessionRepository, guard *session.Guard) *SessionHandler {
+	return &SessionHandler{
+		sessionRepo: sessionRepo,
+		guard:       guard,
+	}
+}
+
+// ListSessions lists the active sessions of the authenticated user
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	sessions, err := h.sessionRepo.ListActiveSessions(principal.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve sessions",
+		})
+		return
+	}
+
+	sessionResponses := make([]model.SessionResponse, len(sessions))
+	for i, s := range sessions {
+		sessionResponses[i] = model.SessionResponse{
+			ID:             s.ID,
+			UserAgent:      s.UserAgent,
+			IPAddress:      s.IPAddress,
+			LastSeenAt:     s.LastSeenAt,
+			CreatedAt:      s.CreatedAt,
+			OrganizationID: s.OrganizationID,
+			Current:        s.ID == principal.SessionID,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessionResponses,
+		"count":    len(sessionResponses),
+	})
+}
+
+// DeleteSession revokes one of the authenticated user's sessions
+func (h *SessionHandler) DeleteSession(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+	sessionID := c.Param("id")
+
+	revoked, err := h.sessionRepo.RevokeSession(principal.UserID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke session",
+		})
+		return
+	}
+	if !revoked {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Session not found",
+		})
+		return
+	}
+
+	h.guard.Revoked(sessionID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked successfully",
+	})
+}
+
+// Logout revokes the session the request was made with
+func (h *SessionHandler) Logout(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	if principal.SessionID != "" {
+		if _, err := h.sessionRepo.RevokeSession(principal.UserID, principal.SessionID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to log out",
+			})
+			return
+		}
+		h.guard.Revoked(principal.SessionID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out successfully",
+	})
+}
+
+// LogoutAll revokes every session of the authenticated user
+func (h *SessionHandler) LogoutAll(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	sessionIDs, err := h.sessionRepo.RevokeAllSessions(principal.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to log out",
+		})
+		return
+	}
+
+	h.guard.Revoked(sessionIDs...)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Logged out of all sessions successfully",
+		"revoked_sessions": len(sessionIDs),
+	})
+}
@@ -0,0 +1,422 @@
+package handler
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/auth/webauthn"
+	"go-backend-valos-id/core/middleware"
+	user_repository "go-backend-valos-id/core/user/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebAuthnHandler struct {
+	userRepo     *user_repository.UserRepository
+	webauthnRepo *repository.WebAuthnRepository
+	rp           *webauthn.RelyingParty
+	// auth finishes passkey logins the same way as password logins
+	auth         *AuthHandler
+	challengeTTL time.Duration
+}
+
+func NewWebAuthnHandler(
+	userRepo *user_repository.UserRepository,
+	webauthnRepo *repository.WebAuthnRepository,
+	rp *webauthn.RelyingParty,
+	auth *AuthHandler,
+	challengeTTL time.Duration,
+) *WebAuthnHandler {
+	return &WebAuthnHandler{
+		userRepo:     userRepo,
+		webauthnRepo: webauthnRepo,
+		rp:           rp,
+		auth:         auth,
+		challengeTTL: challengeTTL,
+	}
+}
+
+// BeginRegistration returns the options for navigator.credentials.create to register a passkey for the authenticated user
+func (h *WebAuthnHandler) BeginRegistration(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	user, err := h.userRepo.GetUserByID(principal.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+
+	existing, err := h.webauthnRepo.ListCredentials(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve passkeys",
+		})
+		return
+	}
+
+	challenge, ok := h.createChallenge(c, &user.ID, model.WebAuthnCeremonyRegistration)
+	if !ok {
+		return
+	}
+
+	options := h.rp.CreationOptions(challenge.Challenge, webauthn.UserEntity{
+		ID:          userHandle(user.ID),
+		Name:        user.Email,
+		DisplayName: user.Username,
+	}, toRelyingPartyCredentials(existing))
+
+	c.JSON(http.StatusOK, gin.H{
+		"challenge_id": challenge.ID,
+		"publicKey":    options,
+	})
+}
+
+// FinishRegistration verifies the authenticator response and stores the new passkey
+func (h *WebAuthnHandler) FinishRegistration(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	var req model.FinishWebAuthnRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	challenge, ok := h.consumeChallenge(c, req.ChallengeID, model.WebAuthnCeremonyRegistration)
+	if !ok {
+		return
+	}
+	if challenge.UserID == nil || *challenge.UserID != principal.UserID {
+		h.invalidChallenge(c)
+		return
+	}
+
+	verified, err := h.rp.VerifyRegistration(challenge.Challenge, &req.Credential)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Passkey registration failed",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+
+	credential, err := h.webauthnRepo.CreateCredential(&model.WebAuthnCredential{
+		UserID:       principal.UserID,
+		CredentialID: verified.ID,
+		PublicKey:    verified.PublicKey,
+		SignCount:    verified.SignCount,
+		AAGUID:       verified.AAGUID,
+		Transports:   verified.Transports,
+		Name:         name,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrWebAuthnCredentialExists) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Passkey is already registered",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to store passkey",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Passkey registered successfully",
+		"data":    toWebAuthnCredentialResponse(credential),
+	})
+}
+
+// ListCredentials lists the passkeys of the authenticated user
+func (h *WebAuthnHandler) ListCredentials(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	credentials, err := h.webauthnRepo.ListCredentials(principal.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve passkeys",
+		})
+		return
+	}
+
+	responses := make([]model.WebAuthnCredentialResponse, len(credentials))
+	for i, credential := range credentials {
+		responses[i] = toWebAuthnCredentialResponse(credential)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  responses,
+		"count": len(responses),
+	})
+}
+
+// DeleteCredential removes one of the authenticated user's passkeys
+func (h *WebAuthnHandler) DeleteCredential(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid passkey ID",
+		})
+		return
+	}
+
+	deleted, err := h.webauthnRepo.DeleteCredential(int32(id), principal.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete passkey",
+		})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Passkey not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Passkey deleted successfully",
+	})
+}
+
+// BeginLogin returns the options for navigator.credentials.get.
+// Without an identifier any discoverable passkey for this site can be used.
+func (h *WebAuthnHandler) BeginLogin(c *gin.Context) {
+	var req model.BeginWebAuthnLoginRequest
+	// The body is optional
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var userID *int32
+	var allowed []*model.WebAuthnCredential
+	if req.Identifier != "" {
+		user, err := h.auth.findUser(req.Identifier)
+		if err != nil && err != sql.ErrNoRows {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve user",
+			})
+			return
+		}
+		// Unknown users get options with no credentials rather than an error, so accounts cannot be probed
+		if user != nil {
+			userID = &user.ID
+			allowed, err = h.webauthnRepo.ListCredentials(user.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to retrieve passkeys",
+				})
+				return
+			}
+		}
+	}
+
+	challenge, ok := h.createChallenge(c, userID, model.WebAuthnCeremonyLogin)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"challenge_id": challenge.ID,
+		"publicKey":    h.rp.RequestOptions(challenge.Challenge, toRelyingPartyCredentials(allowed)),
+	})
+}
+
+// FinishLogin verifies a passkey assertion and signs the user in.
+// A user-verified assertion satisfies MFA; otherwise users with TOTP enabled still get an MFA challenge.
+func (h *WebAuthnHandler) FinishLogin(c *gin.Context) {
+	var req model.FinishWebAuthnLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	challenge, ok := h.consumeChallenge(c, req.ChallengeID, model.WebAuthnCeremonyLogin)
+	if !ok {
+		return
+	}
+
+	credential, err := h.webauthnRepo.GetCredentialByCredentialID(req.Credential.RawID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebAuthnCredentialNotFound) {
+			h.passkeyLoginFailed(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve passkey",
+		})
+		return
+	}
+
+	if challenge.UserID != nil && *challenge.UserID != credential.UserID {
+		h.passkeyLoginFailed(c)
+		return
+	}
+	if len(req.Credential.Response.UserHandle) > 0 && !bytes.Equal(req.Credential.Response.UserHandle, userHandle(credential.UserID)) {
+		h.passkeyLoginFailed(c)
+		return
+	}
+
+	result, err := h.rp.VerifyAssertion(challenge.Challenge, &req.Credential, toRelyingPartyCredential(credential))
+	if err != nil {
+		h.passkeyLoginFailed(c)
+		return
+	}
+
+	stored, err := h.webauthnRepo.UpdateSignCount(credential, result.SignCount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update passkey",
+		})
+		return
+	}
+	if !stored {
+		// A concurrent login with the same counter value means the assertion was replayed or the authenticator cloned
+		h.passkeyLoginFailed(c)
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(credential.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.passkeyLoginFailed(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+
+	h.auth.completeLogin(c, user, result.UserVerified)
+}
+
+// Helper methods
+
+func (h *WebAuthnHandler) createChallenge(c *gin.Context, userID *int32, ceremony string) (*model.WebAuthnChallenge, bool) {
+	id, err := utils.RandomHex(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create challenge",
+		})
+		return nil, false
+	}
+
+	value, err := webauthn.NewChallenge()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create challenge",
+		})
+		return nil, false
+	}
+
+	challenge := &model.WebAuthnChallenge{
+		ID:        id,
+		UserID:    userID,
+		Ceremony:  ceremony,
+		Challenge: value,
+		ExpiresAt: time.Now().Add(h.challengeTTL),
+	}
+	if err := h.webauthnRepo.CreateChallenge(challenge); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create challenge",
+		})
+		return nil, false
+	}
+	return challenge, true
+}
+
+func (h *WebAuthnHandler) consumeChallenge(c *gin.Context, id, ceremony string) (*model.WebAuthnChallenge, bool) {
+	challenge, err := h.webauthnRepo.ConsumeChallenge(id, ceremony)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebAuthnChallengeInvalid) {
+			h.invalidChallenge(c)
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve challenge",
+		})
+		return nil, false
+	}
+	return challenge, true
+}
+
+func (h *WebAuthnHandler) invalidChallenge(c *gin.Context) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": "Invalid or expired challenge",
+	})
+}
+
+func (h *WebAuthnHandler) passkeyLoginFailed(c *gin.Context) {
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error": "Passkey login failed",
+	})
+}
+
+// userHandle is the WebAuthn user ID for a user; it carries no personal information
+func userHandle(userID int32) []byte {
+	return []byte(strconv.Itoa(int(userID)))
+}
+
+func toRelyingPartyCredential(credential *model.WebAuthnCredential) *webauthn.Credential {
+	return &webauthn.Credential{
+		ID:         credential.CredentialID,
+		PublicKey:  credential.PublicKey,
+		SignCount:  credential.SignCount,
+		AAGUID:     credential.AAGUID,
+		Transports: credential.Transports,
+	}
+}
+
+func toRelyingPartyCredentials(credentials []*model.WebAuthnCredential) []webauthn.Credential {
+	result := make([]webauthn.Credential, len(credentials))
+	for i, credential := range credentials {
+		result[i] = *toRelyingPartyCredential(credential)
+	}
+	return result
+}
+
+func toWebAuthnCredentialResponse(credential *model.WebAuthnCredential) model.WebAuthnCredentialResponse {
+	return model.WebAuthnCredentialResponse{
+		ID:           credential.ID,
+		Name:         credential.Name,
+		CredentialID: webauthn.URLEncodedBase64(credential.CredentialID).String(),
+		Transports:   credential.Transports,
+		LastUsedAt:   credential.LastUsedAt,
+		CreatedAt:    credential.CreatedAt,
+	}
+}
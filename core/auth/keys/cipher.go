@@ -0,0 +1,54 @@
+package keys
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"go-backend-valos-id/core/internal/aesgcm"
+)
+
+// KeyCipher encrypts private signing keys at rest with AES-256-GCM under the master key
+type KeyCipher struct {
+	cipher *aesgcm.Cipher
+}
+
+func NewKeyCipher(masterKey []byte) (*KeyCipher, error) {
+	if len(masterKey) != 32 {
+		return nil, errors.New("JWT master key must be 32 bytes")
+	}
+
+	c, err := aesgcm.New(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyCipher{cipher: c}, nil
+}
+
+// NewKeyCipherFromEncoded builds a KeyCipher from the base64 encoded JWT_MASTER_KEY
+func NewKeyCipherFromEncoded(encoded string) (*KeyCipher, error) {
+	if encoded == "" {
+		return nil, errors.New("JWT_MASTER_KEY is required for the database key store")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_MASTER_KEY: %w", err)
+	}
+	return NewKeyCipher(key)
+}
+
+// Encrypt returns the base64 encoded nonce and ciphertext of a DER encoded private key.
+// The key ID is bound as associated data so keys cannot be swapped between rows.
+func (c *KeyCipher) Encrypt(der []byte, kid string) (string, error) {
+	return c.cipher.Seal(der, associatedData(kid))
+}
+
+// Decrypt reverses Encrypt
+func (c *KeyCipher) Decrypt(encoded, kid string) ([]byte, error) {
+	return c.cipher.Open(encoded, associatedData(kid))
+}
+
+func associatedData(kid string) []byte {
+	return []byte("signing_key:" + kid)
+}
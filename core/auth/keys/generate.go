@@ -0,0 +1,48 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+
+	"go-backend-valos-id/core/auth/token"
+)
+
+// rsaKeyBits is the modulus size of generated RSA keys
+const rsaKeyBits = 3072
+
+// GenerateKey creates a new private key for the signing algorithm alg
+func GenerateKey(alg string) (crypto.Signer, error) {
+	switch alg {
+	case token.AlgRS256:
+		return rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case token.AlgES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case token.AlgEdDSA:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	default:
+		return nil, fmt.Errorf("cannot generate keys for %q; use RS256, ES256 or EdDSA", alg)
+	}
+}
+
+// parsePrivateKey decodes a PKCS#8 private key and checks that it suits alg
+func parsePrivateKey(der []byte, alg string) (crypto.Signer, error) {
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+	if err := token.CheckKeyType(alg, signer); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
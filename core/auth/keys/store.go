@@ -0,0 +1,303 @@
+// Package keys keeps the token signing keys in the database and rotates them.
+package keys
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/auth/token"
+	"go-backend-valos-id/core/config"
+)
+
+// ErrNoActiveKey is returned while the store has no key to sign with
+var ErrNoActiveKey = errors.New("no active signing key")
+
+// unknownKeyReloadInterval limits how often a token with an unknown kid may force a reload,
+// so forged kids cannot be used to hammer the database
+const unknownKeyReloadInterval = 10 * time.Second
+
+// Store is a token.KeyProvider backed by the signing_keys table.
+// One generated key is active and signs new tokens; retired keys stay published until every token
+// they signed has expired. Keys are cached in-process, so a rotation made by another instance
+// is picked up within the cache TTL.
+type Store struct {
+	repo             *repository.SigningKeyRepository
+	cipher           *KeyCipher
+	algorithm        string
+	rotationInterval time.Duration
+	retention        time.Duration
+	cacheTTL         time.Duration
+
+	mu       sync.Mutex
+	loadedAt time.Time
+	active   *model.SigningKey
+	keys     map[string]*token.Key
+	// published lists the loaded keys, newest first
+	published []*token.Key
+}
+
+// NewStore creates a key store generating algorithm keys. Keys are rotated every rotationInterval
+// and retired keys are published for retention after their last token was signed.
+func NewStore(repo *repository.SigningKeyRepository, cipher *KeyCipher, algorithm string, rotationInterval, retention, cacheTTL time.Duration) *Store {
+	return &Store{
+		repo:             repo,
+		cipher:           cipher,
+		algorithm:        algorithm,
+		rotationInterval: rotationInterval,
+		retention:        retention,
+		cacheTTL:         cacheTTL,
+	}
+}
+
+// NewStoreFromConfig builds a Store from the JWT settings in cfg
+func NewStoreFromConfig(cfg *config.AuthConfig, repo *repository.SigningKeyRepository) (*Store, error) {
+	switch cfg.JWTAlgorithm {
+	case token.AlgRS256, token.AlgES256, token.AlgEdDSA:
+	default:
+		return nil, fmt.Errorf("JWT_ALGORITHM must be %s, %s or %s for the database key store", token.AlgRS256, token.AlgES256, token.AlgEdDSA)
+	}
+	if cfg.JWTKeyRotationInterval <= 0 {
+		return nil, errors.New("JWT_KEY_ROTATION_INTERVAL must be positive")
+	}
+
+	cipher, err := NewKeyCipherFromEncoded(cfg.JWTMasterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// A retired key must outlive every token it signed, including tokens signed by
+	// instances that have not reloaded their keys since the rotation
+	retention := max(cfg.AccessTokenTTL, cfg.MFAChallengeTTL) + cfg.JWTKeyCacheTTL + cfg.ClockSkew
+
+	return NewStore(repo, cipher, cfg.JWTAlgorithm, cfg.JWTKeyRotationInterval, retention, cfg.JWTKeyCacheTTL), nil
+}
+
+func (s *Store) SigningKey() (*token.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(false); err != nil {
+		return nil, err
+	}
+	if s.active == nil {
+		return nil, ErrNoActiveKey
+	}
+	return s.keys[s.active.KeyID], nil
+}
+
+func (s *Store) VerificationKey(kid string) (*token.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(false); err != nil {
+		return nil, err
+	}
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+
+	// The key may have been created by another instance since the last load
+	if time.Since(s.loadedAt) < unknownKeyReloadInterval {
+		return nil, token.ErrUnknownKey
+	}
+	if err := s.load(true); err != nil {
+		return nil, err
+	}
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, token.ErrUnknownKey
+}
+
+func (s *Store) VerificationKeys() ([]*token.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(false); err != nil {
+		return nil, err
+	}
+	return s.published, nil
+}
+
+// ListKeys returns the stored keys that are currently published, newest first
+func (s *Store) ListKeys() ([]*model.SigningKey, error) {
+	return s.repo.ListPublishedKeys()
+}
+
+// Rotate generates a new active key and retires the current one
+func (s *Store) Rotate() (*model.SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(true); err != nil {
+		return nil, err
+	}
+	return s.rotate()
+}
+
+// RotateIfDue rotates when there is no active key, the active key has reached the rotation interval,
+// or it was generated for a different algorithm than the one configured
+func (s *Store) RotateIfDue() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(false); err != nil {
+		return err
+	}
+	if s.active != nil && s.active.Algorithm == s.algorithm && time.Since(s.active.ActivatedAt) < s.rotationInterval {
+		return nil
+	}
+
+	key, err := s.rotate()
+	if errors.Is(err, repository.ErrActiveKeyChanged) {
+		// Another instance rotated first; its key was loaded by rotate
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Rotated token signing key, new key ID %s", key.KeyID)
+	return nil
+}
+
+// Run keeps rotating keys on schedule and removes expired ones until ctx is cancelled
+func (s *Store) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cacheTTL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.RotateIfDue(); err != nil {
+			log.Printf("Failed to rotate signing key: %v", err)
+		}
+		if _, err := s.repo.DeleteExpiredKeys(); err != nil {
+			log.Printf("Failed to delete expired signing keys: %v", err)
+		}
+	}
+}
+
+// rotate creates a new active key, replacing the loaded active key. The caller holds s.mu.
+func (s *Store) rotate() (*model.SigningKey, error) {
+	signer, err := GenerateKey(s.algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	key := &token.Key{
+		Algorithm: s.algorithm,
+		Private:   signer,
+		Public:    signer.Public(),
+	}
+	kid, ok := token.Thumbprint(key)
+	if !ok {
+		return nil, fmt.Errorf("cannot publish %s keys", s.algorithm)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.cipher.Encrypt(privateDER, kid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt signing key: %w", err)
+	}
+
+	stored := &model.SigningKey{
+		KeyID:               kid,
+		Algorithm:           s.algorithm,
+		PrivateKeyEncrypted: encrypted,
+		PublicKey:           publicDER,
+	}
+	var previousKID string
+	if s.active != nil {
+		previousKID = s.active.KeyID
+	}
+
+	rotateErr := s.repo.RotateKey(stored, previousKID, time.Now().Add(s.retention))
+	if err := s.load(true); err != nil {
+		return nil, err
+	}
+	if rotateErr != nil {
+		return nil, rotateErr
+	}
+	return stored, nil
+}
+
+// load refreshes the cached keys once the cache TTL has passed, or immediately when force is set.
+// A failed refresh keeps serving the previously loaded keys. The caller holds s.mu.
+func (s *Store) load(force bool) error {
+	if !force && !s.loadedAt.IsZero() && time.Since(s.loadedAt) < s.cacheTTL {
+		return nil
+	}
+
+	stored, err := s.repo.ListPublishedKeys()
+	if err == nil {
+		err = s.replace(stored)
+	}
+	if err != nil {
+		if s.loadedAt.IsZero() {
+			return err
+		}
+		log.Printf("Failed to reload signing keys, keeping the loaded keys: %v", err)
+	}
+	s.loadedAt = time.Now()
+	return nil
+}
+
+func (s *Store) replace(stored []*model.SigningKey) error {
+	var active *model.SigningKey
+	keys := make(map[string]*token.Key, len(stored))
+	published := make([]*token.Key, 0, len(stored))
+
+	for _, sk := range stored {
+		key := &token.Key{
+			ID:        sk.KeyID,
+			Algorithm: sk.Algorithm,
+		}
+
+		if sk.Status == model.SigningKeyActive {
+			der, err := s.cipher.Decrypt(sk.PrivateKeyEncrypted, sk.KeyID)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt signing key %s; check JWT_MASTER_KEY: %w", sk.KeyID, err)
+			}
+			signer, err := parsePrivateKey(der, sk.Algorithm)
+			if err != nil {
+				return fmt.Errorf("failed to parse signing key %s: %w", sk.KeyID, err)
+			}
+			key.Private = signer
+			key.Public = signer.Public()
+			active = sk
+		} else {
+			// Retired keys only verify, so their private part is never decrypted
+			public, err := x509.ParsePKIXPublicKey(sk.PublicKey)
+			if err != nil {
+				return fmt.Errorf("failed to parse public key %s: %w", sk.KeyID, err)
+			}
+			key.Public = public
+		}
+
+		keys[key.ID] = key
+		published = append(published, key)
+	}
+
+	s.active = active
+	s.keys = keys
+	s.published = published
+	return nil
+}
@@ -0,0 +1,123 @@
+package lockout
+
+import (
+	"sync"
+	"time"
+
+	audit_model "go-backend-valos-id/core/audit/model"
+	"go-backend-valos-id/core/auth/model"
+)
+
+// Store keeps failed login counts. repository.LoginAttemptRepository shares them between instances
+// through the database; MemoryStore keeps them in-process.
+type Store interface {
+	// GetLoginAttempt returns the failures recorded for subject, or nil if there are none
+	GetLoginAttempt(subject string) (*model.LoginAttempt, error)
+	// RecordLoginFailure counts a failure and returns the failure count, starting over
+	// if the previous failure happened before resetBefore
+	RecordLoginFailure(subject string, failedAt, resetBefore time.Time) (int, error)
+	// BlockLoginSubject blocks subject until the given time unless it is already blocked for longer
+	BlockLoginSubject(subject string, until time.Time) error
+	DeleteLoginAttempt(subject string) error
+	// UnlockLoginSubject forgets the failures of subject on request of origin, recording it in the audit log
+	UnlockLoginSubject(subject string, origin *audit_model.Origin) error
+	// DeleteStaleLoginAttempts removes subjects that last failed before resetBefore and are no longer blocked at now
+	DeleteStaleLoginAttempts(resetBefore, now time.Time) (int64, error)
+}
+
+// EventRecorder writes audit events outside of any other transaction; audit_repository.AuditRepository is one
+type EventRecorder interface {
+	RecordEvent(origin *audit_model.Origin, action, targetType, targetID string, before, after any) error
+}
+
+// MemoryStore is a Store for single instance deployments. Unlocks are recorded through events, if set,
+// right after they happen, since the counts are not in the database.
+type MemoryStore struct {
+	mu       sync.Mutex
+	attempts map[string]*model.LoginAttempt
+	events   EventRecorder
+}
+
+func NewMemoryStore(events EventRecorder) *MemoryStore {
+	return &MemoryStore{
+		attempts: make(map[string]*model.LoginAttempt),
+		events:   events,
+	}
+}
+
+func (s *MemoryStore) GetLoginAttempt(subject string) (*model.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[subject]
+	if !ok {
+		return nil, nil
+	}
+	copied := *attempt
+	return &copied, nil
+}
+
+func (s *MemoryStore) RecordLoginFailure(subject string, failedAt, resetBefore time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[subject]
+	if !ok {
+		attempt = &model.LoginAttempt{Subject: subject}
+		s.attempts[subject] = attempt
+	}
+	if attempt.LastFailureAt.Before(resetBefore) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = failedAt
+	return attempt.Failures, nil
+}
+
+func (s *MemoryStore) BlockLoginSubject(subject string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[subject]
+	if !ok {
+		return nil
+	}
+	if attempt.BlockedUntil == nil || attempt.BlockedUntil.Before(until) {
+		attempt.BlockedUntil = &until
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteLoginAttempt(subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, subject)
+	return nil
+}
+
+func (s *MemoryStore) UnlockLoginSubject(subject string, origin *audit_model.Origin) error {
+	s.mu.Lock()
+	attempt, ok := s.attempts[subject]
+	delete(s.attempts, subject)
+	s.mu.Unlock()
+
+	if !ok || s.events == nil {
+		return nil
+	}
+	return s.events.RecordEvent(origin, audit_model.ActionLockoutLifted, audit_model.TargetLockout, subject, attempt, nil)
+}
+
+func (s *MemoryStore) DeleteStaleLoginAttempts(resetBefore, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for subject, attempt := range s.attempts {
+		if attempt.LastFailureAt.Before(resetBefore) && (attempt.BlockedUntil == nil || attempt.BlockedUntil.Before(now)) {
+			delete(s.attempts, subject)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
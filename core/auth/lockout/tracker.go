@@ -0,0 +1,264 @@
+// Package lockout protects password logins against brute force. Failed logins are counted per account
+// and per IP address; once a subject fails too often it is blocked for a delay that doubles with every
+// further failure. Accounts that keep failing from one IP address can also be locked until an administrator
+// reactivates them.
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	audit_model "go-backend-valos-id/core/audit/model"
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/config"
+	"go-backend-valos-id/core/utils"
+)
+
+// Policy decides how long a subject is blocked after a number of failures
+type Policy struct {
+	// MaxFailures is how many failures are tolerated before the subject is blocked; 0 disables blocking
+	MaxFailures int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Delay returns how long a subject with the given number of failures is blocked
+func (p Policy) Delay(failures int) time.Duration {
+	if p.MaxFailures <= 0 || failures < p.MaxFailures {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.MaxFailures; i < failures; i++ {
+		// Doubling past MaxDelay could overflow with a large MaxDelay
+		if delay > p.MaxDelay/2 {
+			return p.MaxDelay
+		}
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// Attempt names the subjects a login attempt is counted against
+type Attempt struct {
+	account string
+	ip      string
+	// userID is the user logging in, or 0 if the identifier matches no user
+	userID int32
+}
+
+// ForUser is an attempt to log in as a known user
+func ForUser(userID int32, ip string) Attempt {
+	return Attempt{account: accountSubject(userID), ip: ipSubject(ip), userID: userID}
+}
+
+// ForIdentifier is an attempt to log in with an email or username that matches no user.
+// It is counted like an account, so responses do not reveal which identifiers exist.
+// The identifier is stored hashed, keeping subjects short and free of unregistered addresses.
+func ForIdentifier(identifier, ip string) Attempt {
+	return Attempt{account: "identifier:" + utils.HashToken(strings.ToLower(identifier)), ip: ipSubject(ip)}
+}
+
+// AccountLocker sets the status of a user to locked
+type AccountLocker interface {
+	LockUser(userID int32, reason string) error
+}
+
+// Tracker counts failed logins and reports blocked subjects
+type Tracker struct {
+	store         Store
+	account       Policy
+	ip            Policy
+	failureWindow time.Duration
+	// locker locks accounts once they fail lockFailures times from one IP address; a nil locker never locks accounts
+	locker       AccountLocker
+	lockFailures int
+}
+
+func NewTracker(store Store, account, ip Policy, failureWindow time.Duration) *Tracker {
+	return &Tracker{
+		store:         store,
+		account:       account,
+		ip:            ip,
+		failureWindow: failureWindow,
+	}
+}
+
+// NewTrackerFromConfig builds a Tracker on the store selected by cfg, locking accounts through locker
+// if cfg sets a number of failures to lock them after. The memory store records unlocks through events.
+func NewTrackerFromConfig(cfg *config.LockoutConfig, repo *repository.LoginAttemptRepository, locker AccountLocker, events EventRecorder) (*Tracker, error) {
+	if cfg.FailureWindow <= 0 {
+		return nil, errors.New("LOCKOUT_FAILURE_WINDOW must be positive")
+	}
+	if cfg.BaseDelay <= 0 || cfg.MaxDuration < cfg.BaseDelay {
+		return nil, errors.New("LOCKOUT_BASE_DELAY must be positive and at most LOCKOUT_MAX_DURATION")
+	}
+
+	var store Store
+	switch cfg.Store {
+	case config.LockoutStoreMemory:
+		store = NewMemoryStore(events)
+	case config.LockoutStoreDatabase:
+		store = repo
+	default:
+		return nil, fmt.Errorf("unsupported lockout store %q", cfg.Store)
+	}
+
+	account := Policy{MaxFailures: cfg.AccountMaxFailures, BaseDelay: cfg.BaseDelay, MaxDelay: cfg.MaxDuration}
+	ip := Policy{MaxFailures: cfg.IPMaxFailures, BaseDelay: cfg.BaseDelay, MaxDelay: cfg.MaxDuration}
+	tracker := NewTracker(store, account, ip, cfg.FailureWindow)
+	if cfg.AccountLockFailures > 0 {
+		tracker.locker = locker
+		tracker.lockFailures = cfg.AccountLockFailures
+	}
+	return tracker, nil
+}
+
+// Check returns how long the attempt must wait because its account or IP address is blocked, or 0
+func (t *Tracker) Check(a Attempt) (time.Duration, error) {
+	now := time.Now()
+
+	var retryAfter time.Duration
+	for _, subject := range a.subjects() {
+		attempt, err := t.store.GetLoginAttempt(subject)
+		if err != nil {
+			return 0, err
+		}
+		if attempt != nil && attempt.BlockedUntil != nil && attempt.BlockedUntil.After(now) {
+			retryAfter = max(retryAfter, attempt.BlockedUntil.Sub(now))
+		}
+	}
+	return retryAfter, nil
+}
+
+// Failed counts a failed attempt against its account and IP address, blocking those that failed too often
+// and locking accounts that failed often enough from the attempt's IP address to be locked
+func (t *Tracker) Failed(a Attempt) error {
+	now := time.Now()
+
+	for _, subject := range a.subjects() {
+		failures, err := t.store.RecordLoginFailure(subject, now, now.Add(-t.failureWindow))
+		if err != nil {
+			return err
+		}
+
+		policy := t.account
+		if subject == a.ip {
+			policy = t.ip
+		}
+		if delay := policy.Delay(failures); delay > 0 {
+			if err := t.store.BlockLoginSubject(subject, now.Add(delay)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return t.lockIfRepeated(a, now)
+}
+
+// Succeeded forgets the failures of the attempt's account. The IP address keeps its count,
+// so one valid account cannot be used to reset the count of an address guessing others.
+func (t *Tracker) Succeeded(a Attempt) error {
+	if a.account == "" {
+		return nil
+	}
+	if subject := a.lockSubject(); subject != "" {
+		if err := t.store.DeleteLoginAttempt(subject); err != nil {
+			return err
+		}
+	}
+	return t.store.DeleteLoginAttempt(a.account)
+}
+
+// AccountStatus returns the failures counted against a user, or nil if there are none
+func (t *Tracker) AccountStatus(userID int32) (*model.LoginAttempt, error) {
+	return t.store.GetLoginAttempt(accountSubject(userID))
+}
+
+// UnlockAccount forgets the failures of a user on request of origin, lifting a lockout
+func (t *Tracker) UnlockAccount(userID int32, origin *audit_model.Origin) error {
+	return t.store.UnlockLoginSubject(accountSubject(userID), origin)
+}
+
+// UnlockIP forgets the failures of an IP address on request of origin, lifting a block
+func (t *Tracker) UnlockIP(ip string, origin *audit_model.Origin) error {
+	return t.store.UnlockLoginSubject(ipSubject(ip), origin)
+}
+
+// Run removes failure counts that no longer matter until ctx is cancelled
+func (t *Tracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.failureWindow)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		if _, err := t.store.DeleteStaleLoginAttempts(now.Add(-t.failureWindow), now); err != nil {
+			log.Printf("Failed to delete stale login attempts: %v", err)
+		}
+	}
+}
+
+// lockIfRepeated locks the account of an attempt once it has failed lockFailures times from the attempt's IP address.
+// Counting per address keeps a spread out guessing campaign, or anyone who merely knows the account, from
+// locking it without repeatedly failing from one address; the count starts over once the account is locked.
+func (t *Tracker) lockIfRepeated(a Attempt, now time.Time) error {
+	subject := a.lockSubject()
+	if t.locker == nil || subject == "" {
+		return nil
+	}
+
+	failures, err := t.store.RecordLoginFailure(subject, now, now.Add(-t.failureWindow))
+	if err != nil {
+		return err
+	}
+	if failures < t.lockFailures {
+		return nil
+	}
+
+	if err := t.locker.LockUser(a.userID, "too many failed login attempts"); err != nil {
+		return err
+	}
+	return t.store.DeleteLoginAttempt(subject)
+}
+
+func (a Attempt) subjects() []string {
+	subjects := make([]string, 0, 2)
+	if a.account != "" {
+		subjects = append(subjects, a.account)
+	}
+	if a.ip != "" {
+		subjects = append(subjects, a.ip)
+	}
+	return subjects
+}
+
+// lockSubject is the subject counting failures of a known user from one IP address, or empty if there is none
+func (a Attempt) lockSubject() string {
+	if a.userID == 0 || a.ip == "" {
+		return ""
+	}
+	return accountSubject(a.userID) + ":" + a.ip
+}
+
+func accountSubject(userID int32) string {
+	return "account:" + strconv.FormatInt(int64(userID), 10)
+}
+
+func ipSubject(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	return "ip:" + ip
+}
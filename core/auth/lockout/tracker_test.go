@@ -0,0 +1,172 @@
+package lockout_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go-backend-valos-id/core/auth/lockout"
+)
+
+func TestPolicyDelay(t *testing.T) {
+	policy := lockout.Policy{MaxFailures: 5, BaseDelay: 30 * time.Second, MaxDelay: 15 * time.Minute}
+
+	tests := []struct {
+		name     string
+		policy   lockout.Policy
+		failures int
+		want     time.Duration
+	}{
+		{name: "no failures", policy: policy, failures: 0, want: 0},
+		{name: "below the limit", policy: policy, failures: 4, want: 0},
+		{name: "at the limit", policy: policy, failures: 5, want: 30 * time.Second},
+		{name: "doubled", policy: policy, failures: 6, want: time.Minute},
+		{name: "doubled again", policy: policy, failures: 9, want: 8 * time.Minute},
+		{name: "clamped", policy: policy, failures: 10, want: 15 * time.Minute},
+		{name: "clamped far past the limit", policy: policy, failures: 10_000, want: 15 * time.Minute},
+		{
+			name:     "base delay above the maximum",
+			policy:   lockout.Policy{MaxFailures: 1, BaseDelay: time.Hour, MaxDelay: time.Minute},
+			failures: 1,
+			want:     time.Minute,
+		},
+		{
+			name:     "maximum reached exactly",
+			policy:   lockout.Policy{MaxFailures: 1, BaseDelay: time.Second, MaxDelay: 4 * time.Second},
+			failures: 3,
+			want:     4 * time.Second,
+		},
+		{
+			name:     "maximum near overflow",
+			policy:   lockout.Policy{MaxFailures: 1, BaseDelay: time.Second, MaxDelay: math.MaxInt64},
+			failures: 100,
+			want:     math.MaxInt64,
+		},
+		{
+			name:     "disabled",
+			policy:   lockout.Policy{MaxFailures: 0, BaseDelay: time.Second, MaxDelay: time.Minute},
+			failures: 100,
+			want:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Delay(tt.failures); got != tt.want {
+				t.Fatalf("Delay(%d) = %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackerBlocks(t *testing.T) {
+	account := lockout.Policy{MaxFailures: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	ip := lockout.Policy{MaxFailures: 5, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	tracker := lockout.NewTracker(lockout.NewMemoryStore(nil), account, ip, time.Hour)
+
+	attempt := lockout.ForUser(42, "203.0.113.1")
+	for i := 1; i <= 3; i++ {
+		if retryAfter, err := tracker.Check(attempt); err != nil || retryAfter != 0 {
+			t.Fatalf("Check before failure %d = %v, %v, want not blocked", i, retryAfter, err)
+		}
+		if err := tracker.Failed(attempt); err != nil {
+			t.Fatalf("Failed: %v", err)
+		}
+	}
+
+	retryAfter, err := tracker.Check(attempt)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if retryAfter <= 0 || retryAfter > time.Minute {
+		t.Fatalf("Check after 3 failures = %v, want a block of at most %v", retryAfter, time.Minute)
+	}
+
+	// Another address trying the same account is blocked too
+	if retryAfter, _ := tracker.Check(lockout.ForUser(42, "198.51.100.7")); retryAfter <= 0 {
+		t.Fatalf("Check from another address = %v, want blocked", retryAfter)
+	}
+	// but the address may still try other accounts
+	if retryAfter, _ := tracker.Check(lockout.ForUser(43, "203.0.113.1")); retryAfter != 0 {
+		t.Fatalf("Check of another account = %v, want not blocked", retryAfter)
+	}
+}
+
+func TestTrackerSucceededKeepsIPCount(t *testing.T) {
+	account := lockout.Policy{MaxFailures: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	ip := lockout.Policy{MaxFailures: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	store := lockout.NewMemoryStore(nil)
+	tracker := lockout.NewTracker(store, account, ip, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if err := tracker.Failed(lockout.ForIdentifier("alice@example.com", "203.0.113.1")); err != nil {
+			t.Fatalf("Failed: %v", err)
+		}
+	}
+	// A valid login with another account forgets that account's failures only
+	if err := tracker.Succeeded(lockout.ForUser(43, "203.0.113.1")); err != nil {
+		t.Fatalf("Succeeded: %v", err)
+	}
+	if err := tracker.Failed(lockout.ForIdentifier("bob@example.com", "203.0.113.1")); err != nil {
+		t.Fatalf("Failed: %v", err)
+	}
+
+	retryAfter, err := tracker.Check(lockout.ForIdentifier("carol@example.com", "203.0.113.1"))
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if retryAfter <= 0 {
+		t.Fatalf("Check after 3 failures from one address = %v, want blocked", retryAfter)
+	}
+}
+
+func TestMemoryStoreFailureWindow(t *testing.T) {
+	store := lockout.NewMemoryStore(nil)
+	start := time.UnixMilli(1_700_000_000_000)
+	window := 15 * time.Minute
+
+	tests := []struct {
+		name     string
+		failedAt time.Time
+		want     int
+	}{
+		{name: "first failure", failedAt: start, want: 1},
+		{name: "within the window", failedAt: start.Add(window), want: 2},
+		{name: "window restarts at each failure", failedAt: start.Add(2 * window), want: 3},
+		{name: "after the window", failedAt: start.Add(3*window + time.Millisecond), want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures, err := store.RecordLoginFailure("ip:203.0.113.1", tt.failedAt, tt.failedAt.Add(-window))
+			if err != nil {
+				t.Fatalf("RecordLoginFailure: %v", err)
+			}
+			if failures != tt.want {
+				t.Fatalf("RecordLoginFailure = %d, want %d", failures, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreBlockOnlyExtends(t *testing.T) {
+	store := lockout.NewMemoryStore(nil)
+	now := time.UnixMilli(1_700_000_000_000)
+	if _, err := store.RecordLoginFailure("ip:203.0.113.1", now, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("RecordLoginFailure: %v", err)
+	}
+
+	for _, until := range []time.Time{now.Add(time.Hour), now.Add(time.Minute)} {
+		if err := store.BlockLoginSubject("ip:203.0.113.1", until); err != nil {
+			t.Fatalf("BlockLoginSubject: %v", err)
+		}
+	}
+
+	attempt, err := store.GetLoginAttempt("ip:203.0.113.1")
+	if err != nil {
+		t.Fatalf("GetLoginAttempt: %v", err)
+	}
+	if attempt.BlockedUntil == nil || !attempt.BlockedUntil.Equal(now.Add(time.Hour)) {
+		t.Fatalf("BlockedUntil = %v, want %v", attempt.BlockedUntil, now.Add(time.Hour))
+	}
+}
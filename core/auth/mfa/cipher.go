@@ -0,0 +1,67 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+
+	"go-backend-valos-id/core/config"
+	"go-backend-valos-id/core/internal/aesgcm"
+)
+
+// SecretCipher encrypts TOTP secrets at rest with AES-256-GCM
+type SecretCipher struct {
+	cipher *aesgcm.Cipher
+}
+
+func NewSecretCipher(key []byte) (*SecretCipher, error) {
+	if len(key) != 32 {
+		return nil, errors.New("MFA encryption key must be 32 bytes")
+	}
+
+	c, err := aesgcm.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretCipher{cipher: c}, nil
+}
+
+// NewSecretCipherFromConfig builds a SecretCipher from the base64 encoded MFA_ENCRYPTION_KEY
+func NewSecretCipherFromConfig(cfg *config.AuthConfig) (*SecretCipher, error) {
+	if cfg.MFAEncryptionKey == "" {
+		// Fall back to an ephemeral key so local setups work without configuration
+		log.Println("MFA_ENCRYPTION_KEY is not set, using an ephemeral key; TOTP enrollments will not survive a restart")
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate MFA encryption key: %w", err)
+		}
+		return NewSecretCipher(key)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(cfg.MFAEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MFA_ENCRYPTION_KEY: %w", err)
+	}
+	return NewSecretCipher(key)
+}
+
+// Encrypt returns the base64 encoded nonce and ciphertext of plaintext.
+// The user ID is bound as associated data so secrets cannot be swapped between rows.
+func (c *SecretCipher) Encrypt(plaintext string, userID int32) (string, error) {
+	return c.cipher.Seal([]byte(plaintext), associatedData(userID))
+}
+
+// Decrypt reverses Encrypt
+func (c *SecretCipher) Decrypt(encoded string, userID int32) (string, error) {
+	plaintext, err := c.cipher.Open(encoded, associatedData(userID))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func associatedData(userID int32) []byte {
+	return []byte(fmt.Sprintf("user_totp:%d", userID))
+}
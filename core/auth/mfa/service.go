@@ -0,0 +1,177 @@
+package mfa
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/auth/repository"
+	user_model "go-backend-valos-id/core/user/model"
+	"go-backend-valos-id/core/utils"
+)
+
+var (
+	ErrTOTPAlreadyEnabled = errors.New("TOTP is already enabled")
+	ErrTOTPNotEnabled     = errors.New("TOTP is not enabled")
+	ErrInvalidCode        = errors.New("invalid MFA code")
+)
+
+// Service manages TOTP enrollment and checks second-factor codes
+type Service struct {
+	mfaRepo *repository.MFARepository
+	cipher  *SecretCipher
+	issuer  string
+}
+
+func NewService(mfaRepo *repository.MFARepository, cipher *SecretCipher, issuer string) *Service {
+	return &Service{
+		mfaRepo: mfaRepo,
+		cipher:  cipher,
+		issuer:  issuer,
+	}
+}
+
+// Enabled reports whether the user has a confirmed TOTP enrollment
+func (s *Service) Enabled(userID int32) (bool, error) {
+	totp, err := s.ConfirmedTOTP(userID)
+	if err != nil {
+		return false, err
+	}
+	return totp != nil, nil
+}
+
+// BeginEnrollment stores a new pending secret for the user and returns it with its otpauth:// URI
+func (s *Service) BeginEnrollment(user *user_model.User) (secret, uri string, err error) {
+	enabled, err := s.Enabled(user.ID)
+	if err != nil {
+		return "", "", err
+	}
+	if enabled {
+		return "", "", ErrTOTPAlreadyEnabled
+	}
+
+	secret, err = GenerateSecret()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	encrypted, err := s.cipher.Encrypt(secret, user.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt TOTP secret: %w", err)
+	}
+
+	if err := s.mfaRepo.SaveTOTPEnrollment(user.ID, encrypted); err != nil {
+		return "", "", err
+	}
+
+	return secret, KeyURI(s.issuer, user.Email, secret), nil
+}
+
+// ConfirmEnrollment enables the pending enrollment once the first code checks out
+// and returns a fresh set of recovery codes. The codes are only stored hashed.
+func (s *Service) ConfirmEnrollment(userID int32, code string) ([]string, error) {
+	totp, err := s.mfaRepo.GetTOTP(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTOTPNotFound) {
+			return nil, ErrTOTPNotEnabled
+		}
+		return nil, err
+	}
+	if totp.Confirmed() {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := s.cipher.Decrypt(totp.SecretEncrypted, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+
+	step, ok := ValidateCode(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = utils.HashToken(NormalizeRecoveryCode(c))
+	}
+
+	if err := s.mfaRepo.ConfirmTOTP(userID, step, hashes); err != nil {
+		if errors.Is(err, repository.ErrTOTPNotFound) {
+			return nil, ErrTOTPAlreadyEnabled
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Verify checks a TOTP code or, failing that, consumes a recovery code.
+// Each TOTP time step is accepted once so an observed code cannot be replayed.
+func (s *Service) Verify(userID int32, code string) error {
+	totp, err := s.mfaRepo.GetTOTP(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTOTPNotFound) {
+			return ErrTOTPNotEnabled
+		}
+		return err
+	}
+	if !totp.Confirmed() {
+		return ErrTOTPNotEnabled
+	}
+
+	secret, err := s.cipher.Decrypt(totp.SecretEncrypted, userID)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+
+	if step, ok := ValidateCode(secret, code, time.Now()); ok {
+		fresh, err := s.mfaRepo.UseTOTPStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return ErrInvalidCode
+		}
+		return nil
+	}
+
+	used, err := s.mfaRepo.ConsumeRecoveryCode(userID, utils.HashToken(NormalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// Disable removes the user's TOTP enrollment and recovery codes
+func (s *Service) Disable(userID int32) error {
+	return s.mfaRepo.DeleteTOTP(userID)
+}
+
+// RecoveryCodesRemaining returns the number of unused recovery codes
+func (s *Service) RecoveryCodesRemaining(userID int32) (int64, error) {
+	return s.mfaRepo.CountRecoveryCodes(userID)
+}
+
+// ConfirmedTOTP returns the user's TOTP enrollment, or nil if TOTP is not enabled
+func (s *Service) ConfirmedTOTP(userID int32) (*model.TOTP, error) {
+	totp, err := s.mfaRepo.GetTOTP(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTOTPNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !totp.Confirmed() {
+		return nil, nil
+	}
+	return totp, nil
+}
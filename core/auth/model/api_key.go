@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+)
+
+// APIKey is a personal API key. The key itself is only returned once, when it is created.
+type APIKey struct {
+	ID     int32  `json:"id" db:"id"`
+	UserID int32  `json:"-" db:"user_id"`
+	Name   string `json:"name" db:"name"`
+	// Prefix is the visible start of the key
+	Prefix string `json:"prefix" db:"prefix"`
+	// Scopes are the permissions the key may exercise
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	LastUsedIP *string    `json:"last_used_ip" db:"last_used_ip"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	// OwnerAccountType is the account type of the key's owner; it is only filled in when the key is used
+	OwnerAccountType string `json:"-"`
+}
+
+type APIKeyCreateRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is optional; keys without it stay valid until revoked
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyCreateResponse struct {
+	APIKey
+	// Key is the secret API key, shown only in this response
+	Key string `json:"key"`
+}
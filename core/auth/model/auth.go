@@ -0,0 +1,19 @@
+package model
+
+type LoginRequest struct {
+	// Identifier is either the user's email address or username
+	Identifier string `json:"identifier" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+}
+
+// OrganizationSwitchRequest selects the organization a session acts within; null or 0 leaves it
+type OrganizationSwitchRequest struct {
+	OrganizationID *int32 `json:"organization_id"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
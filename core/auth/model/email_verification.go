@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+type EmailVerificationToken struct {
+	ID        int32     `json:"id" db:"id"`
+	UserID    int32     `json:"user_id" db:"user_id"`
+	Email     string    `json:"email" db:"email"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
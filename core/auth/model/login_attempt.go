@@ -0,0 +1,14 @@
+package model
+
+import (
+	"time"
+)
+
+// LoginAttempt counts the recent failed logins of a subject, an account or an IP address
+type LoginAttempt struct {
+	Subject       string    `json:"subject" db:"subject"`
+	Failures      int       `json:"failures" db:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at" db:"last_failure_at"`
+	// BlockedUntil is set once the subject failed too often; it may lie in the past
+	BlockedUntil *time.Time `json:"blocked_until" db:"blocked_until"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+)
+
+type TOTP struct {
+	UserID          int32      `json:"user_id" db:"user_id"`
+	SecretEncrypted string     `json:"-" db:"secret_encrypted"`
+	ConfirmedAt     *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	LastUsedStep    int64      `json:"-" db:"last_used_step"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Confirmed reports whether the enrollment was confirmed with a first code
+func (t *TOTP) Confirmed() bool {
+	return t.ConfirmedAt != nil
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+	// Code is a current TOTP code or an unused recovery code
+	Code string `json:"code" binding:"required"`
+}
+
+type MFAStatusResponse struct {
+	TOTPEnabled            bool       `json:"totp_enabled"`
+	TOTPConfirmedAt        *time.Time `json:"totp_confirmed_at"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// MFAChallengeResponse is returned by login instead of tokens when the user has MFA enabled
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	// Code is a current TOTP code or an unused recovery code
+	Code string `json:"code" binding:"required"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+type PasswordResetToken struct {
+	ID        int32     `json:"id" db:"id"`
+	UserID    int32     `json:"user_id" db:"user_id"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+type RefreshToken struct {
+	ID       int32  `json:"id" db:"id"`
+	UserID   int32  `json:"user_id" db:"user_id"`
+	FamilyID string `json:"family_id" db:"family_id"`
+	// UsedAt is set once the token has been exchanged for its successor
+	UsedAt    *time.Time `json:"used_at,omitempty" db:"used_at"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+type Session struct {
+	ID         string    `json:"id" db:"id"`
+	UserID     int32     `json:"user_id" db:"user_id"`
+	UserAgent  string    `json:"user_agent" db:"user_agent"`
+	IPAddress  string    `json:"ip_address" db:"ip_address"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	// OrganizationID is the organization the session acts within, if any
+	OrganizationID *int32 `json:"organization_id" db:"organization_id"`
+}
+
+type SessionResponse struct {
+	ID             string    `json:"id"`
+	UserAgent      string    `json:"user_agent"`
+	IPAddress      string    `json:"ip_address"`
+	LastSeenAt     time.Time `json:"last_seen_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	OrganizationID *int32    `json:"organization_id"`
+	// Current marks the session the request was made with
+	Current bool `json:"current"`
+}
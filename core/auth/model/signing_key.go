@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+)
+
+// Signing key statuses
+const (
+	SigningKeyActive  = "active"
+	SigningKeyRetired = "retired"
+)
+
+// SigningKey is a token signing key as stored in the key store
+type SigningKey struct {
+	ID        int32  `json:"id" db:"id"`
+	KeyID     string `json:"kid" db:"kid"`
+	Algorithm string `json:"algorithm" db:"algorithm"`
+	// PrivateKeyEncrypted is the AES-GCM encrypted PKCS#8 private key
+	PrivateKeyEncrypted string `json:"-" db:"private_key_encrypted"`
+	// PublicKey is the PKIX DER encoded public key
+	PublicKey   []byte     `json:"-" db:"public_key"`
+	Status      string     `json:"status" db:"status"`
+	ActivatedAt time.Time  `json:"activated_at" db:"activated_at"`
+	RetiredAt   *time.Time `json:"retired_at" db:"retired_at"`
+	// ExpiresAt is when a retired key stops being published
+	ExpiresAt *time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
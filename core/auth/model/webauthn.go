@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"go-backend-valos-id/core/auth/webauthn"
+)
+
+// WebAuthn ceremonies a challenge can be used for
+const (
+	WebAuthnCeremonyRegistration = "registration"
+	WebAuthnCeremonyLogin        = "login"
+)
+
+type WebAuthnCredential struct {
+	ID           int32      `json:"id" db:"id"`
+	UserID       int32      `json:"user_id" db:"user_id"`
+	CredentialID []byte     `json:"-" db:"credential_id"`
+	PublicKey    []byte     `json:"-" db:"public_key"`
+	SignCount    uint32     `json:"-" db:"sign_count"`
+	AAGUID       []byte     `json:"-" db:"aaguid"`
+	Transports   []string   `json:"transports" db:"transports"`
+	Name         string     `json:"name" db:"name"`
+	LastUsedAt   *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+type WebAuthnCredentialResponse struct {
+	ID           int32      `json:"id"`
+	Name         string     `json:"name"`
+	CredentialID string     `json:"credential_id"`
+	Transports   []string   `json:"transports"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type WebAuthnChallenge struct {
+	ID string `json:"id" db:"id"`
+	// UserID is nil for logins that let the authenticator pick a discoverable credential
+	UserID    *int32    `json:"user_id" db:"user_id"`
+	Ceremony  string    `json:"ceremony" db:"ceremony"`
+	Challenge []byte    `json:"-" db:"challenge"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
+
+type FinishWebAuthnRegistrationRequest struct {
+	ChallengeID string                        `json:"challenge_id" binding:"required"`
+	Name        string                        `json:"name" binding:"max=100"`
+	Credential  webauthn.RegistrationResponse `json:"credential" binding:"required"`
+}
+
+type BeginWebAuthnLoginRequest struct {
+	// Identifier is an optional email or username; without it any discoverable credential can be used
+	Identifier string `json:"identifier"`
+}
+
+type FinishWebAuthnLoginRequest struct {
+	ChallengeID string                     `json:"challenge_id" binding:"required"`
+	Credential  webauthn.AssertionResponse `json:"credential" binding:"required"`
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	audit_model "go-backend-valos-id/core/audit/model"
+	audit_repository "go-backend-valos-id/core/audit/repository"
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrAPIKeyInvalid is returned for API keys that are unknown, expired or revoked
+	ErrAPIKeyInvalid = errors.New("API key is invalid")
+	// ErrUnknownScope is returned when an API key is given a scope that is not a permission
+	ErrUnknownScope = errors.New("unknown scope")
+)
+
+type APIKeyRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewAPIKeyRepository(pool *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// CreateAPIKey stores an API key by the hash of the key, after checking that its scopes are permissions,
+// and records it in the audit log
+func (r *APIKeyRepository) CreateAPIKey(key *model.APIKey, keyHash string, origin *audit_model.Origin) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	scopes := slices.Compact(slices.Sorted(slices.Values(key.Scopes)))
+	if len(scopes) > 0 {
+		count, err := qtx.CountPermissionsByName(ctx, scopes)
+		if err != nil {
+			return err
+		}
+		if count != int64(len(scopes)) {
+			return ErrUnknownScope
+		}
+	}
+
+	var expiresAt pgtype.Int8
+	if key.ExpiresAt != nil {
+		expiresAt = utils.ToEpochMillis(*key.ExpiresAt)
+	}
+
+	result, err := qtx.CreateAPIKey(ctx, repository.CreateAPIKeyParams{
+		UserID:    key.UserID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		KeyHash:   keyHash,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: utils.ToEpochMillis(time.Now()),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := r.recordChange(ctx, qtx, origin, audit_model.ActionAPIKeyCreated, nil, &result); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	*key = *r.sqlcAPIKeyToModel(&result)
+	return nil
+}
+
+// ListAPIKeys returns the API keys of a user that have not been revoked, including expired ones
+func (r *APIKeyRepository) ListAPIKeys(userID int32) ([]model.APIKey, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListAPIKeysByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]model.APIKey, len(results))
+	for i := range results {
+		keys[i] = *r.sqlcAPIKeyToModel(&results[i])
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key of a user, recording it in the audit log.
+// It returns false if the user has no such active key.
+func (r *APIKeyRepository) RevokeAPIKey(userID, id int32, origin *audit_model.Origin) (bool, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	result, err := qtx.RevokeAPIKey(ctx, repository.RevokeAPIKeyParams{
+		ID:        id,
+		UserID:    userID,
+		RevokedAt: utils.ToEpochMillis(time.Now()),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := r.recordChange(ctx, qtx, origin, audit_model.ActionAPIKeyRevoked, &result, nil); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UseAPIKey looks up an API key by its hash and records that it was used from ipAddress.
+// It returns ErrAPIKeyInvalid if the key is unknown, expired or revoked.
+func (r *APIKeyRepository) UseAPIKey(keyHash, ipAddress string) (*model.APIKey, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	result, err := r.queries.GetAPIKeyByHash(ctx, keyHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAPIKeyInvalid
+		}
+		return nil, err
+	}
+	if result.ApiKey.RevokedAt.Valid || (result.ApiKey.ExpiresAt.Valid && now.UnixMilli() >= result.ApiKey.ExpiresAt.Int64) {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	err = r.queries.TouchAPIKey(ctx, repository.TouchAPIKeyParams{
+		ID:         result.ApiKey.ID,
+		LastUsedAt: utils.ToEpochMillis(now),
+		LastUsedIp: pgtype.Text{String: ipAddress, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	key := r.sqlcAPIKeyToModel(&result.ApiKey)
+	key.OwnerAccountType = result.AccountType
+	key.LastUsedAt = &now
+	key.LastUsedIP = &ipAddress
+	return key, nil
+}
+
+// recordChange writes an audit event about an API key in the transaction of qtx.
+// The key hash is left out; before is nil for creations and after for revocations.
+func (r *APIKeyRepository) recordChange(ctx context.Context, qtx *repository.Queries, origin *audit_model.Origin, action string, before, after *repository.ApiKey) error {
+	snapshot := func(key *repository.ApiKey) any {
+		if key == nil {
+			return nil
+		}
+		return map[string]any{
+			"user_id":    key.UserID,
+			"name":       key.Name,
+			"prefix":     key.Prefix,
+			"scopes":     key.Scopes,
+			"expires_at": utils.NullableFromEpochMillis(key.ExpiresAt),
+		}
+	}
+	key := after
+	if key == nil {
+		key = before
+	}
+	return audit_repository.Record(ctx, qtx, origin, action, audit_model.TargetAPIKey, strconv.FormatInt(int64(key.ID), 10), snapshot(before), snapshot(after))
+}
+
+// Helper method to convert sqlc ApiKey to model APIKey
+func (r *APIKeyRepository) sqlcAPIKeyToModel(sqlcKey *repository.ApiKey) *model.APIKey {
+	key := &model.APIKey{
+		ID:         sqlcKey.ID,
+		UserID:     sqlcKey.UserID,
+		Name:       sqlcKey.Name,
+		Prefix:     sqlcKey.Prefix,
+		Scopes:     sqlcKey.Scopes,
+		ExpiresAt:  utils.NullableFromEpochMillis(sqlcKey.ExpiresAt),
+		LastUsedAt: utils.NullableFromEpochMillis(sqlcKey.LastUsedAt),
+		CreatedAt:  utils.FromEpochMillis(sqlcKey.CreatedAt),
+	}
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+	if sqlcKey.LastUsedIp.Valid {
+		key.LastUsedIP = &sqlcKey.LastUsedIp.String
+	}
+	return key
+}
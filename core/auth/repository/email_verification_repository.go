@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrEmailVerificationTokenInvalid is returned for unknown, used, expired or outdated verification tokens
+var ErrEmailVerificationTokenInvalid = errors.New("email verification token is invalid")
+
+type EmailVerificationRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewEmailVerificationRepository(pool *pgxpool.Pool) *EmailVerificationRepository {
+	return &EmailVerificationRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// CreateEmailVerificationToken stores a token for the given address and invalidates earlier ones of the user
+func (r *EmailVerificationRepository) CreateEmailVerificationToken(userID int32, email, tokenHash string, expiresAt time.Time) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	err = qtx.InvalidateUserEmailVerificationTokens(ctx, repository.InvalidateUserEmailVerificationTokensParams{
+		UserID: userID,
+		UsedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = qtx.CreateEmailVerificationToken(ctx, repository.CreateEmailVerificationTokenParams{
+		UserID:    userID,
+		Email:     email,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt.UnixMilli(),
+		CreatedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// VerifyEmail consumes a verification token and marks the user's email as verified,
+// provided the user's email is still the address the token was sent to
+func (r *EmailVerificationRepository) VerifyEmail(tokenHash string) (*model.EmailVerificationToken, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	result, err := qtx.GetEmailVerificationTokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEmailVerificationTokenInvalid
+		}
+		return nil, err
+	}
+	if result.UsedAt.Valid || now.UnixMilli() >= result.ExpiresAt {
+		return nil, ErrEmailVerificationTokenInvalid
+	}
+
+	rows, err := qtx.ConsumeEmailVerificationToken(ctx, repository.ConsumeEmailVerificationTokenParams{
+		ID:     result.ID,
+		UsedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, ErrEmailVerificationTokenInvalid
+	}
+
+	rows, err = qtx.SetUserEmailVerified(ctx, repository.SetUserEmailVerifiedParams{
+		ID:              result.UserID,
+		EmailVerifiedAt: utils.ToEpochMillis(now),
+		Email:           pgtype.Text{String: result.Email, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		// The user changed their email after the token was sent
+		return nil, ErrEmailVerificationTokenInvalid
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &model.EmailVerificationToken{
+		ID:        result.ID,
+		UserID:    result.UserID,
+		Email:     result.Email,
+		ExpiresAt: time.UnixMilli(result.ExpiresAt),
+		CreatedAt: utils.FromEpochMillis(result.CreatedAt),
+	}, nil
+}
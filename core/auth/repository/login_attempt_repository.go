@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	audit_model "go-backend-valos-id/core/audit/model"
+	audit_repository "go-backend-valos-id/core/audit/repository"
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LoginAttemptRepository stores failed login counts, shared by every instance
+type LoginAttemptRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewLoginAttemptRepository(pool *pgxpool.Pool) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// GetLoginAttempt returns the failures recorded for subject, or nil if there are none
+func (r *LoginAttemptRepository) GetLoginAttempt(subject string) (*model.LoginAttempt, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetLoginAttempt(ctx, subject)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &model.LoginAttempt{
+		Subject:       result.Subject,
+		Failures:      int(result.Failures),
+		LastFailureAt: time.UnixMilli(result.LastFailureAt),
+		BlockedUntil:  utils.NullableFromEpochMillis(result.BlockedUntil),
+	}, nil
+}
+
+// RecordLoginFailure counts a failure of subject at failedAt and returns its failure count.
+// Counting starts over if the previous failure happened before resetBefore.
+func (r *LoginAttemptRepository) RecordLoginFailure(subject string, failedAt, resetBefore time.Time) (int, error) {
+	ctx := context.Background()
+
+	failures, err := r.queries.RecordLoginFailure(ctx, repository.RecordLoginFailureParams{
+		Subject:     subject,
+		FailedAt:    failedAt.UnixMilli(),
+		ResetBefore: resetBefore.UnixMilli(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(failures), nil
+}
+
+// BlockLoginSubject blocks subject until the given time; a block is only ever extended, never shortened
+func (r *LoginAttemptRepository) BlockLoginSubject(subject string, until time.Time) error {
+	ctx := context.Background()
+
+	return r.queries.BlockLoginSubject(ctx, repository.BlockLoginSubjectParams{
+		BlockedUntil: until.UnixMilli(),
+		Subject:      subject,
+	})
+}
+
+// DeleteLoginAttempt forgets the failures of subject, lifting any block
+func (r *LoginAttemptRepository) DeleteLoginAttempt(subject string) error {
+	ctx := context.Background()
+
+	return r.queries.DeleteLoginAttempt(ctx, subject)
+}
+
+// UnlockLoginSubject forgets the failures of subject on request of origin, recording it in the audit log
+// if subject had any
+func (r *LoginAttemptRepository) UnlockLoginSubject(subject string, origin *audit_model.Origin) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	result, err := qtx.GetLoginAttempt(ctx, subject)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if err := qtx.DeleteLoginAttempt(ctx, subject); err != nil {
+		return err
+	}
+
+	before := &model.LoginAttempt{
+		Subject:       result.Subject,
+		Failures:      int(result.Failures),
+		LastFailureAt: time.UnixMilli(result.LastFailureAt),
+		BlockedUntil:  utils.NullableFromEpochMillis(result.BlockedUntil),
+	}
+	if err := audit_repository.Record(ctx, qtx, origin, audit_model.ActionLockoutLifted, audit_model.TargetLockout, subject, before, nil); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteStaleLoginAttempts removes subjects that last failed before resetBefore and are no longer blocked at now
+func (r *LoginAttemptRepository) DeleteStaleLoginAttempts(resetBefore, now time.Time) (int64, error) {
+	ctx := context.Background()
+
+	return r.queries.DeleteStaleLoginAttempts(ctx, repository.DeleteStaleLoginAttemptsParams{
+		ResetBefore: resetBefore.UnixMilli(),
+		Now:         now.UnixMilli(),
+	})
+}
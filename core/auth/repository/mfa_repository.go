@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrTOTPNotFound is returned when the user has no TOTP enrollment in the expected state
+var ErrTOTPNotFound = errors.New("TOTP enrollment not found")
+
+type MFARepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewMFARepository(pool *pgxpool.Pool) *MFARepository {
+	return &MFARepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// SaveTOTPEnrollment stores a new unconfirmed TOTP secret, replacing any earlier enrollment
+func (r *MFARepository) SaveTOTPEnrollment(userID int32, secretEncrypted string) error {
+	ctx := context.Background()
+
+	return r.queries.UpsertUserTOTP(ctx, repository.UpsertUserTOTPParams{
+		UserID:          userID,
+		SecretEncrypted: secretEncrypted,
+		CreatedAt:       utils.ToEpochMillis(time.Now()),
+	})
+}
+
+func (r *MFARepository) GetTOTP(userID int32) (*model.TOTP, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetUserTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTOTPNotFound
+		}
+		return nil, err
+	}
+
+	return &model.TOTP{
+		UserID:          result.UserID,
+		SecretEncrypted: result.SecretEncrypted,
+		ConfirmedAt:     utils.NullableFromEpochMillis(result.ConfirmedAt),
+		LastUsedStep:    result.LastUsedStep,
+		CreatedAt:       utils.FromEpochMillis(result.CreatedAt),
+	}, nil
+}
+
+// ConfirmTOTP enables a pending enrollment and replaces the user's recovery codes in one transaction
+func (r *MFARepository) ConfirmTOTP(userID int32, step int64, recoveryCodeHashes []string) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	rows, err := qtx.ConfirmUserTOTP(ctx, repository.ConfirmUserTOTPParams{
+		UserID:       userID,
+		ConfirmedAt:  utils.ToEpochMillis(now),
+		LastUsedStep: step,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrTOTPNotFound
+	}
+
+	if err := qtx.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+		return err
+	}
+
+	for _, codeHash := range recoveryCodeHashes {
+		err := qtx.CreateRecoveryCode(ctx, repository.CreateRecoveryCodeParams{
+			UserID:    userID,
+			CodeHash:  codeHash,
+			CreatedAt: utils.ToEpochMillis(now),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseTOTPStep records step as used. It reports false if the step, or a later one, was already used.
+func (r *MFARepository) UseTOTPStep(userID int32, step int64) (bool, error) {
+	ctx := context.Background()
+
+	rows, err := r.queries.UseUserTOTPStep(ctx, repository.UseUserTOTPStepParams{
+		UserID:       userID,
+		LastUsedStep: step,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ConsumeRecoveryCode marks a recovery code as used. It reports false if the code is unknown or used.
+func (r *MFARepository) ConsumeRecoveryCode(userID int32, codeHash string) (bool, error) {
+	ctx := context.Background()
+
+	rows, err := r.queries.ConsumeRecoveryCode(ctx, repository.ConsumeRecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: codeHash,
+		UsedAt:   utils.ToEpochMillis(time.Now()),
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *MFARepository) CountRecoveryCodes(userID int32) (int64, error) {
+	ctx := context.Background()
+	return r.queries.CountUnusedRecoveryCodes(ctx, userID)
+}
+
+// DeleteTOTP removes the user's TOTP enrollment and recovery codes
+func (r *MFARepository) DeleteTOTP(userID int32) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	if err := qtx.DeleteUserTOTP(ctx, userID); err != nil {
+		return err
+	}
+	if err := qtx.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	audit_model "go-backend-valos-id/core/audit/model"
+	audit_repository "go-backend-valos-id/core/audit/repository"
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrPasswordResetTokenInvalid is returned for unknown, used or expired reset tokens
+var ErrPasswordResetTokenInvalid = errors.New("password reset token is invalid")
+
+type PasswordResetRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewPasswordResetRepository(pool *pgxpool.Pool) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// CreatePasswordResetToken stores a new reset token and invalidates any earlier ones of the user
+func (r *PasswordResetRepository) CreatePasswordResetToken(userID int32, tokenHash string, expiresAt time.Time) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	err = qtx.InvalidateUserPasswordResetTokens(ctx, repository.InvalidateUserPasswordResetTokensParams{
+		UserID: userID,
+		UsedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = qtx.CreatePasswordResetToken(ctx, repository.CreatePasswordResetTokenParams{
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt.UnixMilli(),
+		CreatedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetPasswordResetToken returns a reset token that is still usable
+func (r *PasswordResetRepository) GetPasswordResetToken(tokenHash string) (*model.PasswordResetToken, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetPasswordResetTokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPasswordResetTokenInvalid
+		}
+		return nil, err
+	}
+
+	if result.UsedAt.Valid || time.Now().UnixMilli() >= result.ExpiresAt {
+		return nil, ErrPasswordResetTokenInvalid
+	}
+
+	return &model.PasswordResetToken{
+		ID:        result.ID,
+		UserID:    result.UserID,
+		ExpiresAt: time.UnixMilli(result.ExpiresAt),
+		CreatedAt: utils.FromEpochMillis(result.CreatedAt),
+	}, nil
+}
+
+// ResetPassword consumes the reset token, stores the new password hash, revokes every session of the user
+// and records the reset in the audit log in one transaction. It returns the new credential version and the
+// revoked session IDs.
+func (r *PasswordResetRepository) ResetPassword(reset *model.PasswordResetToken, hashedPassword string, origin *audit_model.Origin) (int32, []string, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	rows, err := qtx.ConsumePasswordResetToken(ctx, repository.ConsumePasswordResetTokenParams{
+		ID:     reset.ID,
+		UsedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	if rows == 0 {
+		return 0, nil, ErrPasswordResetTokenInvalid
+	}
+
+	credentialVersion, err := qtx.UpdatePassword(ctx, repository.UpdatePasswordParams{
+		ID:        reset.UserID,
+		Password:  pgtype.Text{String: hashedPassword, Valid: true},
+		UpdatedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	sessionIDs, err := qtx.RevokeUserSessions(ctx, repository.RevokeUserSessionsParams{
+		UserID:    reset.UserID,
+		RevokedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	err = qtx.RevokeUserRefreshTokens(ctx, repository.RevokeUserRefreshTokensParams{
+		UserID:    reset.UserID,
+		RevokedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// The password itself is never recorded, only the credential version it moved the user to
+	before := map[string]any{"credential_version": credentialVersion - 1}
+	after := map[string]any{"credential_version": credentialVersion}
+	err = audit_repository.Record(ctx, qtx, origin, audit_model.ActionUserPasswordReset, audit_model.TargetUser, strconv.FormatInt(int64(reset.UserID), 10), before, after)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, nil, err
+	}
+	return credentialVersion, sessionIDs, nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrRefreshTokenInvalid is returned for unknown, expired or revoked refresh tokens
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again
+	ErrRefreshTokenReused = errors.New("refresh token was reused")
+)
+
+type RefreshTokenRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewRefreshTokenRepository(pool *pgxpool.Pool) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// CreateRefreshToken stores the hash of a newly issued refresh token
+func (r *RefreshTokenRepository) CreateRefreshToken(userID int32, tokenHash, familyID string, expiresAt time.Time) (*model.RefreshToken, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.CreateRefreshToken(ctx, repository.CreateRefreshTokenParams{
+		UserID:    userID,
+		TokenHash: tokenHash,
+		FamilyID:  familyID,
+		ExpiresAt: expiresAt.UnixMilli(),
+		CreatedAt: utils.ToEpochMillis(time.Now()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.sqlcRefreshTokenToModel(&result), nil
+}
+
+// GetRefreshToken retrieves a refresh token by its hash, whether or not it is still usable.
+// It returns ErrRefreshTokenInvalid for unknown tokens.
+func (r *RefreshTokenRepository) GetRefreshToken(tokenHash string) (*model.RefreshToken, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetRefreshTokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	return r.sqlcRefreshTokenToModel(&result), nil
+}
+
+// RotateRefreshToken consumes the refresh token identified by tokenHash and stores its successor
+// in the same family. Presenting a token that was already consumed revokes the whole family.
+func (r *RefreshTokenRepository) RotateRefreshToken(tokenHash, newTokenHash string, newExpiresAt time.Time) (*model.RefreshToken, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	current, err := qtx.GetRefreshTokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	if current.RevokedAt.Valid {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if current.UsedAt.Valid {
+		return nil, r.revokeFamily(ctx, tx, qtx, current.FamilyID, now)
+	}
+	if now.UnixMilli() >= current.ExpiresAt {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	rows, err := qtx.MarkRefreshTokenUsed(ctx, repository.MarkRefreshTokenUsedParams{
+		ID:     current.ID,
+		UsedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		// A concurrent request rotated the same token first
+		return nil, r.revokeFamily(ctx, tx, qtx, current.FamilyID, now)
+	}
+
+	next, err := qtx.CreateRefreshToken(ctx, repository.CreateRefreshTokenParams{
+		UserID:    current.UserID,
+		TokenHash: newTokenHash,
+		FamilyID:  current.FamilyID,
+		ExpiresAt: newExpiresAt.UnixMilli(),
+		CreatedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.sqlcRefreshTokenToModel(&next), nil
+}
+
+// revokeFamily commits the revocation of a family and its session and reports the reuse
+func (r *RefreshTokenRepository) revokeFamily(ctx context.Context, tx pgx.Tx, qtx *repository.Queries, familyID string, now time.Time) error {
+	err := qtx.RevokeRefreshTokenFamily(ctx, repository.RevokeRefreshTokenFamilyParams{
+		FamilyID:  familyID,
+		RevokedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = qtx.RevokeSessionByID(ctx, repository.RevokeSessionByIDParams{
+		ID:        familyID,
+		RevokedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Helper method to convert sqlc RefreshToken to model RefreshToken
+func (r *RefreshTokenRepository) sqlcRefreshTokenToModel(sqlcToken *repository.RefreshToken) *model.RefreshToken {
+	return &model.RefreshToken{
+		ID:        sqlcToken.ID,
+		UserID:    sqlcToken.UserID,
+		FamilyID:  sqlcToken.FamilyID,
+		UsedAt:    utils.NullableFromEpochMillis(sqlcToken.UsedAt),
+		ExpiresAt: time.UnixMilli(sqlcToken.ExpiresAt),
+		CreatedAt: utils.FromEpochMillis(sqlcToken.CreatedAt),
+	}
+}
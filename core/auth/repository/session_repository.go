@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrSessionInactive is returned when touching a session that is revoked or unknown
+var ErrSessionInactive = errors.New("session is not active")
+
+type SessionRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewSessionRepository(pool *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// CreateSession records a new login session
+func (r *SessionRepository) CreateSession(session *model.Session) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	result, err := r.queries.CreateSession(ctx, repository.CreateSessionParams{
+		ID:         session.ID,
+		UserID:     session.UserID,
+		UserAgent:  session.UserAgent,
+		IpAddress:  session.IPAddress,
+		LastSeenAt: now.UnixMilli(),
+		CreatedAt:  utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return err
+	}
+
+	*session = *r.sqlcSessionToModel(&result)
+	return nil
+}
+
+// ListActiveSessions returns the sessions of a user that have not been revoked
+func (r *SessionRepository) ListActiveSessions(userID int32) ([]model.Session, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListActiveSessionsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]model.Session, len(results))
+	for i, result := range results {
+		sessions[i] = *r.sqlcSessionToModel(&result)
+	}
+
+	return sessions, nil
+}
+
+// TouchSession records activity on a session and returns it, or ErrSessionInactive if it is revoked or unknown
+func (r *SessionRepository) TouchSession(sessionID, userAgent, ipAddress string) (*model.Session, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.TouchSession(ctx, repository.TouchSessionParams{
+		ID:         sessionID,
+		LastSeenAt: time.Now().UnixMilli(),
+		UserAgent:  userAgent,
+		IpAddress:  ipAddress,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionInactive
+		}
+		return nil, err
+	}
+
+	return r.sqlcSessionToModel(&result), nil
+}
+
+// SetSessionOrganization sets the organization an active session of the user acts within;
+// nil leaves any organization. It returns false if the session is not an active session of the user.
+func (r *SessionRepository) SetSessionOrganization(sessionID string, userID int32, organizationID *int32) (bool, error) {
+	ctx := context.Background()
+
+	var orgID pgtype.Int4
+	if organizationID != nil {
+		orgID = pgtype.Int4{Int32: *organizationID, Valid: true}
+	}
+
+	rows, err := r.queries.SetSessionOrganization(ctx, repository.SetSessionOrganizationParams{
+		ID:             sessionID,
+		UserID:         userID,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// IsSessionActive reports whether a session exists and has not been revoked
+func (r *SessionRepository) IsSessionActive(sessionID string) (bool, error) {
+	ctx := context.Background()
+
+	return r.queries.IsSessionActive(ctx, sessionID)
+}
+
+// RevokeSession revokes one session of a user along with its refresh tokens.
+// It returns false if the session does not belong to the user or is already revoked.
+func (r *SessionRepository) RevokeSession(userID int32, sessionID string) (bool, error) {
+	ctx := context.Background()
+	revokedAt := utils.ToEpochMillis(time.Now())
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	rows, err := qtx.RevokeSession(ctx, repository.RevokeSessionParams{
+		ID:        sessionID,
+		UserID:    userID,
+		RevokedAt: revokedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	err = qtx.RevokeRefreshTokenFamily(ctx, repository.RevokeRefreshTokenFamilyParams{
+		FamilyID:  sessionID,
+		RevokedAt: revokedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RevokeAllSessions revokes every session and refresh token of a user and returns the revoked session IDs
+func (r *SessionRepository) RevokeAllSessions(userID int32) ([]string, error) {
+	ctx := context.Background()
+	revokedAt := utils.ToEpochMillis(time.Now())
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	sessionIDs, err := qtx.RevokeUserSessions(ctx, repository.RevokeUserSessionsParams{
+		UserID:    userID,
+		RevokedAt: revokedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = qtx.RevokeUserRefreshTokens(ctx, repository.RevokeUserRefreshTokensParams{
+		UserID:    userID,
+		RevokedAt: revokedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return sessionIDs, nil
+}
+
+// RevokeOtherSessions revokes every session and refresh token of a user except the given session
+// and returns the revoked session IDs
+func (r *SessionRepository) RevokeOtherSessions(userID int32, keepSessionID string) ([]string, error) {
+	ctx := context.Background()
+	revokedAt := utils.ToEpochMillis(time.Now())
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	sessionIDs, err := qtx.RevokeOtherUserSessions(ctx, repository.RevokeOtherUserSessionsParams{
+		UserID:    userID,
+		ID:        keepSessionID,
+		RevokedAt: revokedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = qtx.RevokeOtherUserRefreshTokens(ctx, repository.RevokeOtherUserRefreshTokensParams{
+		UserID:    userID,
+		FamilyID:  keepSessionID,
+		RevokedAt: revokedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return sessionIDs, nil
+}
+
+// Helper method to convert sqlc Session to model Session
+func (r *SessionRepository) sqlcSessionToModel(sqlcSession *repository.Session) *model.Session {
+	session := &model.Session{
+		ID:         sqlcSession.ID,
+		UserID:     sqlcSession.UserID,
+		UserAgent:  sqlcSession.UserAgent,
+		IPAddress:  sqlcSession.IpAddress,
+		LastSeenAt: time.UnixMilli(sqlcSession.LastSeenAt),
+		CreatedAt:  utils.FromEpochMillis(sqlcSession.CreatedAt),
+	}
+	if sqlcSession.OrganizationID.Valid {
+		session.OrganizationID = &sqlcSession.OrganizationID.Int32
+	}
+	return session
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	audit_model "go-backend-valos-id/core/audit/model"
+	audit_repository "go-backend-valos-id/core/audit/repository"
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrActiveKeyChanged is returned when another rotation replaced the active signing key first
+var ErrActiveKeyChanged = errors.New("active signing key changed")
+
+type SigningKeyRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewSigningKeyRepository(pool *pgxpool.Pool) *SigningKeyRepository {
+	return &SigningKeyRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// ListPublishedKeys returns the active key and every retired key that has not expired yet, newest first
+func (r *SigningKeyRepository) ListPublishedKeys() ([]*model.SigningKey, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListPublishedSigningKeys(ctx, utils.ToEpochMillis(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*model.SigningKey, len(results))
+	for i := range results {
+		keys[i] = r.sqlcSigningKeyToModel(&results[i])
+	}
+	return keys, nil
+}
+
+// RotateKey activates key and retires the current active key, which stays published until retiredUntil.
+// The rotation only happens while the active key is still previousKID (empty for none);
+// otherwise ErrActiveKeyChanged is returned, so concurrent rotations on several instances create one key.
+// Rotations are recorded in the audit log without an actor, being run by the server or the keys command.
+func (r *SigningKeyRepository) RotateKey(key *model.SigningKey, previousKID string, retiredUntil time.Time) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	active, err := qtx.GetActiveSigningKeyForUpdate(ctx)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		if previousKID != "" {
+			return ErrActiveKeyChanged
+		}
+	case err != nil:
+		return err
+	default:
+		if active.Kid != previousKID {
+			return ErrActiveKeyChanged
+		}
+		err := qtx.RetireSigningKey(ctx, repository.RetireSigningKeyParams{
+			ID:        active.ID,
+			RetiredAt: utils.ToEpochMillis(now),
+			ExpiresAt: utils.ToEpochMillis(retiredUntil),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := qtx.CreateSigningKey(ctx, repository.CreateSigningKeyParams{
+		Kid:                 key.KeyID,
+		Algorithm:           key.Algorithm,
+		PrivateKeyEncrypted: key.PrivateKeyEncrypted,
+		PublicKey:           key.PublicKey,
+		ActivatedAt:         now.UnixMilli(),
+		CreatedAt:           utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		// Only one key may be active; losing that race means another instance rotated
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return ErrActiveKeyChanged
+		}
+		return err
+	}
+
+	var before any
+	if previousKID != "" {
+		before = map[string]any{"kid": previousKID}
+	}
+	after := map[string]any{"kid": result.Kid, "algorithm": result.Algorithm}
+	if err := audit_repository.Record(ctx, qtx, nil, audit_model.ActionSigningKeyRotated, audit_model.TargetSigningKey, result.Kid, before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	*key = *r.sqlcSigningKeyToModel(&result)
+	return nil
+}
+
+// DeleteExpiredKeys removes retired keys that are no longer published
+func (r *SigningKeyRepository) DeleteExpiredKeys() (int64, error) {
+	ctx := context.Background()
+
+	return r.queries.DeleteExpiredSigningKeys(ctx, utils.ToEpochMillis(time.Now()))
+}
+
+// Helper method to convert sqlc SigningKey to model SigningKey
+func (r *SigningKeyRepository) sqlcSigningKeyToModel(sqlcKey *repository.SigningKey) *model.SigningKey {
+	return &model.SigningKey{
+		ID:                  sqlcKey.ID,
+		KeyID:               sqlcKey.Kid,
+		Algorithm:           sqlcKey.Algorithm,
+		PrivateKeyEncrypted: sqlcKey.PrivateKeyEncrypted,
+		PublicKey:           sqlcKey.PublicKey,
+		Status:              sqlcKey.Status,
+		ActivatedAt:         time.UnixMilli(sqlcKey.ActivatedAt),
+		RetiredAt:           utils.NullableFromEpochMillis(sqlcKey.RetiredAt),
+		ExpiresAt:           utils.NullableFromEpochMillis(sqlcKey.ExpiresAt),
+		CreatedAt:           utils.FromEpochMillis(sqlcKey.CreatedAt),
+	}
+}
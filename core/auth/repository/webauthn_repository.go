@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go-backend-valos-id/core/auth/model"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrWebAuthnChallengeInvalid is returned for unknown, used or expired ceremony challenges
+	ErrWebAuthnChallengeInvalid   = errors.New("WebAuthn challenge is invalid")
+	ErrWebAuthnCredentialExists   = errors.New("WebAuthn credential is already registered")
+	ErrWebAuthnCredentialNotFound = errors.New("WebAuthn credential not found")
+)
+
+type WebAuthnRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewWebAuthnRepository(pool *pgxpool.Pool) *WebAuthnRepository {
+	return &WebAuthnRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// CreateChallenge stores a ceremony challenge and clears out expired ones
+func (r *WebAuthnRepository) CreateChallenge(challenge *model.WebAuthnChallenge) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := r.queries.DeleteExpiredWebAuthnChallenges(ctx, now.UnixMilli()); err != nil {
+		return err
+	}
+
+	var userID pgtype.Int4
+	if challenge.UserID != nil {
+		userID = pgtype.Int4{Int32: *challenge.UserID, Valid: true}
+	}
+
+	return r.queries.CreateWebAuthnChallenge(ctx, repository.CreateWebAuthnChallengeParams{
+		ID:        challenge.ID,
+		UserID:    userID,
+		Ceremony:  challenge.Ceremony,
+		Challenge: challenge.Challenge,
+		ExpiresAt: challenge.ExpiresAt.UnixMilli(),
+		CreatedAt: utils.ToEpochMillis(now),
+	})
+}
+
+// ConsumeChallenge removes and returns a challenge so it can only be answered once
+func (r *WebAuthnRepository) ConsumeChallenge(id, ceremony string) (*model.WebAuthnChallenge, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.ConsumeWebAuthnChallenge(ctx, repository.ConsumeWebAuthnChallengeParams{
+		ID:       id,
+		Ceremony: ceremony,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWebAuthnChallengeInvalid
+		}
+		return nil, err
+	}
+
+	if time.Now().UnixMilli() >= result.ExpiresAt {
+		return nil, ErrWebAuthnChallengeInvalid
+	}
+
+	challenge := &model.WebAuthnChallenge{
+		ID:        result.ID,
+		Ceremony:  result.Ceremony,
+		Challenge: result.Challenge,
+		ExpiresAt: time.UnixMilli(result.ExpiresAt),
+	}
+	if result.UserID.Valid {
+		challenge.UserID = &result.UserID.Int32
+	}
+	return challenge, nil
+}
+
+func (r *WebAuthnRepository) CreateCredential(credential *model.WebAuthnCredential) (*model.WebAuthnCredential, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.CreateWebAuthnCredential(ctx, repository.CreateWebAuthnCredentialParams{
+		UserID:       credential.UserID,
+		CredentialID: credential.CredentialID,
+		PublicKey:    credential.PublicKey,
+		SignCount:    int64(credential.SignCount),
+		Aaguid:       credential.AAGUID,
+		Transports:   strings.Join(credential.Transports, ","),
+		Name:         credential.Name,
+		CreatedAt:    utils.ToEpochMillis(time.Now()),
+	})
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return nil, ErrWebAuthnCredentialExists
+		}
+		return nil, err
+	}
+
+	return toWebAuthnCredential(result), nil
+}
+
+func (r *WebAuthnRepository) ListCredentials(userID int32) ([]*model.WebAuthnCredential, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListWebAuthnCredentialsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]*model.WebAuthnCredential, len(results))
+	for i, result := range results {
+		credentials[i] = toWebAuthnCredential(result)
+	}
+	return credentials, nil
+}
+
+func (r *WebAuthnRepository) GetCredentialByCredentialID(credentialID []byte) (*model.WebAuthnCredential, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetWebAuthnCredentialByCredentialID(ctx, credentialID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWebAuthnCredentialNotFound
+		}
+		return nil, err
+	}
+
+	return toWebAuthnCredential(result), nil
+}
+
+// UpdateSignCount stores the counter of a successful assertion.
+// It reports false if another login with the same credential stored a counter first.
+func (r *WebAuthnRepository) UpdateSignCount(credential *model.WebAuthnCredential, signCount uint32) (bool, error) {
+	ctx := context.Background()
+
+	rows, err := r.queries.UpdateWebAuthnCredentialSignCount(ctx, repository.UpdateWebAuthnCredentialSignCountParams{
+		NewSignCount: int64(signCount),
+		LastUsedAt:   utils.ToEpochMillis(time.Now()),
+		ID:           credential.ID,
+		OldSignCount: int64(credential.SignCount),
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// DeleteCredential removes one of the user's credentials. It reports false if no such credential exists.
+func (r *WebAuthnRepository) DeleteCredential(id, userID int32) (bool, error) {
+	ctx := context.Background()
+
+	rows, err := r.queries.DeleteWebAuthnCredential(ctx, repository.DeleteWebAuthnCredentialParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func toWebAuthnCredential(result repository.WebauthnCredential) *model.WebAuthnCredential {
+	var transports []string
+	if result.Transports != "" {
+		transports = strings.Split(result.Transports, ",")
+	}
+
+	return &model.WebAuthnCredential{
+		ID:           result.ID,
+		UserID:       result.UserID,
+		CredentialID: result.CredentialID,
+		PublicKey:    result.PublicKey,
+		SignCount:    uint32(result.SignCount),
+		AAGUID:       result.Aaguid,
+		Transports:   transports,
+		Name:         result.Name,
+		LastUsedAt:   utils.NullableFromEpochMillis(result.LastUsedAt),
+		CreatedAt:    utils.FromEpochMillis(result.CreatedAt),
+	}
+}
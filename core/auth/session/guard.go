@@ -0,0 +1,153 @@
+package session
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/auth/token"
+	org_repository "go-backend-valos-id/core/organization/repository"
+	user_repository "go-backend-valos-id/core/user/repository"
+	"go-backend-valos-id/core/utils"
+)
+
+// deletedUser is cached as the credential version of users that no longer exist
+const deletedUser int32 = -1
+
+// membership identifies a user's membership in an organization
+type membership struct {
+	organizationID int32
+	userID         int32
+}
+
+// Guard rejects access tokens whose session has been revoked, whose user's credentials changed
+// after the token was issued, or whose user left the organization the token acts within. State is cached in-process so most requests do not hit the database;
+// changes made through this instance take effect immediately, others within the cache TTL.
+type Guard struct {
+	sessionRepo        *repository.SessionRepository
+	userRepo           *user_repository.UserRepository
+	orgRepo            *org_repository.OrganizationRepository
+	sessions           *utils.TTLCache[string, bool]
+	credentialVersions *utils.TTLCache[int32, int32]
+	memberships        *utils.TTLCache[membership, bool]
+}
+
+func NewGuard(
+	sessionRepo *repository.SessionRepository,
+	userRepo *user_repository.UserRepository,
+	orgRepo *org_repository.OrganizationRepository,
+	cacheTTL time.Duration,
+) *Guard {
+	return &Guard{
+		sessionRepo:        sessionRepo,
+		userRepo:           userRepo,
+		orgRepo:            orgRepo,
+		sessions:           utils.NewTTLCache[string, bool](cacheTTL),
+		credentialVersions: utils.NewTTLCache[int32, int32](cacheTTL),
+		memberships:        utils.NewTTLCache[membership, bool](cacheTTL),
+	}
+}
+
+// CheckClaims returns token.ErrTokenRevoked if the token's session is no longer active,
+// the token predates the user's current credentials or the user left the token's organization
+func (g *Guard) CheckClaims(claims *token.Claims) error {
+	if err := g.checkSession(claims.SessionID); err != nil {
+		return err
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 32)
+	if err != nil {
+		return token.ErrTokenRevoked
+	}
+	if err := g.checkCredentialVersion(int32(userID), claims.CredentialVersion); err != nil {
+		return err
+	}
+
+	if claims.OrganizationID == 0 {
+		return nil
+	}
+	return g.checkMembership(claims.OrganizationID, int32(userID))
+}
+
+// Revoked marks sessions as revoked in the cache
+func (g *Guard) Revoked(sessionIDs ...string) {
+	for _, id := range sessionIDs {
+		g.sessions.Set(id, false)
+	}
+}
+
+// UserDeleted records in the cache that a user no longer exists
+func (g *Guard) UserDeleted(userID int32) {
+	g.credentialVersions.Set(userID, deletedUser)
+}
+
+// CredentialsChanged records a user's new credential version in the cache
+func (g *Guard) CredentialsChanged(userID, credentialVersion int32) {
+	g.credentialVersions.Set(userID, credentialVersion)
+}
+
+// MembershipChanged drops a user's cached membership in an organization
+func (g *Guard) MembershipChanged(organizationID, userID int32) {
+	g.memberships.Delete(membership{organizationID: organizationID, userID: userID})
+}
+
+func (g *Guard) checkSession(sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+
+	active, ok := g.sessions.Get(sessionID)
+	if !ok {
+		var err error
+		active, err = g.sessionRepo.IsSessionActive(sessionID)
+		if err != nil {
+			return err
+		}
+		g.sessions.Set(sessionID, active)
+	}
+
+	if !active {
+		return token.ErrTokenRevoked
+	}
+	return nil
+}
+
+func (g *Guard) checkCredentialVersion(userID, tokenVersion int32) error {
+	current, ok := g.credentialVersions.Get(userID)
+	if !ok {
+		var err error
+		current, err = g.userRepo.GetCredentialVersion(userID)
+		if err == sql.ErrNoRows {
+			current = deletedUser
+		} else if err != nil {
+			return err
+		}
+		g.credentialVersions.Set(userID, current)
+	}
+
+	// Deleted users keep no valid credentials
+	if current == deletedUser || tokenVersion != current {
+		return token.ErrTokenRevoked
+	}
+	return nil
+}
+
+func (g *Guard) checkMembership(organizationID, userID int32) error {
+	key := membership{organizationID: organizationID, userID: userID}
+
+	member, ok := g.memberships.Get(key)
+	if !ok {
+		var err error
+		member, err = g.orgRepo.IsMember(organizationID, userID)
+		if err != nil {
+			return err
+		}
+		g.memberships.Set(key, member)
+	}
+
+	if !member {
+		return token.ErrTokenRevoked
+	}
+	return nil
+}
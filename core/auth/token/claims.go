@@ -0,0 +1,86 @@
+package token
+
+import (
+	"encoding/json"
+)
+
+// Claims holds the JWT claims issued by this service
+type Claims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+	// SessionID identifies the login session the token was issued for,
+	// or on tokens issued to OAuth clients the authorization grant
+	SessionID string `json:"sid,omitempty"`
+	// OrganizationID is the organization the session acts within; access tokens outside one leave it zero
+	OrganizationID int32 `json:"org,omitempty"`
+	// CredentialVersion is the user's credential version when the token was issued
+	CredentialVersion int32 `json:"cv,omitempty"`
+	// AccountType is "service" on tokens acting for a service account and empty otherwise
+	AccountType string `json:"acct,omitempty"`
+	// Purpose marks special-purpose tokens such as MFA challenges; access tokens leave it empty
+	Purpose string `json:"purpose,omitempty"`
+	// ClientID and Scope are set on tokens issued to OAuth clients (RFC 9068)
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+}
+
+// IDClaims holds the claims of an OpenID Connect ID token
+type IDClaims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  Audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	IssuedAt  int64    `json:"iat"`
+	Nonce     string   `json:"nonce,omitempty"`
+	// AuthorizedParty is the client the ID token was issued to
+	AuthorizedParty string `json:"azp,omitempty"`
+	ProfileClaims
+}
+
+// ProfileClaims holds the standard OpenID Connect claims about a user.
+// Which of them are filled in depends on the scopes granted to the client.
+type ProfileClaims struct {
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+}
+
+// Audience is serialized as a single string when it has one entry, as allowed by RFC 7519
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// Contains reports whether aud is one of the audiences
+func (a Audience) Contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+)
+
+// JWK is the public part of a signing key as a JSON Web Key (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	// N and E are set for RSA keys
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Curve and X are set for EC and OKP keys (RFC 8037), Y only for EC keys
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set as served at the jwks_uri
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK converts the public part of key to a JWK. It reports false for keys that
+// cannot be published, such as HMAC secrets.
+func NewJWK(key *Key) (JWK, bool) {
+	jwk := JWK{
+		Use:       "sig",
+		Algorithm: key.Algorithm,
+		KeyID:     key.ID,
+	}
+
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encodeSegment(pub.N.Bytes())
+		jwk.E = encodeSegment(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		point, err := pub.Bytes()
+		if err != nil || len(point) != 1+2*es256CoordinateSize {
+			return JWK{}, false
+		}
+		jwk.KeyType = "EC"
+		jwk.Curve = "P-256"
+		jwk.X = encodeSegment(point[1 : 1+es256CoordinateSize])
+		jwk.Y = encodeSegment(point[1+es256CoordinateSize:])
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = encodeSegment(pub)
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of the public part of key.
+// It reports false for keys that cannot be published.
+func Thumbprint(key *Key) (string, bool) {
+	jwk, ok := NewJWK(key)
+	if !ok {
+		return "", false
+	}
+
+	// Only the required members, in lexicographic order (RFC 7638 §3.2)
+	var members any
+	switch jwk.KeyType {
+	case "RSA":
+		members = struct {
+			E       string `json:"e"`
+			KeyType string `json:"kty"`
+			N       string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N}
+	case "EC":
+		members = struct {
+			Curve   string `json:"crv"`
+			KeyType string `json:"kty"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+		}{jwk.Curve, jwk.KeyType, jwk.X, jwk.Y}
+	default:
+		members = struct {
+			Curve   string `json:"crv"`
+			KeyType string `json:"kty"`
+			X       string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return encodeSegment(sum[:]), true
+}
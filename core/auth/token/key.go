@@ -0,0 +1,186 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"go-backend-valos-id/core/config"
+	"go-backend-valos-id/core/utils"
+)
+
+// Supported signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// Key is a signing or verification key together with its algorithm
+type Key struct {
+	ID        string
+	Algorithm string
+	// Secret is only set for HMAC keys
+	Secret []byte
+	// Private is nil for verification-only keys
+	Private crypto.Signer
+	Public  crypto.PublicKey
+}
+
+// KeyProvider supplies the key used to sign new tokens and resolves keys for verification
+type KeyProvider interface {
+	SigningKey() (*Key, error)
+	VerificationKey(kid string) (*Key, error)
+	// VerificationKeys lists every key tokens may currently be verified with
+	VerificationKeys() ([]*Key, error)
+}
+
+// StaticKeyProvider serves a single key loaded at startup
+type StaticKeyProvider struct {
+	key *Key
+}
+
+func NewStaticKeyProvider(key *Key) *StaticKeyProvider {
+	return &StaticKeyProvider{key: key}
+}
+
+func (p *StaticKeyProvider) SigningKey() (*Key, error) {
+	return p.key, nil
+}
+
+func (p *StaticKeyProvider) VerificationKey(kid string) (*Key, error) {
+	if kid != "" && kid != p.key.ID {
+		return nil, ErrUnknownKey
+	}
+	return p.key, nil
+}
+
+func (p *StaticKeyProvider) VerificationKeys() ([]*Key, error) {
+	return []*Key{p.key}, nil
+}
+
+// NewKeyProviderFromConfig builds a StaticKeyProvider from the JWT settings in cfg
+func NewKeyProviderFromConfig(cfg *config.AuthConfig) (*StaticKeyProvider, error) {
+	algorithm := cfg.JWTAlgorithm
+	if algorithm == "" {
+		algorithm = AlgHS256
+	}
+
+	key := &Key{
+		ID:        cfg.JWTKeyID,
+		Algorithm: algorithm,
+	}
+
+	switch algorithm {
+	case AlgHS256:
+		secret := []byte(cfg.JWTSecret)
+		if len(secret) == 0 {
+			// Fall back to an ephemeral secret so local setups work without configuration
+			generated, err := utils.RandomHex(32)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate JWT secret: %w", err)
+			}
+			log.Println("JWT_SECRET is not set, using an ephemeral secret; tokens will not survive a restart")
+			secret = []byte(generated)
+		}
+		if len(secret) < 32 {
+			return nil, fmt.Errorf("JWT_SECRET must be at least 32 bytes for %s", AlgHS256)
+		}
+		key.Secret = secret
+	case AlgRS256, AlgES256, AlgEdDSA:
+		if cfg.JWTPrivateKeyFile == "" {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE is required for %s", algorithm)
+		}
+		signer, err := LoadPrivateKey(cfg.JWTPrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := CheckKeyType(algorithm, signer); err != nil {
+			return nil, err
+		}
+		key.Private = signer
+		key.Public = signer.Public()
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", algorithm)
+	}
+
+	// Tokens always carry a kid so verifiers can tell keys apart once keys are rotated
+	if key.ID == "" {
+		key.ID = deriveKeyID(key)
+	}
+
+	return NewStaticKeyProvider(key), nil
+}
+
+// deriveKeyID returns a stable key ID: the JWK thumbprint of asymmetric keys,
+// or a MAC of a fixed label for HMAC secrets so the ID reveals nothing about the secret
+func deriveKeyID(key *Key) string {
+	if thumbprint, ok := Thumbprint(key); ok {
+		return thumbprint
+	}
+	mac := hmac.New(sha256.New, key.Secret)
+	mac.Write([]byte("valos-id key id"))
+	return encodeSegment(mac.Sum(nil)[:16])
+}
+
+// LoadPrivateKey reads a PEM encoded PKCS#1, SEC 1 or PKCS#8 private key
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM private key in %s", path)
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+	return signer, nil
+}
+
+// CheckKeyType reports an error if signer cannot be used with the signing algorithm alg
+func CheckKeyType(alg string, signer crypto.Signer) error {
+	switch alg {
+	case AlgRS256:
+		if _, ok := signer.(*rsa.PrivateKey); !ok {
+			return fmt.Errorf("%s requires an RSA private key", alg)
+		}
+	case AlgES256:
+		if priv, ok := signer.(*ecdsa.PrivateKey); !ok || priv.Curve != elliptic.P256() {
+			return fmt.Errorf("%s requires a P-256 ECDSA private key", alg)
+		}
+	case AlgEdDSA:
+		if _, ok := signer.(ed25519.PrivateKey); !ok {
+			return fmt.Errorf("%s requires an Ed25519 private key", alg)
+		}
+	}
+	return nil
+}
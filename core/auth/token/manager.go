@@ -0,0 +1,304 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-backend-valos-id/core/config"
+	user_model "go-backend-valos-id/core/user/model"
+	"go-backend-valos-id/core/utils"
+)
+
+var (
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenNotYetValid = errors.New("token is not valid yet")
+	ErrInvalidIssuer    = errors.New("token has an invalid issuer")
+	ErrInvalidAudience  = errors.New("token has an invalid audience")
+	ErrInvalidPurpose   = errors.New("token has an invalid purpose")
+	ErrClientToken      = errors.New("token was issued to an OAuth client")
+	ErrNotClientToken   = errors.New("token was not issued to an OAuth client")
+	// ErrTokenRevoked is returned by checks that reject tokens which are otherwise valid
+	ErrTokenRevoked = errors.New("token has been revoked")
+)
+
+// PurposeMFAChallenge marks tokens that stand for a password check still awaiting its second factor
+const PurposeMFAChallenge = "mfa_challenge"
+
+// Manager issues and validates access tokens for authenticated users
+type Manager struct {
+	keys            KeyProvider
+	issuer          string
+	audience        Audience
+	accessTokenTTL  time.Duration
+	clockSkew       time.Duration
+	mfaChallengeTTL time.Duration
+}
+
+func NewManager(cfg *config.AuthConfig, keys KeyProvider) *Manager {
+	return &Manager{
+		keys:            keys,
+		issuer:          cfg.Issuer,
+		audience:        Audience(cfg.Audience),
+		accessTokenTTL:  cfg.AccessTokenTTL,
+		clockSkew:       cfg.ClockSkew,
+		mfaChallengeTTL: cfg.MFAChallengeTTL,
+	}
+}
+
+// AccessTokenTTL returns the lifetime of issued access tokens
+func (m *Manager) AccessTokenTTL() time.Duration {
+	return m.accessTokenTTL
+}
+
+// IssueAccessToken creates a signed access token for the given user and session.
+// organizationID is the organization the session acts within, or 0 for none.
+func (m *Manager) IssueAccessToken(userID int32, sessionID string, organizationID, credentialVersion int32) (string, *Claims, error) {
+	jti, err := utils.RandomHex(16)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token ID: %w", err)
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		Issuer:            m.issuer,
+		Subject:           strconv.Itoa(int(userID)),
+		Audience:          m.audience,
+		IssuedAt:          now.Unix(),
+		ExpiresAt:         now.Add(m.accessTokenTTL).Unix(),
+		ID:                jti,
+		SessionID:         sessionID,
+		OrganizationID:    organizationID,
+		CredentialVersion: credentialVersion,
+	}
+
+	signed, err := m.sign(claims)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return signed, claims, nil
+}
+
+// IssueClientAccessToken creates an access token for an OAuth client acting for a user,
+// or for the client itself when userID is 0 (client credentials grant).
+// grantID links tokens of the same authorization so they can be revoked together; it is empty without a user.
+func (m *Manager) IssueClientAccessToken(clientID string, userID int32, grantID, scope string, credentialVersion int32) (string, *Claims, error) {
+	return m.issueClientAccessToken(clientID, userID, grantID, scope, credentialVersion, "")
+}
+
+// IssueServiceAccountToken creates an access token for the client credentials grant of a client owned by
+// a service account. The token acts for the service account, which is its subject, and is marked as such.
+func (m *Manager) IssueServiceAccountToken(clientID string, serviceAccountID int32, scope string, credentialVersion int32) (string, *Claims, error) {
+	return m.issueClientAccessToken(clientID, serviceAccountID, "", scope, credentialVersion, user_model.AccountTypeService)
+}
+
+func (m *Manager) issueClientAccessToken(clientID string, userID int32, grantID, scope string, credentialVersion int32, accountType string) (string, *Claims, error) {
+	jti, err := utils.RandomHex(16)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token ID: %w", err)
+	}
+
+	subject := clientID
+	if userID != 0 {
+		subject = strconv.Itoa(int(userID))
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		Issuer:            m.issuer,
+		Subject:           subject,
+		Audience:          m.audience,
+		IssuedAt:          now.Unix(),
+		ExpiresAt:         now.Add(m.accessTokenTTL).Unix(),
+		ID:                jti,
+		SessionID:         grantID,
+		CredentialVersion: credentialVersion,
+		AccountType:       accountType,
+		ClientID:          clientID,
+		Scope:             scope,
+	}
+
+	signed, err := m.sign(claims)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return signed, claims, nil
+}
+
+// IssueIDToken creates an OpenID Connect ID token about a user for the client clientID
+func (m *Manager) IssueIDToken(clientID string, userID int32, nonce string, profile ProfileClaims) (string, error) {
+	now := time.Now()
+	claims := &IDClaims{
+		Issuer:          m.issuer,
+		Subject:         strconv.Itoa(int(userID)),
+		Audience:        Audience{clientID},
+		ExpiresAt:       now.Add(m.accessTokenTTL).Unix(),
+		IssuedAt:        now.Unix(),
+		Nonce:           nonce,
+		AuthorizedParty: clientID,
+		ProfileClaims:   profile,
+	}
+
+	signed, err := m.sign(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign ID token: %w", err)
+	}
+	return signed, nil
+}
+
+// MFAChallengeTTL returns the lifetime of MFA challenge tokens
+func (m *Manager) MFAChallengeTTL() time.Duration {
+	return m.mfaChallengeTTL
+}
+
+// IssueMFAChallenge creates a short-lived token proving the user passed the password check.
+// It is addressed to the issuer itself so it is never accepted as an access token.
+func (m *Manager) IssueMFAChallenge(userID int32, credentialVersion int32) (string, error) {
+	jti, err := utils.RandomHex(16)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		Issuer:            m.issuer,
+		Subject:           strconv.Itoa(int(userID)),
+		Audience:          Audience{m.issuer},
+		IssuedAt:          now.Unix(),
+		ExpiresAt:         now.Add(m.mfaChallengeTTL).Unix(),
+		ID:                jti,
+		CredentialVersion: credentialVersion,
+		Purpose:           PurposeMFAChallenge,
+	}
+
+	signed, err := m.sign(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign MFA challenge: %w", err)
+	}
+	return signed, nil
+}
+
+// ValidateMFAChallenge verifies a token issued by IssueMFAChallenge
+func (m *Manager) ValidateMFAChallenge(raw string) (*Claims, error) {
+	var claims Claims
+	if err := Parse(raw, m.keys, &claims); err != nil {
+		return nil, err
+	}
+
+	if err := m.validateClaims(&claims, Audience{m.issuer}, time.Now()); err != nil {
+		return nil, err
+	}
+	if claims.Purpose != PurposeMFAChallenge {
+		return nil, ErrInvalidPurpose
+	}
+
+	return &claims, nil
+}
+
+// ValidateAccessToken verifies the signature of raw and checks its expiry, issuer and audience.
+// Only first-party tokens are accepted; tokens issued to OAuth clients are rejected.
+func (m *Manager) ValidateAccessToken(raw string) (*Claims, error) {
+	var claims Claims
+	if err := Parse(raw, m.keys, &claims); err != nil {
+		return nil, err
+	}
+
+	if err := m.validateClaims(&claims, m.audience, time.Now()); err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, ErrInvalidPurpose
+	}
+	if claims.ClientID != "" {
+		return nil, ErrClientToken
+	}
+
+	return &claims, nil
+}
+
+// ValidateClientAccessToken verifies an access token issued to an OAuth client by IssueClientAccessToken
+func (m *Manager) ValidateClientAccessToken(raw string) (*Claims, error) {
+	var claims Claims
+	if err := Parse(raw, m.keys, &claims); err != nil {
+		return nil, err
+	}
+
+	if err := m.validateClaims(&claims, m.audience, time.Now()); err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, ErrInvalidPurpose
+	}
+	if claims.ClientID == "" {
+		return nil, ErrNotClientToken
+	}
+
+	return &claims, nil
+}
+
+// Algorithm returns the algorithm new tokens are signed with
+func (m *Manager) Algorithm() (string, error) {
+	key, err := m.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	return key.Algorithm, nil
+}
+
+// Issuer returns the iss claim of issued tokens
+func (m *Manager) Issuer() string {
+	return m.issuer
+}
+
+// PublicKeys returns the keys tokens may currently be verified with, as a JSON Web Key Set.
+// HMAC keys are never published.
+func (m *Manager) PublicKeys() (*JWKSet, error) {
+	keys, err := m.keys.VerificationKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	set := &JWKSet{Keys: []JWK{}}
+	for _, key := range keys {
+		if jwk, ok := NewJWK(key); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set, nil
+}
+
+func (m *Manager) sign(claims any) (string, error) {
+	key, err := m.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	return Sign(claims, key)
+}
+
+func (m *Manager) validateClaims(claims *Claims, audience Audience, now time.Time) error {
+	skew := int64(m.clockSkew.Seconds())
+	unix := now.Unix()
+
+	if claims.ExpiresAt == 0 || unix > claims.ExpiresAt+skew {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && unix+skew < claims.NotBefore {
+		return ErrTokenNotYetValid
+	}
+	if claims.IssuedAt != 0 && unix+skew < claims.IssuedAt {
+		return ErrTokenNotYetValid
+	}
+	if claims.Issuer != m.issuer {
+		return ErrInvalidIssuer
+	}
+
+	for _, aud := range audience {
+		if claims.Audience.Contains(aud) {
+			return nil
+		}
+	}
+	return ErrInvalidAudience
+}
@@ -0,0 +1,50 @@
+package verification
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/mail"
+	user_model "go-backend-valos-id/core/user/model"
+	"go-backend-valos-id/core/utils"
+)
+
+// EmailVerifier issues verification tokens and mails the verification link to the user's current address
+type EmailVerifier struct {
+	verificationRepo *repository.EmailVerificationRepository
+	mailer           mail.Sender
+	appBaseURL       string
+	ttl              time.Duration
+}
+
+func NewEmailVerifier(verificationRepo *repository.EmailVerificationRepository, mailer mail.Sender, appBaseURL string, ttl time.Duration) *EmailVerifier {
+	return &EmailVerifier{
+		verificationRepo: verificationRepo,
+		mailer:           mailer,
+		appBaseURL:       appBaseURL,
+		ttl:              ttl,
+	}
+}
+
+// SendVerification emails a new verification link for the user's current email address
+func (v *EmailVerifier) SendVerification(user *user_model.User) error {
+	verificationToken, err := utils.RandomToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(v.ttl)
+	if err := v.verificationRepo.CreateEmailVerificationToken(user.ID, user.Email, utils.HashToken(verificationToken), expiresAt); err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", v.appBaseURL, url.QueryEscape(verificationToken))
+	return v.mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm that %s is your email address by opening the link below. It expires in %s.\n\n%s\n",
+			user.Username, user.Email, v.ttl, link),
+	})
+}
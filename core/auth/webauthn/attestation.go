@@ -0,0 +1,83 @@
+package webauthn
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+
+	"go-backend-valos-id/core/internal/cbor"
+)
+
+var (
+	ErrMalformedAttestation   = errors.New("malformed attestation object")
+	ErrUnsupportedAttestation = errors.New("unsupported attestation format")
+)
+
+// attestationObject is the decoded CBOR attestation object returned on registration
+type attestationObject struct {
+	Format   string
+	Stmt     map[any]any
+	AuthData []byte
+}
+
+func parseAttestationObject(raw []byte) (*attestationObject, error) {
+	decoded, rest, err := cbor.Decode(raw)
+	if err != nil || len(rest) != 0 {
+		return nil, ErrMalformedAttestation
+	}
+	m, ok := decoded.(map[any]any)
+	if !ok {
+		return nil, ErrMalformedAttestation
+	}
+
+	obj := &attestationObject{}
+	obj.Format, _ = m["fmt"].(string)
+	obj.Stmt, _ = m["attStmt"].(map[any]any)
+	obj.AuthData, _ = m["authData"].([]byte)
+	if obj.Format == "" || obj.Stmt == nil || obj.AuthData == nil {
+		return nil, ErrMalformedAttestation
+	}
+	return obj, nil
+}
+
+// verify checks the attestation statement signature.
+// Options request no attestation, so certificates in packed statements are not checked against a trust store.
+func (o *attestationObject) verify(credentialKey *PublicKey, clientDataJSON []byte) error {
+	switch o.Format {
+	case "none":
+		if len(o.Stmt) != 0 {
+			return ErrMalformedAttestation
+		}
+		return nil
+
+	case "packed":
+		alg, _ := o.Stmt["alg"].(int64)
+		sig, _ := o.Stmt["sig"].([]byte)
+		if sig == nil {
+			return ErrMalformedAttestation
+		}
+
+		clientDataHash := sha256.Sum256(clientDataJSON)
+		signed := append(append([]byte(nil), o.AuthData...), clientDataHash[:]...)
+
+		chain, hasChain := o.Stmt["x5c"].([]any)
+		if !hasChain {
+			// Self attestation is signed with the credential key itself
+			if alg != credentialKey.Algorithm {
+				return ErrMalformedAttestation
+			}
+			return credentialKey.Verify(signed, sig)
+		}
+
+		if len(chain) == 0 {
+			return ErrMalformedAttestation
+		}
+		leaf, _ := chain[0].([]byte)
+		cert, err := x509.ParseCertificate(leaf)
+		if err != nil {
+			return ErrMalformedAttestation
+		}
+		return verifySignature(alg, cert.PublicKey, signed, sig)
+	}
+	return ErrUnsupportedAttestation
+}
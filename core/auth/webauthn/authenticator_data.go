@@ -0,0 +1,90 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"go-backend-valos-id/core/internal/cbor"
+)
+
+// Authenticator data flags
+const (
+	flagUserPresent        = 0x01
+	flagUserVerified       = 0x04
+	flagAttestedCredential = 0x40
+	flagExtensionData      = 0x80
+)
+
+var errMalformedAuthenticatorData = errors.New("malformed authenticator data")
+
+// AuthenticatorData is the parsed authenticator data structure (WebAuthn §6.1)
+type AuthenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+	// The attested credential fields are only set during registration
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+func (d *AuthenticatorData) UserPresent() bool {
+	return d.Flags&flagUserPresent != 0
+}
+
+func (d *AuthenticatorData) UserVerified() bool {
+	return d.Flags&flagUserVerified != 0
+}
+
+func (d *AuthenticatorData) HasAttestedCredential() bool {
+	return d.Flags&flagAttestedCredential != 0
+}
+
+// ParseAuthenticatorData parses raw authenticator data
+func ParseAuthenticatorData(raw []byte) (*AuthenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, errMalformedAuthenticatorData
+	}
+
+	data := &AuthenticatorData{
+		RPIDHash:  raw[:32],
+		Flags:     raw[32],
+		SignCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	rest := raw[37:]
+
+	if data.HasAttestedCredential() {
+		if len(rest) < 18 {
+			return nil, errMalformedAuthenticatorData
+		}
+		data.AAGUID = rest[:16]
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if len(rest) < idLen {
+			return nil, errMalformedAuthenticatorData
+		}
+		data.CredentialID = rest[:idLen]
+		rest = rest[idLen:]
+
+		// The COSE key is followed directly by any extension data, so decode it to find its length
+		_, after, err := cbor.Decode(rest)
+		if err != nil {
+			return nil, errMalformedAuthenticatorData
+		}
+		data.PublicKey = rest[:len(rest)-len(after)]
+		rest = after
+	}
+
+	if data.Flags&flagExtensionData != 0 {
+		_, after, err := cbor.Decode(rest)
+		if err != nil {
+			return nil, errMalformedAuthenticatorData
+		}
+		rest = after
+	}
+
+	if len(rest) != 0 {
+		return nil, errMalformedAuthenticatorData
+	}
+	return data, nil
+}
@@ -0,0 +1,248 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"go-backend-valos-id/core/config"
+)
+
+// User verification requirements
+const (
+	UserVerificationRequired  = "required"
+	UserVerificationPreferred = "preferred"
+)
+
+var (
+	ErrInvalidCeremony       = errors.New("client data does not match the ceremony")
+	ErrInvalidOrigin         = errors.New("origin is not allowed")
+	ErrInvalidRPID           = errors.New("authenticator data is for another relying party")
+	ErrUserNotPresent        = errors.New("user presence was not confirmed")
+	ErrUserNotVerified       = errors.New("user verification is required")
+	ErrCredentialMismatch    = errors.New("response is for another credential")
+	ErrSignCountNotIncreased = errors.New("signature counter did not increase, the authenticator may be cloned")
+)
+
+// Credential is the part of a registered credential needed to verify assertions
+type Credential struct {
+	ID         []byte
+	PublicKey  []byte
+	SignCount  uint32
+	AAGUID     []byte
+	Transports []string
+}
+
+// AssertionResult is the outcome of a verified assertion
+type AssertionResult struct {
+	SignCount    uint32
+	UserVerified bool
+}
+
+// RelyingParty builds ceremony options and verifies authenticator responses for one RP ID
+type RelyingParty struct {
+	id               string
+	name             string
+	origins          []string
+	timeout          time.Duration
+	userVerification string
+}
+
+func NewRelyingParty(cfg *config.WebAuthnConfig) *RelyingParty {
+	return &RelyingParty{
+		id:               cfg.RPID,
+		name:             cfg.RPName,
+		origins:          cfg.Origins,
+		timeout:          cfg.ChallengeTTL,
+		userVerification: cfg.UserVerification,
+	}
+}
+
+// NewChallenge returns a random ceremony challenge
+func NewChallenge() ([]byte, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// CreationOptions returns registration options. Existing credentials are excluded so an authenticator is not registered twice.
+func (rp *RelyingParty) CreationOptions(challenge []byte, user UserEntity, exclude []Credential) *CredentialCreationOptions {
+	params := make([]CredentialParameter, len(supportedAlgorithms))
+	for i, alg := range supportedAlgorithms {
+		params[i] = CredentialParameter{Type: "public-key", Alg: alg}
+	}
+
+	return &CredentialCreationOptions{
+		RP:                 RelyingPartyEntity{ID: rp.id, Name: rp.name},
+		User:               user,
+		Challenge:          challenge,
+		PubKeyCredParams:   params,
+		Timeout:            rp.timeout.Milliseconds(),
+		ExcludeCredentials: descriptors(exclude),
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: rp.userVerification,
+		},
+		Attestation: "none",
+	}
+}
+
+// RequestOptions returns login options. With no allowed credentials the client offers discoverable credentials.
+func (rp *RelyingParty) RequestOptions(challenge []byte, allow []Credential) *CredentialRequestOptions {
+	return &CredentialRequestOptions{
+		Challenge:        challenge,
+		Timeout:          rp.timeout.Milliseconds(),
+		RPID:             rp.id,
+		AllowCredentials: descriptors(allow),
+		UserVerification: rp.userVerification,
+	}
+}
+
+// VerifyRegistration checks a registration response against the issued challenge and returns the new credential
+func (rp *RelyingParty) VerifyRegistration(challenge []byte, resp *RegistrationResponse) (*Credential, error) {
+	if resp.Type != "public-key" {
+		return nil, ErrInvalidCeremony
+	}
+	if err := rp.verifyClientData(resp.Response.ClientDataJSON, ceremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	attestation, err := parseAttestationObject(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, err
+	}
+
+	authData, err := ParseAuthenticatorData(attestation.AuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+	if !authData.HasAttestedCredential() {
+		return nil, ErrMalformedAttestation
+	}
+	if !bytes.Equal(authData.CredentialID, resp.RawID) {
+		return nil, ErrCredentialMismatch
+	}
+
+	publicKey, err := ParsePublicKey(authData.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := attestation.verify(publicKey, resp.Response.ClientDataJSON); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:         authData.CredentialID,
+		PublicKey:  authData.PublicKey,
+		SignCount:  authData.SignCount,
+		AAGUID:     authData.AAGUID,
+		Transports: resp.Response.Transports,
+	}, nil
+}
+
+// VerifyAssertion checks a login response for credential against the issued challenge.
+// A signature counter that fails to increase is rejected as a sign of a cloned authenticator.
+func (rp *RelyingParty) VerifyAssertion(challenge []byte, resp *AssertionResponse, credential *Credential) (*AssertionResult, error) {
+	if resp.Type != "public-key" {
+		return nil, ErrInvalidCeremony
+	}
+	if !bytes.Equal(resp.RawID, credential.ID) {
+		return nil, ErrCredentialMismatch
+	}
+	if err := rp.verifyClientData(resp.Response.ClientDataJSON, ceremonyGet, challenge); err != nil {
+		return nil, err
+	}
+
+	authData, err := ParseAuthenticatorData(resp.Response.AuthenticatorData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+
+	publicKey, err := ParsePublicKey(credential.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+	signed := append(append([]byte(nil), resp.Response.AuthenticatorData...), clientDataHash[:]...)
+	if err := publicKey.Verify(signed, resp.Response.Signature); err != nil {
+		return nil, err
+	}
+
+	// Authenticators without a counter always report zero
+	if (authData.SignCount != 0 || credential.SignCount != 0) && authData.SignCount <= credential.SignCount {
+		return nil, ErrSignCountNotIncreased
+	}
+
+	return &AssertionResult{
+		SignCount:    authData.SignCount,
+		UserVerified: authData.UserVerified(),
+	}, nil
+}
+
+// Helper methods
+
+func (rp *RelyingParty) verifyClientData(raw []byte, ceremony string, challenge []byte) error {
+	var clientData CollectedClientData
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return ErrInvalidCeremony
+	}
+
+	if clientData.Type != ceremony {
+		return ErrInvalidCeremony
+	}
+
+	expected := base64.RawURLEncoding.EncodeToString(challenge)
+	if subtle.ConstantTimeCompare([]byte(clientData.Challenge), []byte(expected)) != 1 {
+		return ErrInvalidCeremony
+	}
+
+	if clientData.CrossOrigin || !slices.Contains(rp.origins, clientData.Origin) {
+		return ErrInvalidOrigin
+	}
+	return nil
+}
+
+func (rp *RelyingParty) verifyAuthenticatorData(authData *AuthenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.id))
+	if subtle.ConstantTimeCompare(authData.RPIDHash, rpIDHash[:]) != 1 {
+		return ErrInvalidRPID
+	}
+	if !authData.UserPresent() {
+		return ErrUserNotPresent
+	}
+	if rp.userVerification == UserVerificationRequired && !authData.UserVerified() {
+		return ErrUserNotVerified
+	}
+	return nil
+}
+
+func descriptors(credentials []Credential) []CredentialDescriptor {
+	if len(credentials) == 0 {
+		return nil
+	}
+
+	result := make([]CredentialDescriptor, len(credentials))
+	for i, credential := range credentials {
+		result[i] = CredentialDescriptor{
+			Type:       "public-key",
+			ID:         credential.ID,
+			Transports: credential.Transports,
+		}
+	}
+	return result
+}
@@ -0,0 +1,251 @@
+package webauthn_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-backend-valos-id/core/auth/webauthn"
+	"go-backend-valos-id/core/auth/webauthn/webauthntest"
+	"go-backend-valos-id/core/config"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+var testUser = webauthn.UserEntity{
+	ID:          []byte("user-1"),
+	Name:        "alice@example.com",
+	DisplayName: "Alice",
+}
+
+func newRelyingParty(rpID string) *webauthn.RelyingParty {
+	return webauthn.NewRelyingParty(&config.WebAuthnConfig{
+		RPID:             rpID,
+		RPName:           "Example",
+		Origins:          []string{testOrigin},
+		ChallengeTTL:     5 * time.Minute,
+		UserVerification: webauthn.UserVerificationRequired,
+	})
+}
+
+func newChallenge(t *testing.T) []byte {
+	t.Helper()
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		t.Fatalf("NewChallenge: %v", err)
+	}
+	return challenge
+}
+
+// register runs a registration ceremony between rp and authenticator and returns the verified credential
+func register(t *testing.T, rp *webauthn.RelyingParty, authenticator *webauthntest.Authenticator) *webauthn.Credential {
+	t.Helper()
+	challenge := newChallenge(t)
+	resp, err := authenticator.Register(rp.CreationOptions(challenge, testUser, nil))
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	credential, err := rp.VerifyRegistration(challenge, resp)
+	if err != nil {
+		t.Fatalf("VerifyRegistration: %v", err)
+	}
+	return credential
+}
+
+// login runs a login ceremony for credential and returns the verification outcome
+func login(t *testing.T, rp *webauthn.RelyingParty, authenticator *webauthntest.Authenticator, credential *webauthn.Credential) (*webauthn.AssertionResult, error) {
+	t.Helper()
+	challenge := newChallenge(t)
+	resp, err := authenticator.Login(rp.RequestOptions(challenge, []webauthn.Credential{*credential}))
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return rp.VerifyAssertion(challenge, resp, credential)
+}
+
+func TestRegistrationAndLogin(t *testing.T) {
+	rp := newRelyingParty(testRPID)
+	authenticator := webauthntest.NewAuthenticator(testOrigin)
+
+	credential := register(t, rp, authenticator)
+	if len(credential.ID) == 0 || len(credential.PublicKey) == 0 {
+		t.Fatal("registration returned an empty credential")
+	}
+	if credential.SignCount != 0 {
+		t.Fatalf("registration sign count = %d, want 0", credential.SignCount)
+	}
+
+	for want := uint32(1); want <= 3; want++ {
+		result, err := login(t, rp, authenticator, credential)
+		if err != nil {
+			t.Fatalf("login %d: %v", want, err)
+		}
+		if result.SignCount != want {
+			t.Fatalf("login %d: sign count = %d, want %d", want, result.SignCount, want)
+		}
+		if !result.UserVerified {
+			t.Fatalf("login %d: user not verified", want)
+		}
+		credential.SignCount = result.SignCount
+	}
+}
+
+func TestDiscoverableLogin(t *testing.T) {
+	rp := newRelyingParty(testRPID)
+	authenticator := webauthntest.NewAuthenticator(testOrigin)
+	credential := register(t, rp, authenticator)
+
+	challenge := newChallenge(t)
+	resp, err := authenticator.Login(rp.RequestOptions(challenge, nil))
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if string(resp.Response.UserHandle) != string(testUser.ID) {
+		t.Fatalf("user handle = %q, want %q", resp.Response.UserHandle, testUser.ID)
+	}
+	if _, err := rp.VerifyAssertion(challenge, resp, credential); err != nil {
+		t.Fatalf("VerifyAssertion: %v", err)
+	}
+}
+
+func TestRegistrationExcludesExistingCredentials(t *testing.T) {
+	rp := newRelyingParty(testRPID)
+	authenticator := webauthntest.NewAuthenticator(testOrigin)
+	credential := register(t, rp, authenticator)
+
+	options := rp.CreationOptions(newChallenge(t), testUser, []webauthn.Credential{*credential})
+	if _, err := authenticator.Register(options); !errors.Is(err, webauthntest.ErrExcluded) {
+		t.Fatalf("Register error = %v, want %v", err, webauthntest.ErrExcluded)
+	}
+}
+
+func TestRegistrationRejectsRPIDMismatch(t *testing.T) {
+	rp := newRelyingParty(testRPID)
+	authenticator := webauthntest.NewAuthenticator(testOrigin)
+
+	challenge := newChallenge(t)
+	options := rp.CreationOptions(challenge, testUser, nil)
+	options.RP.ID = "evil.example"
+	resp, err := authenticator.Register(options)
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	if _, err := rp.VerifyRegistration(challenge, resp); !errors.Is(err, webauthn.ErrInvalidRPID) {
+		t.Fatalf("VerifyRegistration error = %v, want %v", err, webauthn.ErrInvalidRPID)
+	}
+}
+
+func TestLoginRejectsRPIDMismatch(t *testing.T) {
+	rp := newRelyingParty(testRPID)
+	other := newRelyingParty("evil.example")
+	authenticator := webauthntest.NewAuthenticator(testOrigin)
+
+	// The credential is bound to the other RP, so its assertions carry the other rpIdHash
+	credential := register(t, other, authenticator)
+	challenge := newChallenge(t)
+	resp, err := authenticator.Login(other.RequestOptions(challenge, []webauthn.Credential{*credential}))
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	if _, err := rp.VerifyAssertion(challenge, resp, credential); !errors.Is(err, webauthn.ErrInvalidRPID) {
+		t.Fatalf("VerifyAssertion error = %v, want %v", err, webauthn.ErrInvalidRPID)
+	}
+}
+
+func TestRejectsOriginMismatch(t *testing.T) {
+	rp := newRelyingParty(testRPID)
+	authenticator := webauthntest.NewAuthenticator(testOrigin)
+	credential := register(t, rp, authenticator)
+
+	authenticator.Origin = "https://evil.example"
+
+	challenge := newChallenge(t)
+	registration, err := authenticator.Register(rp.CreationOptions(challenge, testUser, nil))
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := rp.VerifyRegistration(challenge, registration); !errors.Is(err, webauthn.ErrInvalidOrigin) {
+		t.Fatalf("VerifyRegistration error = %v, want %v", err, webauthn.ErrInvalidOrigin)
+	}
+
+	if _, err := login(t, rp, authenticator, credential); !errors.Is(err, webauthn.ErrInvalidOrigin) {
+		t.Fatalf("VerifyAssertion error = %v, want %v", err, webauthn.ErrInvalidOrigin)
+	}
+}
+
+func TestRejectsChallengeMismatch(t *testing.T) {
+	rp := newRelyingParty(testRPID)
+	authenticator := webauthntest.NewAuthenticator(testOrigin)
+	credential := register(t, rp, authenticator)
+
+	registration, err := authenticator.Register(rp.CreationOptions(newChallenge(t), testUser, nil))
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := rp.VerifyRegistration(newChallenge(t), registration); !errors.Is(err, webauthn.ErrInvalidCeremony) {
+		t.Fatalf("VerifyRegistration error = %v, want %v", err, webauthn.ErrInvalidCeremony)
+	}
+
+	assertion, err := authenticator.Login(rp.RequestOptions(newChallenge(t), []webauthn.Credential{*credential}))
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if _, err := rp.VerifyAssertion(newChallenge(t), assertion, credential); !errors.Is(err, webauthn.ErrInvalidCeremony) {
+		t.Fatalf("VerifyAssertion error = %v, want %v", err, webauthn.ErrInvalidCeremony)
+	}
+}
+
+func TestLoginRejectsUnverifiedUser(t *testing.T) {
+	rp := newRelyingParty(testRPID)
+	authenticator := webauthntest.NewAuthenticator(testOrigin)
+	credential := register(t, rp, authenticator)
+
+	authenticator.UserVerified = false
+	if _, err := login(t, rp, authenticator, credential); !errors.Is(err, webauthn.ErrUserNotVerified) {
+		t.Fatalf("VerifyAssertion error = %v, want %v", err, webauthn.ErrUserNotVerified)
+	}
+}
+
+func TestLoginDetectsClonedAuthenticator(t *testing.T) {
+	rp := newRelyingParty(testRPID)
+	authenticator := webauthntest.NewAuthenticator(testOrigin)
+	credential := register(t, rp, authenticator)
+	clone := authenticator.Clone()
+
+	result, err := login(t, rp, authenticator, credential)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	credential.SignCount = result.SignCount
+
+	// The clone reports the same counter the original just used
+	if _, err := login(t, rp, clone, credential); !errors.Is(err, webauthn.ErrSignCountNotIncreased) {
+		t.Fatalf("VerifyAssertion error = %v, want %v", err, webauthn.ErrSignCountNotIncreased)
+	}
+}
+
+func TestLoginRejectsReplayedAssertion(t *testing.T) {
+	rp := newRelyingParty(testRPID)
+	authenticator := webauthntest.NewAuthenticator(testOrigin)
+	credential := register(t, rp, authenticator)
+
+	challenge := newChallenge(t)
+	resp, err := authenticator.Login(rp.RequestOptions(challenge, []webauthn.Credential{*credential}))
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	result, err := rp.VerifyAssertion(challenge, resp, credential)
+	if err != nil {
+		t.Fatalf("VerifyAssertion: %v", err)
+	}
+	credential.SignCount = result.SignCount
+
+	if _, err := rp.VerifyAssertion(challenge, resp, credential); !errors.Is(err, webauthn.ErrSignCountNotIncreased) {
+		t.Fatalf("replayed VerifyAssertion error = %v, want %v", err, webauthn.ErrSignCountNotIncreased)
+	}
+}
@@ -0,0 +1,243 @@
+// Package webauthntest provides a software authenticator for exercising WebAuthn
+// registration and login ceremonies end to end in Go tests, without a browser.
+package webauthntest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+
+	"go-backend-valos-id/core/auth/webauthn"
+	"go-backend-valos-id/core/internal/cbor"
+)
+
+// Authenticator flags
+const (
+	flagUserPresent        = 0x01
+	flagUserVerified       = 0x04
+	flagAttestedCredential = 0x40
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("webauthntest: ES256 was not offered")
+	ErrExcluded             = errors.New("webauthntest: credential is excluded")
+	ErrNoCredential         = errors.New("webauthntest: no matching credential")
+)
+
+// Authenticator is an in-memory platform authenticator holding ES256 discoverable credentials
+type Authenticator struct {
+	// Origin is reported in the client data, as a browser would
+	Origin string
+	// UserVerified sets the UV flag, as after a PIN or biometric check
+	UserVerified bool
+
+	mu          sync.Mutex
+	credentials []*credential
+}
+
+type credential struct {
+	id         []byte
+	rpID       string
+	userHandle []byte
+	key        *ecdsa.PrivateKey
+	signCount  uint32
+}
+
+func NewAuthenticator(origin string) *Authenticator {
+	return &Authenticator{
+		Origin:       origin,
+		UserVerified: true,
+	}
+}
+
+// Register creates a credential as navigator.credentials.create would and returns the response with "none" attestation
+func (a *Authenticator) Register(options *webauthn.CredentialCreationOptions) (*webauthn.RegistrationResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	offered := false
+	for _, param := range options.PubKeyCredParams {
+		offered = offered || param.Alg == webauthn.AlgES256
+	}
+	if !offered {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	for _, excluded := range options.ExcludeCredentials {
+		if a.find(options.RP.ID, excluded.ID) != nil {
+			return nil, ErrExcluded
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	cred := &credential{
+		id:         id,
+		rpID:       options.RP.ID,
+		userHandle: slices.Clone(options.User.ID),
+		key:        key,
+	}
+
+	publicKey, err := encodePublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	authData := a.authenticatorData(cred, flagAttestedCredential)
+	authData = append(authData, make([]byte, 16)...) // AAGUID
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(id)))
+	authData = append(authData, id...)
+	authData = append(authData, publicKey...)
+
+	attestationObject, err := cbor.Encode(cbor.Map{
+		{Key: "fmt", Value: "none"},
+		{Key: "attStmt", Value: cbor.Map{}},
+		{Key: "authData", Value: authData},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clientData, err := a.clientData("webauthn.create", options.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	a.credentials = append(a.credentials, cred)
+
+	return &webauthn.RegistrationResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(id),
+		RawID: id,
+		Type:  "public-key",
+		Response: webauthn.AuthenticatorAttestationResponse{
+			ClientDataJSON:    clientData,
+			AttestationObject: attestationObject,
+			Transports:        []string{"internal"},
+		},
+	}, nil
+}
+
+// Login signs an assertion as navigator.credentials.get would, using the first allowed credential
+// or, when none are listed, the first discoverable credential for the RP
+func (a *Authenticator) Login(options *webauthn.CredentialRequestOptions) (*webauthn.AssertionResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var cred *credential
+	if len(options.AllowCredentials) == 0 {
+		cred = a.find(options.RPID, nil)
+	}
+	for _, allowed := range options.AllowCredentials {
+		if cred = a.find(options.RPID, allowed.ID); cred != nil {
+			break
+		}
+	}
+	if cred == nil {
+		return nil, ErrNoCredential
+	}
+
+	cred.signCount++
+	authData := a.authenticatorData(cred, 0)
+
+	clientData, err := a.clientData("webauthn.get", options.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(slices.Clone(authData), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, cred.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &webauthn.AssertionResponse{
+		ID:    base64.RawURLEncoding.EncodeToString(cred.id),
+		RawID: slices.Clone(cred.id),
+		Type:  "public-key",
+		Response: webauthn.AuthenticatorAssertionResponse{
+			ClientDataJSON:    clientData,
+			AuthenticatorData: authData,
+			Signature:         signature,
+			UserHandle:        slices.Clone(cred.userHandle),
+		},
+	}, nil
+}
+
+// Clone returns an authenticator holding copies of the same credentials,
+// for exercising signature counter based clone detection
+func (a *Authenticator) Clone() *Authenticator {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	clone := &Authenticator{
+		Origin:       a.Origin,
+		UserVerified: a.UserVerified,
+	}
+	for _, cred := range a.credentials {
+		copied := *cred
+		clone.credentials = append(clone.credentials, &copied)
+	}
+	return clone
+}
+
+// Helper methods
+
+func (a *Authenticator) find(rpID string, id []byte) *credential {
+	for _, cred := range a.credentials {
+		if cred.rpID == rpID && (id == nil || bytes.Equal(cred.id, id)) {
+			return cred
+		}
+	}
+	return nil
+}
+
+func (a *Authenticator) authenticatorData(cred *credential, flags byte) []byte {
+	flags |= flagUserPresent
+	if a.UserVerified {
+		flags |= flagUserVerified
+	}
+
+	rpIDHash := sha256.Sum256([]byte(cred.rpID))
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, cred.signCount)
+}
+
+func (a *Authenticator) clientData(ceremony string, challenge []byte) ([]byte, error) {
+	return json.Marshal(webauthn.CollectedClientData{
+		Type:      ceremony,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    a.Origin,
+	})
+}
+
+func encodePublicKey(pub *ecdsa.PublicKey) ([]byte, error) {
+	ecdhKey, err := pub.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	point := ecdhKey.Bytes() // 0x04 || x || y
+
+	return cbor.Encode(cbor.Map{
+		{Key: 1, Value: 2},  // kty: EC2
+		{Key: 3, Value: -7}, // alg: ES256
+		{Key: -1, Value: 1}, // crv: P-256
+		{Key: -2, Value: point[1:33]},
+		{Key: -3, Value: point[33:65]},
+	})
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"time"
+)
+
+type AuditConfig struct {
+	// CheckpointInterval is how often the newest audit event is checkpointed; 0 disables checkpoints
+	CheckpointInterval time.Duration
+	// CheckpointKeyFile is the PEM Ed25519 private key checkpoints are signed with
+	CheckpointKeyFile string
+	// CheckpointPublicKeysFile holds further PEM public keys checkpoints may be verified with,
+	// such as keys that signed earlier checkpoints
+	CheckpointPublicKeysFile string
+}
+
+func NewAuditConfig() *AuditConfig {
+	return &AuditConfig{
+		CheckpointInterval:       getEnvDuration("AUDIT_CHECKPOINT_INTERVAL", time.Hour),
+		CheckpointKeyFile:        getEnv("AUDIT_CHECKPOINT_KEY_FILE", ""),
+		CheckpointPublicKeysFile: getEnv("AUDIT_CHECKPOINT_PUBLIC_KEYS_FILE", ""),
+	}
+}
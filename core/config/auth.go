@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signing key sources
+const (
+	KeySourceConfig   = "config"
+	KeySourceDatabase = "database"
+)
+
+type AuthConfig struct {
+	// JWTAlgorithm selects the access token signing algorithm: HS256, RS256, ES256 or EdDSA
+	JWTAlgorithm      string
+	JWTSecret         string
+	JWTPrivateKeyFile string
+	JWTKeyID          string
+	// JWTKeySource is "config" to sign with the single key configured above,
+	// or "database" to sign with generated keys from the rotating key store
+	JWTKeySource string
+	// JWTMasterKey is the base64 encoded 32-byte AES key protecting private keys in the key store
+	JWTMasterKey           string
+	JWTKeyRotationInterval time.Duration
+	// JWTKeyCacheTTL bounds how long a rotation made by another instance may go unnoticed
+	JWTKeyCacheTTL  time.Duration
+	Issuer          string
+	Audience        []string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// ClockSkew is the leeway allowed when checking exp, nbf and iat
+	ClockSkew time.Duration
+	// SessionCacheTTL bounds how long a session revoked on another instance may keep working
+	SessionCacheTTL time.Duration
+	// PermissionCacheTTL bounds how long a role change made on another instance takes to apply
+	PermissionCacheTTL   time.Duration
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	// InvitationTTL is how long an organization invitation link can be accepted
+	InvitationTTL time.Duration
+	// RequireVerifiedEmail blocks login until the user's email address is verified
+	RequireVerifiedEmail bool
+	// MFAEncryptionKey is the base64 encoded 32-byte AES key protecting TOTP secrets at rest
+	MFAEncryptionKey string
+	// MFAIssuer is the account label shown in authenticator apps
+	MFAIssuer       string
+	MFAChallengeTTL time.Duration
+}
+
+func NewAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		JWTAlgorithm:           getEnv("JWT_ALGORITHM", "HS256"),
+		JWTSecret:              os.Getenv("JWT_SECRET"),
+		JWTPrivateKeyFile:      os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		JWTKeyID:               os.Getenv("JWT_KEY_ID"),
+		JWTKeySource:           getEnv("JWT_KEY_SOURCE", KeySourceConfig),
+		JWTMasterKey:           os.Getenv("JWT_MASTER_KEY"),
+		JWTKeyRotationInterval: getEnvDuration("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour),
+		JWTKeyCacheTTL:         getEnvDuration("JWT_KEY_CACHE_TTL", time.Minute),
+		Issuer:                 getEnv("JWT_ISSUER", "valos-id"),
+		Audience:               getEnvList("JWT_AUDIENCE", []string{"valos-api"}),
+		AccessTokenTTL:         getEnvDuration("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:        getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		ClockSkew:              getEnvDuration("JWT_CLOCK_SKEW", 30*time.Second),
+		SessionCacheTTL:        getEnvDuration("SESSION_CACHE_TTL", 30*time.Second),
+		PermissionCacheTTL:     getEnvDuration("PERMISSION_CACHE_TTL", 30*time.Second),
+		PasswordResetTTL:       getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+		EmailVerificationTTL:   getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		InvitationTTL:          getEnvDuration("ORGANIZATION_INVITATION_TTL", 7*24*time.Hour),
+		RequireVerifiedEmail:   getEnvBool("AUTH_REQUIRE_VERIFIED_EMAIL", false),
+		MFAEncryptionKey:       os.Getenv("MFA_ENCRYPTION_KEY"),
+		MFAIssuer:              getEnv("MFA_ISSUER", "Valos ID"),
+		MFAChallengeTTL:        getEnvDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+	}
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return defaultValue
+	}
+	return items
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"time"
+)
+
+// Lockout stores
+const (
+	LockoutStoreMemory   = "memory"
+	LockoutStoreDatabase = "database"
+)
+
+type LockoutConfig struct {
+	// Store is "database" to share failure counts between instances, or "memory" to keep them per instance
+	Store string
+	// AccountMaxFailures is how many failed logins an account tolerates before it is locked
+	AccountMaxFailures int
+	// AccountLockFailures is how many failed logins from one IP address within the failure window set an
+	// account's status to locked, until an administrator reactivates it; 0 never locks accounts.
+	// Locking trades availability for safety: anyone who knows an account name and can fail that many
+	// times from one address locks the real user out until an administrator steps in. The timed blocks
+	// above already slow guessing down, so only enable this where that denial of service is acceptable.
+	AccountLockFailures int
+	// IPMaxFailures is how many failed logins an IP address tolerates before it is blocked
+	IPMaxFailures int
+	// BaseDelay is the first lockout; each further failure doubles it up to MaxDuration
+	BaseDelay   time.Duration
+	MaxDuration time.Duration
+	// FailureWindow is how long a failure counts; counting starts over after a quiet window
+	FailureWindow time.Duration
+}
+
+func NewLockoutConfig() *LockoutConfig {
+	return &LockoutConfig{
+		Store:               getEnv("LOCKOUT_STORE", LockoutStoreDatabase),
+		AccountMaxFailures:  getEnvInt("LOCKOUT_ACCOUNT_MAX_FAILURES", 5),
+		AccountLockFailures: getEnvInt("LOCKOUT_ACCOUNT_LOCK_FAILURES", 0),
+		IPMaxFailures:       getEnvInt("LOCKOUT_IP_MAX_FAILURES", 20),
+		BaseDelay:           getEnvDuration("LOCKOUT_BASE_DELAY", 30*time.Second),
+		MaxDuration:         getEnvDuration("LOCKOUT_MAX_DURATION", 15*time.Minute),
+		FailureWindow:       getEnvDuration("LOCKOUT_FAILURE_WINDOW", time.Hour),
+	}
+}
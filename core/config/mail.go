@@ -0,0 +1,19 @@
+package config
+
+type MailConfig struct {
+	// Driver selects the mail sender: log or file
+	Driver  string
+	From    string
+	FileDir string
+	// AppBaseURL is the client application URL used to build links in emails
+	AppBaseURL string
+}
+
+func NewMailConfig() *MailConfig {
+	return &MailConfig{
+		Driver:     getEnv("MAIL_DRIVER", "log"),
+		From:       getEnv("MAIL_FROM", "no-reply@valos.id"),
+		FileDir:    getEnv("MAIL_FILE_DIR", "tmp/mail"),
+		AppBaseURL: getEnv("APP_BASE_URL", "http://localhost:3000"),
+	}
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"time"
+)
+
+type OAuthConfig struct {
+	// Scopes lists the scopes OAuth clients may be registered for
+	Scopes               []string
+	AuthorizationCodeTTL time.Duration
+	RefreshTokenTTL      time.Duration
+}
+
+func NewOAuthConfig() *OAuthConfig {
+	return &OAuthConfig{
+		Scopes:               getEnvList("OAUTH_SCOPES", []string{"openid", "profile", "email"}),
+		AuthorizationCodeTTL: getEnvDuration("OAUTH_CODE_TTL", time.Minute),
+		RefreshTokenTTL:      getEnvDuration("OAUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+	}
+}
@@ -0,0 +1,17 @@
+package config
+
+import (
+	"os"
+)
+
+type PaginationConfig struct {
+	// CursorKey is the base64 encoded key of at least 32 bytes signing pagination cursors.
+	// Every instance needs the same key for cursors to work across them.
+	CursorKey string
+}
+
+func NewPaginationConfig() *PaginationConfig {
+	return &PaginationConfig{
+		CursorKey: os.Getenv("PAGINATION_CURSOR_KEY"),
+	}
+}
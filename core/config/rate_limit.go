@@ -0,0 +1,45 @@
+package config
+
+import (
+	"time"
+)
+
+// Rate limit stores
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStoreDatabase = "database"
+)
+
+// RateLimitRule allows Requests requests per Window; zero requests disables the limit
+type RateLimitRule struct {
+	Requests int
+	Window   time.Duration
+}
+
+type RateLimitConfig struct {
+	// Store is "memory" to count requests per instance, or "database" to share the counts between instances
+	Store string
+	// Auth limits the public login, password and invitation routes per IP address
+	Auth RateLimitRule
+	// OAuth limits the OAuth and OpenID Connect endpoints per IP address
+	OAuth RateLimitRule
+	// API limits the authenticated API per user or API key
+	API RateLimitRule
+}
+
+func NewRateLimitConfig() *RateLimitConfig {
+	return &RateLimitConfig{
+		Store: getEnv("RATE_LIMIT_STORE", RateLimitStoreMemory),
+		Auth:  getEnvRateLimitRule("RATE_LIMIT_AUTH", 30, time.Minute),
+		OAuth: getEnvRateLimitRule("RATE_LIMIT_OAUTH", 120, time.Minute),
+		API:   getEnvRateLimitRule("RATE_LIMIT_API", 600, time.Minute),
+	}
+}
+
+// getEnvRateLimitRule reads the rule from <prefix>_REQUESTS and <prefix>_WINDOW
+func getEnvRateLimitRule(prefix string, requests int, window time.Duration) RateLimitRule {
+	return RateLimitRule{
+		Requests: getEnvInt(prefix+"_REQUESTS", requests),
+		Window:   getEnvDuration(prefix+"_WINDOW", window),
+	}
+}
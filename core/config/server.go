@@ -0,0 +1,14 @@
+package config
+
+type ServerConfig struct {
+	// TrustedProxies are the addresses or CIDR ranges of the reverse proxies whose X-Forwarded-For and
+	// X-Real-IP headers are believed. With none, the client IP is the address of the connection, since any
+	// client can set those headers; lockouts, rate limits and audit events all key on that IP.
+	TrustedProxies []string
+}
+
+func NewServerConfig() *ServerConfig {
+	return &ServerConfig{
+		TrustedProxies: getEnvList("TRUSTED_PROXIES", nil),
+	}
+}
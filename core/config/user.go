@@ -0,0 +1,19 @@
+package config
+
+import (
+	"time"
+)
+
+type UserConfig struct {
+	// DeletedRetention is how long deleted users can be restored before they are purged for good
+	DeletedRetention time.Duration
+	// PurgeInterval is how often deleted users past the retention are purged
+	PurgeInterval time.Duration
+}
+
+func NewUserConfig() *UserConfig {
+	return &UserConfig{
+		DeletedRetention: getEnvDuration("USER_DELETED_RETENTION", 30*24*time.Hour),
+		PurgeInterval:    getEnvDuration("USER_PURGE_INTERVAL", time.Hour),
+	}
+}
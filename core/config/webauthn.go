@@ -0,0 +1,26 @@
+package config
+
+import (
+	"time"
+)
+
+type WebAuthnConfig struct {
+	// RPID is the relying party ID, the registrable domain passkeys are bound to
+	RPID   string
+	RPName string
+	// Origins lists the client origins allowed to run WebAuthn ceremonies
+	Origins      []string
+	ChallengeTTL time.Duration
+	// UserVerification is required or preferred
+	UserVerification string
+}
+
+func NewWebAuthnConfig() *WebAuthnConfig {
+	return &WebAuthnConfig{
+		RPID:             getEnv("WEBAUTHN_RP_ID", "localhost"),
+		RPName:           getEnv("WEBAUTHN_RP_NAME", "Valos ID"),
+		Origins:          getEnvList("WEBAUTHN_ORIGINS", []string{"http://localhost:3000"}),
+		ChallengeTTL:     getEnvDuration("WEBAUTHN_CHALLENGE_TTL", 5*time.Minute),
+		UserVerification: getEnv("WEBAUTHN_USER_VERIFICATION", "preferred"),
+	}
+}
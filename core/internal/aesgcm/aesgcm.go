@@ -0,0 +1,62 @@
+// Package aesgcm seals values at rest with AES-256-GCM. Sealed values are the random nonce followed by
+// the ciphertext, base64 encoded. Callers bind each value to its row through the associated data.
+package aesgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+)
+
+var (
+	ErrInvalidKey = errors.New("aesgcm: key must be 32 bytes")
+	ErrTooShort   = errors.New("aesgcm: sealed value is too short")
+)
+
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+func New(key []byte) (*Cipher, error) {
+	if len(key) != 32 {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Seal returns the base64 encoded nonce and ciphertext of plaintext, authenticated together with associatedData
+func (c *Cipher) Seal(plaintext, associatedData []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, plaintext, associatedData)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open reverses Seal. It fails unless associatedData matches the value given to Seal.
+func (c *Cipher) Open(encoded string, associatedData []byte) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, ErrTooShort
+	}
+
+	return c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], associatedData)
+}
@@ -0,0 +1,239 @@
+// Package cbor implements the subset of CBOR (RFC 8949) used by WebAuthn:
+// definite-length integers, byte and text strings, arrays, maps and simple values.
+package cbor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const maxDepth = 16
+
+var (
+	ErrUnexpectedEnd = errors.New("cbor: unexpected end of data")
+	ErrUnsupported   = errors.New("cbor: unsupported data item")
+)
+
+// Map is an ordered map used for encoding, so output is deterministic
+type Map []Pair
+
+type Pair struct {
+	Key   any
+	Value any
+}
+
+// Decode decodes the first data item in data and returns it together with the remaining bytes.
+// Integers decode to int64, byte strings to []byte, text to string, arrays to []any and maps to map[any]any.
+func Decode(data []byte) (any, []byte, error) {
+	return decode(data, 0)
+}
+
+func decode(data []byte, depth int) (any, []byte, error) {
+	if depth > maxDepth {
+		return nil, nil, errors.New("cbor: nesting too deep")
+	}
+	if len(data) == 0 {
+		return nil, nil, ErrUnexpectedEnd
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+
+	if major == 7 {
+		return decodeSimple(data, info)
+	}
+
+	arg, rest, err := readArgument(data[1:], info)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, nil, ErrUnsupported
+		}
+		return int64(arg), rest, nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, nil, ErrUnsupported
+		}
+		return -1 - int64(arg), rest, nil
+	case 2, 3:
+		if arg > uint64(len(rest)) {
+			return nil, nil, ErrUnexpectedEnd
+		}
+		b := rest[:arg]
+		if major == 3 {
+			return string(b), rest[arg:], nil
+		}
+		return append([]byte(nil), b...), rest[arg:], nil
+	case 4:
+		if arg > uint64(len(rest)) {
+			return nil, nil, ErrUnexpectedEnd
+		}
+		items := make([]any, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			var item any
+			item, rest, err = decode(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, rest, nil
+	case 5:
+		if arg > uint64(len(rest)) {
+			return nil, nil, ErrUnexpectedEnd
+		}
+		m := make(map[any]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			var key, value any
+			key, rest, err = decode(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, fmt.Errorf("cbor: unsupported map key type %T", key)
+			}
+			value, rest, err = decode(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			m[key] = value
+		}
+		return m, rest, nil
+	case 6:
+		// Tags carry no meaning for WebAuthn, decode the tagged item
+		return decode(rest, depth+1)
+	}
+	return nil, nil, ErrUnsupported
+}
+
+func readArgument(data []byte, info byte) (uint64, []byte, error) {
+	switch {
+	case info < 24:
+		return uint64(info), data, nil
+	case info == 24:
+		if len(data) < 1 {
+			return 0, nil, ErrUnexpectedEnd
+		}
+		return uint64(data[0]), data[1:], nil
+	case info == 25:
+		if len(data) < 2 {
+			return 0, nil, ErrUnexpectedEnd
+		}
+		return uint64(binary.BigEndian.Uint16(data)), data[2:], nil
+	case info == 26:
+		if len(data) < 4 {
+			return 0, nil, ErrUnexpectedEnd
+		}
+		return uint64(binary.BigEndian.Uint32(data)), data[4:], nil
+	case info == 27:
+		if len(data) < 8 {
+			return 0, nil, ErrUnexpectedEnd
+		}
+		return binary.BigEndian.Uint64(data), data[8:], nil
+	}
+	// Indefinite lengths are not used by authenticators
+	return 0, nil, ErrUnsupported
+}
+
+func decodeSimple(data []byte, info byte) (any, []byte, error) {
+	rest := data[1:]
+	switch info {
+	case 20:
+		return false, rest, nil
+	case 21:
+		return true, rest, nil
+	case 22, 23:
+		return nil, rest, nil
+	case 26:
+		if len(rest) < 4 {
+			return nil, nil, ErrUnexpectedEnd
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(rest))), rest[4:], nil
+	case 27:
+		if len(rest) < 8 {
+			return nil, nil, ErrUnexpectedEnd
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(rest)), rest[8:], nil
+	}
+	return nil, nil, ErrUnsupported
+}
+
+// Encode encodes v, which may be an integer, []byte, string, bool, nil, []any or Map
+func Encode(v any) ([]byte, error) {
+	return appendValue(nil, v)
+}
+
+func appendValue(buf []byte, v any) ([]byte, error) {
+	var err error
+	switch v := v.(type) {
+	case nil:
+		return append(buf, 0xf6), nil
+	case bool:
+		if v {
+			return append(buf, 0xf5), nil
+		}
+		return append(buf, 0xf4), nil
+	case int:
+		return appendInt(buf, int64(v)), nil
+	case int64:
+		return appendInt(buf, v), nil
+	case uint64:
+		return appendHead(buf, 0, v), nil
+	case []byte:
+		buf = appendHead(buf, 2, uint64(len(v)))
+		return append(buf, v...), nil
+	case string:
+		buf = appendHead(buf, 3, uint64(len(v)))
+		return append(buf, v...), nil
+	case []any:
+		buf = appendHead(buf, 4, uint64(len(v)))
+		for _, item := range v {
+			if buf, err = appendValue(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case Map:
+		buf = appendHead(buf, 5, uint64(len(v)))
+		for _, pair := range v {
+			if buf, err = appendValue(buf, pair.Key); err != nil {
+				return nil, err
+			}
+			if buf, err = appendValue(buf, pair.Value); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+	return nil, fmt.Errorf("cbor: cannot encode %T", v)
+}
+
+func appendInt(buf []byte, v int64) []byte {
+	if v < 0 {
+		return appendHead(buf, 1, uint64(-1-v))
+	}
+	return appendHead(buf, 0, uint64(v))
+}
+
+func appendHead(buf []byte, major byte, arg uint64) []byte {
+	m := major << 5
+	switch {
+	case arg < 24:
+		return append(buf, m|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(buf, m|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, m|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, m|26), uint32(arg))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, m|27), arg)
+}
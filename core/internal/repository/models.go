@@ -8,11 +8,269 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type User struct {
+type ApiKey struct {
+	ID         int32       `json:"id"`
+	UserID     int32       `json:"user_id"`
+	Name       string      `json:"name"`
+	Prefix     string      `json:"prefix"`
+	KeyHash    string      `json:"key_hash"`
+	Scopes     []string    `json:"scopes"`
+	ExpiresAt  pgtype.Int8 `json:"expires_at"`
+	LastUsedAt pgtype.Int8 `json:"last_used_at"`
+	LastUsedIp pgtype.Text `json:"last_used_ip"`
+	RevokedAt  pgtype.Int8 `json:"revoked_at"`
+	CreatedAt  pgtype.Int8 `json:"created_at"`
+}
+
+type AuditCheckpoint struct {
+	ID        int64  `json:"id"`
+	EventID   int64  `json:"event_id"`
+	EventHash string `json:"event_hash"`
+	Signature string `json:"signature"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type AuditEvent struct {
+	ID            int64       `json:"id"`
+	ActorID       pgtype.Int4 `json:"actor_id"`
+	ActorApiKeyID pgtype.Int4 `json:"actor_api_key_id"`
+	Action        string      `json:"action"`
+	TargetType    string      `json:"target_type"`
+	TargetID      string      `json:"target_id"`
+	RequestID     pgtype.Text `json:"request_id"`
+	IpAddress     pgtype.Text `json:"ip_address"`
+	UserAgent     pgtype.Text `json:"user_agent"`
+	Changes       []byte      `json:"changes"`
+	CreatedAt     int64       `json:"created_at"`
+	PrevHash      pgtype.Text `json:"prev_hash"`
+	Hash          pgtype.Text `json:"hash"`
+}
+
+type EmailVerificationToken struct {
 	ID        int32       `json:"id"`
-	Username  string      `json:"username"`
+	UserID    int32       `json:"user_id"`
 	Email     string      `json:"email"`
-	Password  string      `json:"password"`
+	TokenHash string      `json:"token_hash"`
+	ExpiresAt int64       `json:"expires_at"`
+	UsedAt    pgtype.Int8 `json:"used_at"`
+	CreatedAt pgtype.Int8 `json:"created_at"`
+}
+
+type LoginAttempt struct {
+	Subject       string      `json:"subject"`
+	Failures      int32       `json:"failures"`
+	LastFailureAt int64       `json:"last_failure_at"`
+	BlockedUntil  pgtype.Int8 `json:"blocked_until"`
+}
+
+type MfaRecoveryCode struct {
+	ID        int32       `json:"id"`
+	UserID    int32       `json:"user_id"`
+	CodeHash  string      `json:"code_hash"`
+	UsedAt    pgtype.Int8 `json:"used_at"`
+	CreatedAt pgtype.Int8 `json:"created_at"`
+}
+
+type OauthAuthorizationCode struct {
+	ID            int32       `json:"id"`
+	CodeHash      string      `json:"code_hash"`
+	ClientID      string      `json:"client_id"`
+	UserID        int32       `json:"user_id"`
+	RedirectUri   string      `json:"redirect_uri"`
+	Scope         string      `json:"scope"`
+	CodeChallenge string      `json:"code_challenge"`
+	FamilyID      string      `json:"family_id"`
+	ExpiresAt     int64       `json:"expires_at"`
+	UsedAt        pgtype.Int8 `json:"used_at"`
+	CreatedAt     pgtype.Int8 `json:"created_at"`
+	Nonce         string      `json:"nonce"`
+}
+
+type OauthClient struct {
+	ID               int32       `json:"id"`
+	ClientID         string      `json:"client_id"`
+	ClientSecretHash pgtype.Text `json:"client_secret_hash"`
+	Name             string      `json:"name"`
+	ClientType       string      `json:"client_type"`
+	RedirectUris     []string    `json:"redirect_uris"`
+	GrantTypes       []string    `json:"grant_types"`
+	Scopes           []string    `json:"scopes"`
+	OwnerID          pgtype.Int4 `json:"owner_id"`
+	CreatedAt        pgtype.Int8 `json:"created_at"`
+	UpdatedAt        pgtype.Int8 `json:"updated_at"`
+}
+
+type OauthRefreshToken struct {
+	ID                int32       `json:"id"`
+	TokenHash         string      `json:"token_hash"`
+	FamilyID          string      `json:"family_id"`
+	ClientID          string      `json:"client_id"`
+	UserID            int32       `json:"user_id"`
+	Scope             string      `json:"scope"`
+	CredentialVersion int32       `json:"credential_version"`
+	ExpiresAt         int64       `json:"expires_at"`
+	UsedAt            pgtype.Int8 `json:"used_at"`
+	RevokedAt         pgtype.Int8 `json:"revoked_at"`
+	CreatedAt         pgtype.Int8 `json:"created_at"`
+}
+
+type OauthRevokedAccessToken struct {
+	Jti       string `json:"jti"`
+	ClientID  string `json:"client_id"`
+	ExpiresAt int64  `json:"expires_at"`
+	RevokedAt int64  `json:"revoked_at"`
+}
+
+type Organization struct {
+	ID        int32       `json:"id"`
+	Slug      string      `json:"slug"`
+	Name      string      `json:"name"`
 	CreatedAt pgtype.Int8 `json:"created_at"`
 	UpdatedAt pgtype.Int8 `json:"updated_at"`
 }
+
+type OrganizationInvitation struct {
+	ID             int32       `json:"id"`
+	OrganizationID int32       `json:"organization_id"`
+	Email          string      `json:"email"`
+	Role           string      `json:"role"`
+	TokenHash      string      `json:"token_hash"`
+	InvitedBy      pgtype.Int4 `json:"invited_by"`
+	Status         string      `json:"status"`
+	ExpiresAt      int64       `json:"expires_at"`
+	RespondedAt    pgtype.Int8 `json:"responded_at"`
+	CreatedAt      pgtype.Int8 `json:"created_at"`
+	UpdatedAt      pgtype.Int8 `json:"updated_at"`
+}
+
+type OrganizationMember struct {
+	OrganizationID int32       `json:"organization_id"`
+	UserID         int32       `json:"user_id"`
+	Role           string      `json:"role"`
+	CreatedAt      pgtype.Int8 `json:"created_at"`
+	UpdatedAt      pgtype.Int8 `json:"updated_at"`
+}
+
+type PasswordResetToken struct {
+	ID        int32       `json:"id"`
+	UserID    int32       `json:"user_id"`
+	TokenHash string      `json:"token_hash"`
+	ExpiresAt int64       `json:"expires_at"`
+	UsedAt    pgtype.Int8 `json:"used_at"`
+	CreatedAt pgtype.Int8 `json:"created_at"`
+}
+
+type Permission struct {
+	ID          int32       `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	CreatedAt   pgtype.Int8 `json:"created_at"`
+}
+
+type RateLimitCounter struct {
+	Key         string `json:"key"`
+	WindowStart int64  `json:"window_start"`
+	Count       int32  `json:"count"`
+	ExpiresAt   int64  `json:"expires_at"`
+}
+
+type RefreshToken struct {
+	ID        int32       `json:"id"`
+	UserID    int32       `json:"user_id"`
+	TokenHash string      `json:"token_hash"`
+	FamilyID  string      `json:"family_id"`
+	ExpiresAt int64       `json:"expires_at"`
+	UsedAt    pgtype.Int8 `json:"used_at"`
+	RevokedAt pgtype.Int8 `json:"revoked_at"`
+	CreatedAt pgtype.Int8 `json:"created_at"`
+}
+
+type Role struct {
+	ID          int32       `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	CreatedAt   pgtype.Int8 `json:"created_at"`
+	UpdatedAt   pgtype.Int8 `json:"updated_at"`
+}
+
+type RolePermission struct {
+	RoleID       int32 `json:"role_id"`
+	PermissionID int32 `json:"permission_id"`
+}
+
+type Session struct {
+	ID             string      `json:"id"`
+	UserID         int32       `json:"user_id"`
+	UserAgent      string      `json:"user_agent"`
+	IpAddress      string      `json:"ip_address"`
+	LastSeenAt     int64       `json:"last_seen_at"`
+	RevokedAt      pgtype.Int8 `json:"revoked_at"`
+	CreatedAt      pgtype.Int8 `json:"created_at"`
+	OrganizationID pgtype.Int4 `json:"organization_id"`
+}
+
+type SigningKey struct {
+	ID                  int32       `json:"id"`
+	Kid                 string      `json:"kid"`
+	Algorithm           string      `json:"algorithm"`
+	PrivateKeyEncrypted string      `json:"private_key_encrypted"`
+	PublicKey           []byte      `json:"public_key"`
+	Status              string      `json:"status"`
+	ActivatedAt         int64       `json:"activated_at"`
+	RetiredAt           pgtype.Int8 `json:"retired_at"`
+	ExpiresAt           pgtype.Int8 `json:"expires_at"`
+	CreatedAt           pgtype.Int8 `json:"created_at"`
+}
+
+type User struct {
+	ID                int32       `json:"id"`
+	Username          string      `json:"username"`
+	Email             pgtype.Text `json:"email"`
+	Password          pgtype.Text `json:"password"`
+	CreatedAt         pgtype.Int8 `json:"created_at"`
+	UpdatedAt         pgtype.Int8 `json:"updated_at"`
+	CredentialVersion int32       `json:"credential_version"`
+	EmailVerifiedAt   pgtype.Int8 `json:"email_verified_at"`
+	AccountType       string      `json:"account_type"`
+	DeletedAt         pgtype.Int8 `json:"deleted_at"`
+	Status            string      `json:"status"`
+	StatusReason      pgtype.Text `json:"status_reason"`
+	StatusChangedAt   pgtype.Int8 `json:"status_changed_at"`
+	StatusChangedBy   pgtype.Int4 `json:"status_changed_by"`
+}
+
+type UserRole struct {
+	UserID    int32       `json:"user_id"`
+	RoleID    int32       `json:"role_id"`
+	CreatedAt pgtype.Int8 `json:"created_at"`
+}
+
+type UserTotp struct {
+	UserID          int32       `json:"user_id"`
+	SecretEncrypted string      `json:"secret_encrypted"`
+	ConfirmedAt     pgtype.Int8 `json:"confirmed_at"`
+	LastUsedStep    int64       `json:"last_used_step"`
+	CreatedAt       pgtype.Int8 `json:"created_at"`
+}
+
+type WebauthnChallenge struct {
+	ID        string      `json:"id"`
+	UserID    pgtype.Int4 `json:"user_id"`
+	Ceremony  string      `json:"ceremony"`
+	Challenge []byte      `json:"challenge"`
+	ExpiresAt int64       `json:"expires_at"`
+	CreatedAt pgtype.Int8 `json:"created_at"`
+}
+
+type WebauthnCredential struct {
+	ID           int32       `json:"id"`
+	UserID       int32       `json:"user_id"`
+	CredentialID []byte      `json:"credential_id"`
+	PublicKey    []byte      `json:"public_key"`
+	SignCount    int64       `json:"sign_count"`
+	Aaguid       []byte      `json:"aaguid"`
+	Transports   string      `json:"transports"`
+	Name         string      `json:"name"`
+	LastUsedAt   pgtype.Int8 `json:"last_used_at"`
+	CreatedAt    pgtype.Int8 `json:"created_at"`
+}
@@ -6,19 +6,154 @@ package repository
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
-	CountUsers(ctx context.Context) (int64, error)
+	AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) error
+	AddRolePermissions(ctx context.Context, arg AddRolePermissionsParams) error
+	AssignUserRole(ctx context.Context, arg AssignUserRoleParams) (int64, error)
+	AssignUserRoleByName(ctx context.Context, arg AssignUserRoleByNameParams) error
+	BlockLoginSubject(ctx context.Context, arg BlockLoginSubjectParams) error
+	ClearSessionsOrganization(ctx context.Context, arg ClearSessionsOrganizationParams) error
+	ConfirmUserTOTP(ctx context.Context, arg ConfirmUserTOTPParams) (int64, error)
+	ConsumeEmailVerificationToken(ctx context.Context, arg ConsumeEmailVerificationTokenParams) (int64, error)
+	ConsumeOAuthAuthorizationCode(ctx context.Context, arg ConsumeOAuthAuthorizationCodeParams) (int64, error)
+	ConsumePasswordResetToken(ctx context.Context, arg ConsumePasswordResetTokenParams) (int64, error)
+	ConsumeRecoveryCode(ctx context.Context, arg ConsumeRecoveryCodeParams) (int64, error)
+	ConsumeWebAuthnChallenge(ctx context.Context, arg ConsumeWebAuthnChallengeParams) (WebauthnChallenge, error)
+	CountOrganizationOwners(ctx context.Context, organizationID int32) (int64, error)
+	CountOrganizationUsers(ctx context.Context, arg CountOrganizationUsersParams) (int64, error)
+	CountPermissionsByName(ctx context.Context, names []string) (int64, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID int32) (int64, error)
+	CountUsers(ctx context.Context, arg CountUsersParams) (int64, error)
+	CountUsersWithRole(ctx context.Context, roleID int32) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateAuditCheckpoint(ctx context.Context, arg CreateAuditCheckpointParams) (AuditCheckpoint, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error
+	CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) (EmailVerificationToken, error)
+	CreateOAuthAuthorizationCode(ctx context.Context, arg CreateOAuthAuthorizationCodeParams) error
+	CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OauthClient, error)
+	CreateOAuthRefreshToken(ctx context.Context, arg CreateOAuthRefreshTokenParams) error
+	CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error)
+	CreateOrganizationInvitation(ctx context.Context, arg CreateOrganizationInvitationParams) (OrganizationInvitation, error)
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error)
+	CreateRole(ctx context.Context, arg CreateRoleParams) (Role, error)
+	CreateServiceAccount(ctx context.Context, arg CreateServiceAccountParams) (User, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateSigningKey(ctx context.Context, arg CreateSigningKeyParams) (SigningKey, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteUser(ctx context.Context, id int32) error
-	GetAllUsers(ctx context.Context) ([]GetAllUsersRow, error)
-	GetUserByEmail(ctx context.Context, email string) (User, error)
+	CreateWebAuthnChallenge(ctx context.Context, arg CreateWebAuthnChallengeParams) error
+	CreateWebAuthnCredential(ctx context.Context, arg CreateWebAuthnCredentialParams) (WebauthnCredential, error)
+	DeleteExpiredOAuthRevokedAccessTokens(ctx context.Context, expiresAt int64) error
+	DeleteExpiredRateLimitCounters(ctx context.Context, expiresAt int64) (int64, error)
+	DeleteExpiredSigningKeys(ctx context.Context, expiresAt pgtype.Int8) (int64, error)
+	DeleteExpiredWebAuthnChallenges(ctx context.Context, expiresAt int64) error
+	DeleteLoginAttempt(ctx context.Context, subject string) error
+	DeleteOAuthClient(ctx context.Context, arg DeleteOAuthClientParams) (OauthClient, error)
+	DeleteOrganizationMember(ctx context.Context, arg DeleteOrganizationMemberParams) (int64, error)
+	DeleteRole(ctx context.Context, id int32) error
+	DeleteRolePermissions(ctx context.Context, roleID int32) error
+	DeleteStaleLoginAttempts(ctx context.Context, arg DeleteStaleLoginAttemptsParams) (int64, error)
+	DeleteUserRecoveryCodes(ctx context.Context, userID int32) error
+	DeleteUserTOTP(ctx context.Context, userID int32) error
+	DeleteWebAuthnCredential(ctx context.Context, arg DeleteWebAuthnCredentialParams) (int64, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (GetAPIKeyByHashRow, error)
+	GetActiveSigningKeyForUpdate(ctx context.Context) (SigningKey, error)
+	GetDeletedUserByIDForUpdate(ctx context.Context, id int32) (User, error)
+	GetEmailVerificationTokenByHash(ctx context.Context, tokenHash string) (EmailVerificationToken, error)
+	GetLastAuditEvent(ctx context.Context) (GetLastAuditEventRow, error)
+	GetLatestAuditCheckpoint(ctx context.Context) (AuditCheckpoint, error)
+	GetLoginAttempt(ctx context.Context, subject string) (LoginAttempt, error)
+	GetOAuthAuthorizationCodeByHash(ctx context.Context, codeHash string) (OauthAuthorizationCode, error)
+	GetOAuthClientByClientID(ctx context.Context, clientID string) (OauthClient, error)
+	GetOAuthRefreshTokenByHash(ctx context.Context, tokenHash string) (OauthRefreshToken, error)
+	GetOrganizationByID(ctx context.Context, id int32) (Organization, error)
+	GetOrganizationByIDForUpdate(ctx context.Context, id int32) (Organization, error)
+	GetOrganizationInvitationByID(ctx context.Context, id int32) (OrganizationInvitation, error)
+	GetOrganizationInvitationByTokenHash(ctx context.Context, tokenHash string) (OrganizationInvitation, error)
+	GetOrganizationMember(ctx context.Context, arg GetOrganizationMemberParams) (OrganizationMember, error)
+	GetOrganizationUsersWithPagination(ctx context.Context, arg GetOrganizationUsersWithPaginationParams) ([]GetOrganizationUsersWithPaginationRow, error)
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (PasswordResetToken, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error)
+	GetRoleByID(ctx context.Context, id int32) (Role, error)
+	GetRoleByIDForUpdate(ctx context.Context, id int32) (Role, error)
+	GetRoleByName(ctx context.Context, name string) (Role, error)
+	GetUserByEmail(ctx context.Context, email pgtype.Text) (User, error)
 	GetUserByID(ctx context.Context, id int32) (User, error)
+	GetUserByIDForUpdate(ctx context.Context, id int32) (User, error)
+	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetUserCredentialVersion(ctx context.Context, id int32) (int32, error)
+	GetUserTOTP(ctx context.Context, userID int32) (UserTotp, error)
 	GetUsersWithPagination(ctx context.Context, arg GetUsersWithPaginationParams) ([]GetUsersWithPaginationRow, error)
-	UpdatePassword(ctx context.Context, arg UpdatePasswordParams) error
-	UpdateUser(ctx context.Context, arg UpdateUserParams) error
-	UserExists(ctx context.Context, email string) (bool, error)
+	GetWebAuthnCredentialByCredentialID(ctx context.Context, credentialID []byte) (WebauthnCredential, error)
+	IncrementRateLimitCounter(ctx context.Context, arg IncrementRateLimitCounterParams) (IncrementRateLimitCounterRow, error)
+	InvalidateUserEmailVerificationTokens(ctx context.Context, arg InvalidateUserEmailVerificationTokensParams) error
+	InvalidateUserPasswordResetTokens(ctx context.Context, arg InvalidateUserPasswordResetTokensParams) error
+	IsOAuthAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	IsOAuthRefreshTokenFamilyRevoked(ctx context.Context, familyID string) (bool, error)
+	IsOrganizationMember(ctx context.Context, arg IsOrganizationMemberParams) (bool, error)
+	IsSessionActive(ctx context.Context, id string) (bool, error)
+	ListAPIKeysByUser(ctx context.Context, userID int32) ([]ApiKey, error)
+	ListActiveSessionsByUser(ctx context.Context, userID int32) ([]Session, error)
+	ListAuditCheckpoints(ctx context.Context) ([]AuditCheckpoint, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListAuditEventsAfter(ctx context.Context, arg ListAuditEventsAfterParams) ([]AuditEvent, error)
+	ListOAuthClientsByOwner(ctx context.Context, ownerID pgtype.Int4) ([]OauthClient, error)
+	ListOrganizationMembers(ctx context.Context, organizationID int32) ([]ListOrganizationMembersRow, error)
+	ListOrganizationUsers(ctx context.Context, arg ListOrganizationUsersParams) ([]ListOrganizationUsersRow, error)
+	ListOrganizationUsersBefore(ctx context.Context, arg ListOrganizationUsersBeforeParams) ([]ListOrganizationUsersBeforeRow, error)
+	ListOrganizationsByUser(ctx context.Context, userID int32) ([]ListOrganizationsByUserRow, error)
+	ListPendingOrganizationInvitations(ctx context.Context, organizationID int32) ([]OrganizationInvitation, error)
+	ListPermissions(ctx context.Context) ([]Permission, error)
+	ListPublishedSigningKeys(ctx context.Context, expiresAt pgtype.Int8) ([]SigningKey, error)
+	ListRolePermissionNames(ctx context.Context) ([]ListRolePermissionNamesRow, error)
+	ListRolePermissionNamesByRole(ctx context.Context, roleID int32) ([]string, error)
+	ListRoles(ctx context.Context) ([]Role, error)
+	ListServiceAccounts(ctx context.Context) ([]User, error)
+	ListUserPermissionNames(ctx context.Context, userID int32) ([]string, error)
+	ListUserRoles(ctx context.Context, userID int32) ([]Role, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error)
+	ListUsersBefore(ctx context.Context, arg ListUsersBeforeParams) ([]ListUsersBeforeRow, error)
+	ListWebAuthnCredentialsByUser(ctx context.Context, userID int32) ([]WebauthnCredential, error)
+	// Serializes appends to the hash chain until the end of the transaction
+	LockAuditChain(ctx context.Context) error
+	MarkOAuthRefreshTokenUsed(ctx context.Context, arg MarkOAuthRefreshTokenUsedParams) (int64, error)
+	MarkRefreshTokenUsed(ctx context.Context, arg MarkRefreshTokenUsedParams) (int64, error)
+	PurgeDeletedUsers(ctx context.Context, deletedAt pgtype.Int8) ([]int32, error)
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (int32, error)
+	RemoveUserRole(ctx context.Context, arg RemoveUserRoleParams) (int64, error)
+	RenewOrganizationInvitation(ctx context.Context, arg RenewOrganizationInvitationParams) (int64, error)
+	RestoreUser(ctx context.Context, arg RestoreUserParams) (User, error)
+	RetireSigningKey(ctx context.Context, arg RetireSigningKeyParams) error
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error)
+	RevokeOAuthAccessToken(ctx context.Context, arg RevokeOAuthAccessTokenParams) error
+	RevokeOAuthRefreshTokenFamily(ctx context.Context, arg RevokeOAuthRefreshTokenFamilyParams) error
+	RevokeOtherUserRefreshTokens(ctx context.Context, arg RevokeOtherUserRefreshTokensParams) error
+	RevokeOtherUserSessions(ctx context.Context, arg RevokeOtherUserSessionsParams) ([]string, error)
+	RevokeRefreshTokenFamily(ctx context.Context, arg RevokeRefreshTokenFamilyParams) error
+	RevokeSession(ctx context.Context, arg RevokeSessionParams) (int64, error)
+	RevokeSessionByID(ctx context.Context, arg RevokeSessionByIDParams) error
+	RevokeUserRefreshTokens(ctx context.Context, arg RevokeUserRefreshTokensParams) error
+	RevokeUserSessions(ctx context.Context, arg RevokeUserSessionsParams) ([]string, error)
+	SetOrganizationInvitationStatus(ctx context.Context, arg SetOrganizationInvitationStatusParams) (int64, error)
+	SetSessionOrganization(ctx context.Context, arg SetSessionOrganizationParams) (int64, error)
+	SetUserEmailVerified(ctx context.Context, arg SetUserEmailVerifiedParams) (int64, error)
+	SetUserStatus(ctx context.Context, arg SetUserStatusParams) (User, error)
+	SoftDeleteUser(ctx context.Context, arg SoftDeleteUserParams) (User, error)
+	TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error
+	TouchSession(ctx context.Context, arg TouchSessionParams) (Session, error)
+	UpdateOrganizationMemberRole(ctx context.Context, arg UpdateOrganizationMemberRoleParams) (int64, error)
+	UpdatePassword(ctx context.Context, arg UpdatePasswordParams) (int32, error)
+	UpdateRole(ctx context.Context, arg UpdateRoleParams) error
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateWebAuthnCredentialSignCount(ctx context.Context, arg UpdateWebAuthnCredentialSignCountParams) (int64, error)
+	UpsertUserTOTP(ctx context.Context, arg UpsertUserTOTPParams) error
+	UseUserTOTPStep(ctx context.Context, arg UseUserTOTPStepParams) (int64, error)
+	UserExists(ctx context.Context, email pgtype.Text) (bool, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -11,27 +11,91 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countOrganizationUsers = `-- name: CountOrganizationUsers :one
+SELECT COUNT(*)
+FROM organization_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.organization_id = $1 AND u.deleted_at IS NULL
+  AND ($2::text IS NULL OR u.status = $2)
+`
+
+type CountOrganizationUsersParams struct {
+	OrganizationID int32       `json:"organization_id"`
+	Status         pgtype.Text `json:"status"`
+}
+
+func (q *Queries) CountOrganizationUsers(ctx context.Context, arg CountOrganizationUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countOrganizationUsers, arg.OrganizationID, arg.Status)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countUsers = `-- name: CountUsers :one
 SELECT COUNT(*) FROM users
+WHERE (account_type = 'user' OR $1::boolean)
+  AND (deleted_at IS NULL OR $2::boolean)
+  AND ($3::text IS NULL OR status = $3)
 `
 
-func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, countUsers)
+type CountUsersParams struct {
+	IncludeServiceAccounts bool        `json:"include_service_accounts"`
+	IncludeDeleted         bool        `json:"include_deleted"`
+	Status                 pgtype.Text `json:"status"`
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers, arg.IncludeServiceAccounts, arg.IncludeDeleted, arg.Status)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
+const createServiceAccount = `-- name: CreateServiceAccount :one
+INSERT INTO users (username, account_type, created_at, updated_at)
+VALUES ($1, 'service', $2, $3)
+RETURNING id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
+`
+
+type CreateServiceAccountParams struct {
+	Username  string      `json:"username"`
+	CreatedAt pgtype.Int8 `json:"created_at"`
+	UpdatedAt pgtype.Int8 `json:"updated_at"`
+}
+
+func (q *Queries) CreateServiceAccount(ctx context.Context, arg CreateServiceAccountParams) (User, error) {
+	row := q.db.QueryRow(ctx, createServiceAccount, arg.Username, arg.CreatedAt, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CredentialVersion,
+		&i.EmailVerifiedAt,
+		&i.AccountType,
+		&i.DeletedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (username, email, password, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, username, email, password, created_at, updated_at
+INSERT INTO users (username, email, password, status, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
 `
 
 type CreateUserParams struct {
 	Username  string      `json:"username"`
-	Email     string      `json:"email"`
-	Password  string      `json:"password"`
+	Email     pgtype.Text `json:"email"`
+	Password  pgtype.Text `json:"password"`
+	Status    string      `json:"status"`
 	CreatedAt pgtype.Int8 `json:"created_at"`
 	UpdatedAt pgtype.Int8 `json:"updated_at"`
 }
@@ -41,6 +105,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Username,
 		arg.Email,
 		arg.Password,
+		arg.Status,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 	)
@@ -52,48 +117,98 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CredentialVersion,
+		&i.EmailVerifiedAt,
+		&i.AccountType,
+		&i.DeletedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
 	)
 	return i, err
 }
 
-const deleteUser = `-- name: DeleteUser :exec
-DELETE FROM users WHERE id = $1
+const getDeletedUserByIDForUpdate = `-- name: GetDeletedUserByIDForUpdate :one
+SELECT id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
+FROM users
+WHERE id = $1 AND deleted_at IS NOT NULL
+FOR UPDATE
 `
 
-func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
-	_, err := q.db.Exec(ctx, deleteUser, id)
-	return err
+func (q *Queries) GetDeletedUserByIDForUpdate(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRow(ctx, getDeletedUserByIDForUpdate, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CredentialVersion,
+		&i.EmailVerifiedAt,
+		&i.AccountType,
+		&i.DeletedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+	)
+	return i, err
 }
 
-const getAllUsers = `-- name: GetAllUsers :many
-SELECT id, username, email, created_at, updated_at
-FROM users
-ORDER BY created_at DESC
+const getOrganizationUsersWithPagination = `-- name: GetOrganizationUsersWithPagination :many
+SELECT u.id, u.username, u.email, u.created_at, u.updated_at, u.email_verified_at, u.account_type, u.status
+FROM users u
+JOIN organization_members m ON m.user_id = u.id
+WHERE m.organization_id = $1 AND u.deleted_at IS NULL
+  AND ($2::text IS NULL OR u.status = $2)
+ORDER BY u.created_at DESC
+LIMIT $3 OFFSET $4
 `
 
-type GetAllUsersRow struct {
-	ID        int32       `json:"id"`
-	Username  string      `json:"username"`
-	Email     string      `json:"email"`
-	CreatedAt pgtype.Int8 `json:"created_at"`
-	UpdatedAt pgtype.Int8 `json:"updated_at"`
+type GetOrganizationUsersWithPaginationParams struct {
+	OrganizationID int32       `json:"organization_id"`
+	Status         pgtype.Text `json:"status"`
+	Limit          int32       `json:"limit"`
+	Offset         int32       `json:"offset"`
+}
+
+type GetOrganizationUsersWithPaginationRow struct {
+	ID              int32       `json:"id"`
+	Username        string      `json:"username"`
+	Email           pgtype.Text `json:"email"`
+	CreatedAt       pgtype.Int8 `json:"created_at"`
+	UpdatedAt       pgtype.Int8 `json:"updated_at"`
+	EmailVerifiedAt pgtype.Int8 `json:"email_verified_at"`
+	AccountType     string      `json:"account_type"`
+	Status          string      `json:"status"`
 }
 
-func (q *Queries) GetAllUsers(ctx context.Context) ([]GetAllUsersRow, error) {
-	rows, err := q.db.Query(ctx, getAllUsers)
+func (q *Queries) GetOrganizationUsersWithPagination(ctx context.Context, arg GetOrganizationUsersWithPaginationParams) ([]GetOrganizationUsersWithPaginationRow, error) {
+	rows, err := q.db.Query(ctx, getOrganizationUsersWithPagination,
+		arg.OrganizationID,
+		arg.Status,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetAllUsersRow{}
+	items := []GetOrganizationUsersWithPaginationRow{}
 	for rows.Next() {
-		var i GetAllUsersRow
+		var i GetOrganizationUsersWithPaginationRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Username,
 			&i.Email,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.EmailVerifiedAt,
+			&i.AccountType,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -106,12 +221,12 @@ func (q *Queries) GetAllUsers(ctx context.Context) ([]GetAllUsersRow, error) {
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, password, created_at, updated_at
+SELECT id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
 FROM users
-WHERE email = $1
+WHERE email = $1 AND deleted_at IS NULL
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+func (q *Queries) GetUserByEmail(ctx context.Context, email pgtype.Text) (User, error) {
 	row := q.db.QueryRow(ctx, getUserByEmail, email)
 	var i User
 	err := row.Scan(
@@ -121,14 +236,22 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CredentialVersion,
+		&i.EmailVerifiedAt,
+		&i.AccountType,
+		&i.DeletedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, email, password, created_at, updated_at
+SELECT id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
 FROM users
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
@@ -141,32 +264,124 @@ func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
 		&i.Password,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CredentialVersion,
+		&i.EmailVerifiedAt,
+		&i.AccountType,
+		&i.DeletedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+	)
+	return i, err
+}
+
+const getUserByIDForUpdate = `-- name: GetUserByIDForUpdate :one
+SELECT id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
+FROM users
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE
+`
+
+func (q *Queries) GetUserByIDForUpdate(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByIDForUpdate, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CredentialVersion,
+		&i.EmailVerifiedAt,
+		&i.AccountType,
+		&i.DeletedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
+FROM users
+WHERE username = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CredentialVersion,
+		&i.EmailVerifiedAt,
+		&i.AccountType,
+		&i.DeletedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
 	)
 	return i, err
 }
 
+const getUserCredentialVersion = `-- name: GetUserCredentialVersion :one
+SELECT credential_version FROM users WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) GetUserCredentialVersion(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, getUserCredentialVersion, id)
+	var credential_version int32
+	err := row.Scan(&credential_version)
+	return credential_version, err
+}
+
 const getUsersWithPagination = `-- name: GetUsersWithPagination :many
-SELECT id, username, email, created_at, updated_at
+SELECT id, username, email, created_at, updated_at, email_verified_at, account_type, deleted_at, status
 FROM users
+WHERE (account_type = 'user' OR $1::boolean)
+  AND (deleted_at IS NULL OR $2::boolean)
+  AND ($3::text IS NULL OR status = $3)
 ORDER BY created_at DESC
-LIMIT $1 OFFSET $2
+LIMIT $4 OFFSET $5
 `
 
 type GetUsersWithPaginationParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	IncludeServiceAccounts bool        `json:"include_service_accounts"`
+	IncludeDeleted         bool        `json:"include_deleted"`
+	Status                 pgtype.Text `json:"status"`
+	Limit                  int32       `json:"limit"`
+	Offset                 int32       `json:"offset"`
 }
 
 type GetUsersWithPaginationRow struct {
-	ID        int32       `json:"id"`
-	Username  string      `json:"username"`
-	Email     string      `json:"email"`
-	CreatedAt pgtype.Int8 `json:"created_at"`
-	UpdatedAt pgtype.Int8 `json:"updated_at"`
+	ID              int32       `json:"id"`
+	Username        string      `json:"username"`
+	Email           pgtype.Text `json:"email"`
+	CreatedAt       pgtype.Int8 `json:"created_at"`
+	UpdatedAt       pgtype.Int8 `json:"updated_at"`
+	EmailVerifiedAt pgtype.Int8 `json:"email_verified_at"`
+	AccountType     string      `json:"account_type"`
+	DeletedAt       pgtype.Int8 `json:"deleted_at"`
+	Status          string      `json:"status"`
 }
 
 func (q *Queries) GetUsersWithPagination(ctx context.Context, arg GetUsersWithPaginationParams) ([]GetUsersWithPaginationRow, error) {
-	rows, err := q.db.Query(ctx, getUsersWithPagination, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, getUsersWithPagination,
+		arg.IncludeServiceAccounts,
+		arg.IncludeDeleted,
+		arg.Status,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -180,6 +395,251 @@ func (q *Queries) GetUsersWithPagination(ctx context.Context, arg GetUsersWithPa
 			&i.Email,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.EmailVerifiedAt,
+			&i.AccountType,
+			&i.DeletedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganizationUsers = `-- name: ListOrganizationUsers :many
+SELECT u.id, u.username, u.email, u.created_at, u.updated_at, u.email_verified_at, u.account_type, u.status
+FROM users u
+JOIN organization_members m ON m.user_id = u.id
+WHERE m.organization_id = $1 AND u.deleted_at IS NULL
+  AND ($2::text IS NULL OR u.status = $2)
+  AND ($3::int8 IS NULL OR (u.created_at, u.id) < ($3::int8, $4::int4))
+ORDER BY u.created_at DESC, u.id DESC
+LIMIT $5
+`
+
+type ListOrganizationUsersParams struct {
+	OrganizationID int32       `json:"organization_id"`
+	Status         pgtype.Text `json:"status"`
+	AfterCreatedAt pgtype.Int8 `json:"after_created_at"`
+	AfterID        pgtype.Int4 `json:"after_id"`
+	Limit          int32       `json:"limit"`
+}
+
+type ListOrganizationUsersRow struct {
+	ID              int32       `json:"id"`
+	Username        string      `json:"username"`
+	Email           pgtype.Text `json:"email"`
+	CreatedAt       pgtype.Int8 `json:"created_at"`
+	UpdatedAt       pgtype.Int8 `json:"updated_at"`
+	EmailVerifiedAt pgtype.Int8 `json:"email_verified_at"`
+	AccountType     string      `json:"account_type"`
+	Status          string      `json:"status"`
+}
+
+func (q *Queries) ListOrganizationUsers(ctx context.Context, arg ListOrganizationUsersParams) ([]ListOrganizationUsersRow, error) {
+	rows, err := q.db.Query(ctx, listOrganizationUsers,
+		arg.OrganizationID,
+		arg.Status,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOrganizationUsersRow{}
+	for rows.Next() {
+		var i ListOrganizationUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmailVerifiedAt,
+			&i.AccountType,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrganizationUsersBefore = `-- name: ListOrganizationUsersBefore :many
+SELECT u.id, u.username, u.email, u.created_at, u.updated_at, u.email_verified_at, u.account_type, u.status
+FROM users u
+JOIN organization_members m ON m.user_id = u.id
+WHERE m.organization_id = $1 AND u.deleted_at IS NULL
+  AND ($2::text IS NULL OR u.status = $2)
+  AND (u.created_at, u.id) > ($3::int8, $4::int4)
+ORDER BY u.created_at ASC, u.id ASC
+LIMIT $5
+`
+
+type ListOrganizationUsersBeforeParams struct {
+	OrganizationID  int32       `json:"organization_id"`
+	Status          pgtype.Text `json:"status"`
+	BeforeCreatedAt int64       `json:"before_created_at"`
+	BeforeID        int32       `json:"before_id"`
+	Limit           int32       `json:"limit"`
+}
+
+type ListOrganizationUsersBeforeRow struct {
+	ID              int32       `json:"id"`
+	Username        string      `json:"username"`
+	Email           pgtype.Text `json:"email"`
+	CreatedAt       pgtype.Int8 `json:"created_at"`
+	UpdatedAt       pgtype.Int8 `json:"updated_at"`
+	EmailVerifiedAt pgtype.Int8 `json:"email_verified_at"`
+	AccountType     string      `json:"account_type"`
+	Status          string      `json:"status"`
+}
+
+func (q *Queries) ListOrganizationUsersBefore(ctx context.Context, arg ListOrganizationUsersBeforeParams) ([]ListOrganizationUsersBeforeRow, error) {
+	rows, err := q.db.Query(ctx, listOrganizationUsersBefore,
+		arg.OrganizationID,
+		arg.Status,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOrganizationUsersBeforeRow{}
+	for rows.Next() {
+		var i ListOrganizationUsersBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmailVerifiedAt,
+			&i.AccountType,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceAccounts = `-- name: ListServiceAccounts :many
+SELECT id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
+FROM users
+WHERE account_type = 'service' AND deleted_at IS NULL
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListServiceAccounts(ctx context.Context) ([]User, error) {
+	rows, err := q.db.Query(ctx, listServiceAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.Password,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CredentialVersion,
+			&i.EmailVerifiedAt,
+			&i.AccountType,
+			&i.DeletedAt,
+			&i.Status,
+			&i.StatusReason,
+			&i.StatusChangedAt,
+			&i.StatusChangedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, username, email, created_at, updated_at, email_verified_at, account_type, deleted_at, status
+FROM users
+WHERE (account_type = 'user' OR $1::boolean)
+  AND (deleted_at IS NULL OR $2::boolean)
+  AND ($3::text IS NULL OR status = $3)
+  AND ($4::int8 IS NULL OR (created_at, id) < ($4::int8, $5::int4))
+ORDER BY created_at DESC, id DESC
+LIMIT $6
+`
+
+type ListUsersParams struct {
+	IncludeServiceAccounts bool        `json:"include_service_accounts"`
+	IncludeDeleted         bool        `json:"include_deleted"`
+	Status                 pgtype.Text `json:"status"`
+	AfterCreatedAt         pgtype.Int8 `json:"after_created_at"`
+	AfterID                pgtype.Int4 `json:"after_id"`
+	Limit                  int32       `json:"limit"`
+}
+
+type ListUsersRow struct {
+	ID              int32       `json:"id"`
+	Username        string      `json:"username"`
+	Email           pgtype.Text `json:"email"`
+	CreatedAt       pgtype.Int8 `json:"created_at"`
+	UpdatedAt       pgtype.Int8 `json:"updated_at"`
+	EmailVerifiedAt pgtype.Int8 `json:"email_verified_at"`
+	AccountType     string      `json:"account_type"`
+	DeletedAt       pgtype.Int8 `json:"deleted_at"`
+	Status          string      `json:"status"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.IncludeServiceAccounts,
+		arg.IncludeDeleted,
+		arg.Status,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersRow{}
+	for rows.Next() {
+		var i ListUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmailVerifiedAt,
+			&i.AccountType,
+			&i.DeletedAt,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -191,51 +651,300 @@ func (q *Queries) GetUsersWithPagination(ctx context.Context, arg GetUsersWithPa
 	return items, nil
 }
 
-const updatePassword = `-- name: UpdatePassword :exec
+const listUsersBefore = `-- name: ListUsersBefore :many
+SELECT id, username, email, created_at, updated_at, email_verified_at, account_type, deleted_at, status
+FROM users
+WHERE (account_type = 'user' OR $1::boolean)
+  AND (deleted_at IS NULL OR $2::boolean)
+  AND ($3::text IS NULL OR status = $3)
+  AND (created_at, id) > ($4::int8, $5::int4)
+ORDER BY created_at ASC, id ASC
+LIMIT $6
+`
+
+type ListUsersBeforeParams struct {
+	IncludeServiceAccounts bool        `json:"include_service_accounts"`
+	IncludeDeleted         bool        `json:"include_deleted"`
+	Status                 pgtype.Text `json:"status"`
+	BeforeCreatedAt        int64       `json:"before_created_at"`
+	BeforeID               int32       `json:"before_id"`
+	Limit                  int32       `json:"limit"`
+}
+
+type ListUsersBeforeRow struct {
+	ID              int32       `json:"id"`
+	Username        string      `json:"username"`
+	Email           pgtype.Text `json:"email"`
+	CreatedAt       pgtype.Int8 `json:"created_at"`
+	UpdatedAt       pgtype.Int8 `json:"updated_at"`
+	EmailVerifiedAt pgtype.Int8 `json:"email_verified_at"`
+	AccountType     string      `json:"account_type"`
+	DeletedAt       pgtype.Int8 `json:"deleted_at"`
+	Status          string      `json:"status"`
+}
+
+func (q *Queries) ListUsersBefore(ctx context.Context, arg ListUsersBeforeParams) ([]ListUsersBeforeRow, error) {
+	rows, err := q.db.Query(ctx, listUsersBefore,
+		arg.IncludeServiceAccounts,
+		arg.IncludeDeleted,
+		arg.Status,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersBeforeRow{}
+	for rows.Next() {
+		var i ListUsersBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmailVerifiedAt,
+			&i.AccountType,
+			&i.DeletedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :many
+DELETE FROM users
+WHERE deleted_at < $1
+RETURNING id
+`
+
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, deletedAt pgtype.Int8) ([]int32, error) {
+	rows, err := q.db.Query(ctx, purgeDeletedUsers, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int32{}
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreUser = `-- name: RestoreUser :one
 UPDATE users
-SET password = $2, updated_at = $3
-WHERE id = $1
+SET deleted_at = NULL, updated_at = $2
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
+`
+
+type RestoreUserParams struct {
+	ID        int32       `json:"id"`
+	UpdatedAt pgtype.Int8 `json:"updated_at"`
+}
+
+func (q *Queries) RestoreUser(ctx context.Context, arg RestoreUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, restoreUser, arg.ID, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CredentialVersion,
+		&i.EmailVerifiedAt,
+		&i.AccountType,
+		&i.DeletedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+	)
+	return i, err
+}
+
+const setUserEmailVerified = `-- name: SetUserEmailVerified :execrows
+UPDATE users
+SET email_verified_at = $2
+WHERE id = $1 AND email = $3 AND deleted_at IS NULL
+`
+
+type SetUserEmailVerifiedParams struct {
+	ID              int32       `json:"id"`
+	EmailVerifiedAt pgtype.Int8 `json:"email_verified_at"`
+	Email           pgtype.Text `json:"email"`
+}
+
+func (q *Queries) SetUserEmailVerified(ctx context.Context, arg SetUserEmailVerifiedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setUserEmailVerified, arg.ID, arg.EmailVerifiedAt, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setUserStatus = `-- name: SetUserStatus :one
+UPDATE users
+SET status = $2, status_reason = $3, status_changed_at = $4, status_changed_by = $5, updated_at = $4,
+    credential_version = CASE WHEN status = 'active' THEN credential_version + 1 ELSE credential_version END
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
+`
+
+type SetUserStatusParams struct {
+	ID              int32       `json:"id"`
+	Status          string      `json:"status"`
+	StatusReason    pgtype.Text `json:"status_reason"`
+	StatusChangedAt pgtype.Int8 `json:"status_changed_at"`
+	StatusChangedBy pgtype.Int4 `json:"status_changed_by"`
+}
+
+func (q *Queries) SetUserStatus(ctx context.Context, arg SetUserStatusParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserStatus,
+		arg.ID,
+		arg.Status,
+		arg.StatusReason,
+		arg.StatusChangedAt,
+		arg.StatusChangedBy,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CredentialVersion,
+		&i.EmailVerifiedAt,
+		&i.AccountType,
+		&i.DeletedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+	)
+	return i, err
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :one
+UPDATE users
+SET deleted_at = $2, updated_at = $2, credential_version = credential_version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
+`
+
+type SoftDeleteUserParams struct {
+	ID        int32       `json:"id"`
+	DeletedAt pgtype.Int8 `json:"deleted_at"`
+}
+
+func (q *Queries) SoftDeleteUser(ctx context.Context, arg SoftDeleteUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, softDeleteUser, arg.ID, arg.DeletedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CredentialVersion,
+		&i.EmailVerifiedAt,
+		&i.AccountType,
+		&i.DeletedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+	)
+	return i, err
+}
+
+const updatePassword = `-- name: UpdatePassword :one
+UPDATE users
+SET password = $2, updated_at = $3, credential_version = credential_version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING credential_version
 `
 
 type UpdatePasswordParams struct {
 	ID        int32       `json:"id"`
-	Password  string      `json:"password"`
+	Password  pgtype.Text `json:"password"`
 	UpdatedAt pgtype.Int8 `json:"updated_at"`
 }
 
-func (q *Queries) UpdatePassword(ctx context.Context, arg UpdatePasswordParams) error {
-	_, err := q.db.Exec(ctx, updatePassword, arg.ID, arg.Password, arg.UpdatedAt)
-	return err
+func (q *Queries) UpdatePassword(ctx context.Context, arg UpdatePasswordParams) (int32, error) {
+	row := q.db.QueryRow(ctx, updatePassword, arg.ID, arg.Password, arg.UpdatedAt)
+	var credential_version int32
+	err := row.Scan(&credential_version)
+	return credential_version, err
 }
 
-const updateUser = `-- name: UpdateUser :exec
+const updateUser = `-- name: UpdateUser :one
 UPDATE users
-SET username = $2, email = $3, updated_at = $4
-WHERE id = $1
+SET username = $2, email = $3, updated_at = $4,
+    email_verified_at = CASE WHEN email = $3 THEN email_verified_at ELSE NULL END
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
 `
 
 type UpdateUserParams struct {
 	ID        int32       `json:"id"`
 	Username  string      `json:"username"`
-	Email     string      `json:"email"`
+	Email     pgtype.Text `json:"email"`
 	UpdatedAt pgtype.Int8 `json:"updated_at"`
 }
 
-func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
-	_, err := q.db.Exec(ctx, updateUser,
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUser,
 		arg.ID,
 		arg.Username,
 		arg.Email,
 		arg.UpdatedAt,
 	)
-	return err
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CredentialVersion,
+		&i.EmailVerifiedAt,
+		&i.AccountType,
+		&i.DeletedAt,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedAt,
+		&i.StatusChangedBy,
+	)
+	return i, err
 }
 
 const userExists = `-- name: UserExists :one
-SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)
+SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND deleted_at IS NULL)
 `
 
-func (q *Queries) UserExists(ctx context.Context, email string) (bool, error) {
+func (q *Queries) UserExists(ctx context.Context, email pgtype.Text) (bool, error) {
 	row := q.db.QueryRow(ctx, userExists, email)
 	var exists bool
 	err := row.Scan(&exists)
@@ -0,0 +1,19 @@
+package mail
+
+import (
+	"log"
+)
+
+// LogSender writes messages to the application log instead of delivering them
+type LogSender struct {
+	from string
+}
+
+func NewLogSender(from string) *LogSender {
+	return &LogSender{from: from}
+}
+
+func (s *LogSender) Send(msg Message) error {
+	log.Printf("Mail from=%s to=%s subject=%q\n%s", s.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,31 @@
+package mail
+
+import (
+	"fmt"
+
+	"go-backend-valos-id/core/config"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email messages
+type Sender interface {
+	Send(msg Message) error
+}
+
+// NewSenderFromConfig builds the Sender selected by MAIL_DRIVER
+func NewSenderFromConfig(cfg *config.MailConfig) (Sender, error) {
+	switch cfg.Driver {
+	case "log":
+		return NewLogSender(cfg.From), nil
+	case "file":
+		return NewFileSender(cfg.From, cfg.FileDir)
+	default:
+		return nil, fmt.Errorf("unsupported mail driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-backend-valos-id/core/auth/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyPrefix starts every API key, which tells them apart from access tokens
+const APIKeyPrefix = "vk_"
+
+// ErrInvalidAPIKey is returned by validators for API keys that are unknown, expired or revoked
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyValidator validates a raw API key presented from ipAddress and returns it
+type APIKeyValidator interface {
+	ValidateAPIKey(raw, ipAddress string) (*model.APIKey, error)
+}
+
+// AuthenticateAPIKey middleware authenticates requests whose bearer token is an API key.
+// Requests with any other bearer token, or none, are handed to next, normally Authenticate,
+// so that routes using it accept both API keys and access tokens.
+func AuthenticateAPIKey(validator APIKeyValidator, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw, ok := BearerToken(c)
+		if !ok || !strings.HasPrefix(raw, APIKeyPrefix) {
+			next(c)
+			return
+		}
+
+		key, err := validator.ValidateAPIKey(raw, c.ClientIP())
+		if err != nil {
+			if errors.Is(err, ErrInvalidAPIKey) {
+				AbortUnauthorized(c, "Invalid or expired API key")
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to validate API key",
+			})
+			return
+		}
+
+		c.Set(PrincipalKey, &Principal{
+			UserID:      key.UserID,
+			AccountType: key.OwnerAccountType,
+			APIKeyID:    key.ID,
+			Scopes:      key.Scopes,
+		})
+		c.Next()
+	}
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend-valos-id/core/auth/token"
+	user_model "go-backend-valos-id/core/user/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrincipalKey is the gin.Context key holding the authenticated *Principal
+const PrincipalKey = "Principal"
+
+// Principal is the identity behind an authenticated request
+type Principal struct {
+	UserID    int32
+	SessionID string
+	TokenID   string
+	// AccountType tells service accounts apart from people, see user_model.AccountTypeService
+	AccountType string
+	// OrganizationID is the organization the request acts within, or 0 for none
+	OrganizationID int32
+	// Claims are the access token claims; nil when authenticated with an API key
+	Claims *token.Claims
+	// APIKeyID and Scopes are set when authenticated with an API key
+	APIKeyID int32
+	Scopes   []string
+}
+
+// Allows reports whether the credential behind the request may exercise a permission the user holds.
+// Access tokens carry every permission of their user; API keys only those in their scopes.
+func (p *Principal) Allows(permission string) bool {
+	if p.APIKeyID == 0 {
+		return true
+	}
+	return slices.Contains(p.Scopes, permission)
+}
+
+// IsServiceAccount reports whether the request was made by a service account
+func (p *Principal) IsServiceAccount() bool {
+	return p.AccountType == user_model.AccountTypeService
+}
+
+// AccessTokenValidator validates a raw bearer token and returns its claims
+type AccessTokenValidator interface {
+	ValidateAccessToken(raw string) (*token.Claims, error)
+}
+
+// ClaimsCheck rejects otherwise valid tokens, for example when their session was revoked.
+// Checks return token.ErrTokenRevoked to reject a token; any other error is reported as a server error.
+type ClaimsCheck func(claims *token.Claims) error
+
+// Authenticate middleware requires a valid bearer access token on every request in the group
+func Authenticate(validator AccessTokenValidator, checks ...ClaimsCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw, ok := BearerToken(c)
+		if !ok {
+			AbortUnauthorized(c, "Authentication required")
+			return
+		}
+
+		claims, err := validator.ValidateAccessToken(raw)
+		if err != nil {
+			AbortUnauthorized(c, "Invalid or expired token")
+			return
+		}
+
+		userID, err := strconv.ParseInt(claims.Subject, 10, 32)
+		if err != nil || userID <= 0 {
+			AbortUnauthorized(c, "Invalid or expired token")
+			return
+		}
+
+		for _, check := range checks {
+			if err := check(claims); err != nil {
+				if errors.Is(err, token.ErrTokenRevoked) {
+					AbortUnauthorized(c, "Invalid or expired token")
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to validate token",
+				})
+				return
+			}
+		}
+
+		// First-party access tokens are only issued on login, which service accounts cannot do
+		c.Set(PrincipalKey, &Principal{
+			UserID:         int32(userID),
+			SessionID:      claims.SessionID,
+			TokenID:        claims.ID,
+			AccountType:    user_model.AccountTypeUser,
+			OrganizationID: claims.OrganizationID,
+			Claims:         claims,
+		})
+		c.Next()
+	}
+}
+
+// GetPrincipal returns the authenticated principal set by Authenticate
+func GetPrincipal(c *gin.Context) (*Principal, bool) {
+	value, exists := c.Get(PrincipalKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*Principal)
+	return principal, ok
+}
+
+// AbortUnauthorized stops the request with a 401 and a bearer challenge
+func AbortUnauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", "Bearer")
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"error": message,
+	})
+}
+
+// AbortForbidden stops the request with a 403 for authenticated callers lacking access
+func AbortForbidden(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error": message,
+	})
+}
+
+// AbortTooManyRequests stops the request with a 429 telling the caller how long to wait
+func AbortTooManyRequests(c *gin.Context, retryAfter time.Duration, message string) {
+	SetRetryAfter(c, retryAfter)
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":       message,
+		"retry_after": RetryAfterSeconds(retryAfter),
+	})
+}
+
+// SetRetryAfter sets the Retry-After header
+func SetRetryAfter(c *gin.Context, retryAfter time.Duration) {
+	c.Header("Retry-After", strconv.FormatInt(RetryAfterSeconds(retryAfter), 10))
+}
+
+// RetryAfterSeconds rounds a wait up to whole seconds, so callers retrying on time are not refused again
+func RetryAfterSeconds(retryAfter time.Duration) int64 {
+	return int64((retryAfter + time.Second - 1) / time.Second)
+}
+
+// BearerToken returns the token of a "Bearer" Authorization header
+func BearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	scheme, raw, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	raw = strings.TrimSpace(raw)
+	return raw, raw != ""
+}
@@ -42,6 +42,9 @@ func ErrorHandler() gin.HandlerFunc {
 	}
 }
 
+// RequestIDKey is the gin.Context key holding the request ID set by RequestID
+const RequestIDKey = "RequestID"
+
 // RequestID middleware for tracking requests
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -49,12 +52,17 @@ func RequestID() gin.HandlerFunc {
 		if requestID == "" {
 			requestID = generateRequestID()
 		}
-		c.Set("RequestID", requestID)
+		c.Set(RequestIDKey, requestID)
 		c.Header("X-Request-ID", requestID)
 		c.Next()
 	}
 }
 
+// GetRequestID returns the request ID set by RequestID
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
+
 // Simple request ID generator
 func generateRequestID() string {
 	return "req-" + randomString(8)
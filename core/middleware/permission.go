@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionChecker reports whether a user has been granted a permission
+type PermissionChecker interface {
+	HasPermission(userID int32, permission string) (bool, error)
+}
+
+// RequirePermission middleware lets through only principals holding permission.
+// It must run after Authenticate.
+func RequirePermission(checker PermissionChecker, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			AbortUnauthorized(c, "Authentication required")
+			return
+		}
+
+		if !principal.Allows(permission) {
+			AbortForbidden(c, "API key lacks the required scope")
+			return
+		}
+
+		allowed, err := checker.HasPermission(principal.UserID, permission)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check permissions",
+			})
+			return
+		}
+		if !allowed {
+			AbortForbidden(c, "Insufficient permissions")
+			return
+		}
+
+		c.Next()
+	}
+}
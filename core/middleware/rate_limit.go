@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitResult is a rate limiter's decision on one request
+type RateLimitResult struct {
+	Allowed bool
+	// Limit is the number of requests allowed per Window; 0 means the request is not limited
+	Limit     int
+	Window    time.Duration
+	Remaining int
+	// Reset is how long until the caller may send another request when refused,
+	// otherwise until the current window ends
+	Reset time.Duration
+}
+
+// RateLimiter counts a request against key and decides whether it may proceed
+type RateLimiter interface {
+	Allow(key string) (RateLimitResult, error)
+}
+
+// RateLimitKey names the caller a request is counted against
+type RateLimitKey func(c *gin.Context) string
+
+// RateLimit middleware refuses requests over the limiter's limit with a 429. Every limited response
+// carries RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers.
+// Requests are let through if the limiter fails, so an unavailable store does not take the API down.
+func RateLimit(limiter RateLimiter, key RateLimitKey) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := limiter.Allow(key(c))
+		if err != nil {
+			log.Printf("Failed to check rate limit: %v", err)
+			c.Next()
+			return
+		}
+		if result.Limit == 0 {
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.FormatInt(RetryAfterSeconds(result.Reset), 10))
+		c.Header("RateLimit-Policy", strconv.Itoa(result.Limit)+";w="+strconv.FormatInt(RetryAfterSeconds(result.Window), 10))
+
+		if !result.Allowed {
+			AbortTooManyRequests(c, result.Reset, "Rate limit exceeded, try again later")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimitByIP counts requests per client IP address. The address is only as reliable as the engine's
+// trusted proxies: X-Forwarded-For is believed from them alone, otherwise any client could pick a new
+// address, and so a fresh limit, for every request.
+func RateLimitByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitByPrincipal counts requests per API key or, for access tokens, per user.
+// Unauthenticated requests are counted per client IP address, as RateLimitByIP; use it after authentication.
+func RateLimitByPrincipal(c *gin.Context) string {
+	principal, ok := GetPrincipal(c)
+	if !ok {
+		return RateLimitByIP(c)
+	}
+	if principal.APIKeyID != 0 {
+		return "api_key:" + strconv.FormatInt(int64(principal.APIKeyID), 10)
+	}
+	return "user:" + strconv.FormatInt(int64(principal.UserID), 10)
+}
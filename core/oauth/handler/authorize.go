@@ -0,0 +1,433 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend-valos-id/core/auth/lockout"
+	"go-backend-valos-id/core/auth/mfa"
+	"go-backend-valos-id/core/auth/token"
+	"go-backend-valos-id/core/middleware"
+	"go-backend-valos-id/core/oauth"
+	"go-backend-valos-id/core/oauth/model"
+	"go-backend-valos-id/core/oauth/repository"
+	user_model "go-backend-valos-id/core/user/model"
+	user_repository "go-backend-valos-id/core/user/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxNonceLength bounds the OpenID Connect nonce stored with an authorization code
+const maxNonceLength = 255
+
+// AuthorizeHandler serves the authorization endpoint, where users sign in with their password
+// (and second factor) to grant a client an authorization code
+type AuthorizeHandler struct {
+	clientRepo           *repository.ClientRepository
+	tokenRepo            *repository.TokenRepository
+	userRepo             *user_repository.UserRepository
+	mfa                  *mfa.Service
+	tokens               *token.Manager
+	lockouts             *lockout.Tracker
+	issuer               string
+	codeTTL              time.Duration
+	requireVerifiedEmail bool
+}
+
+func NewAuthorizeHandler(
+	clientRepo *repository.ClientRepository,
+	tokenRepo *repository.TokenRepository,
+	userRepo *user_repository.UserRepository,
+	mfaService *mfa.Service,
+	tokens *token.Manager,
+	lockouts *lockout.Tracker,
+	issuer string,
+	codeTTL time.Duration,
+	requireVerifiedEmail bool,
+) *AuthorizeHandler {
+	return &AuthorizeHandler{
+		clientRepo:           clientRepo,
+		tokenRepo:            tokenRepo,
+		userRepo:             userRepo,
+		mfa:                  mfaService,
+		tokens:               tokens,
+		lockouts:             lockouts,
+		issuer:               issuer,
+		codeTTL:              codeTTL,
+		requireVerifiedEmail: requireVerifiedEmail,
+	}
+}
+
+// Authorize validates an authorization request and shows the sign-in form
+func (h *AuthorizeHandler) Authorize(c *gin.Context) {
+	var req model.AuthorizationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.renderError(c, http.StatusBadRequest, "Malformed authorization request")
+		return
+	}
+
+	client, redirectURI, ok := h.resolveClient(c, &req)
+	if !ok {
+		return
+	}
+
+	scopes, oauthErr := h.validateRequest(client, &req)
+	if oauthErr != nil {
+		h.redirectWithError(c, redirectURI, req.State, oauthErr)
+		return
+	}
+
+	h.renderLogin(c, http.StatusOK, client, &req, scopes, authorizePage{})
+}
+
+// Submit handles the sign-in form. A correct password either issues the authorization code
+// or, for users with MFA enabled, moves on to asking for their second factor.
+func (h *AuthorizeHandler) Submit(c *gin.Context) {
+	var sub model.AuthorizationSubmission
+	if err := c.ShouldBind(&sub); err != nil {
+		h.renderError(c, http.StatusBadRequest, "Malformed authorization request")
+		return
+	}
+	req := &sub.AuthorizationRequest
+
+	client, redirectURI, ok := h.resolveClient(c, req)
+	if !ok {
+		return
+	}
+
+	scopes, oauthErr := h.validateRequest(client, req)
+	if oauthErr != nil {
+		h.redirectWithError(c, redirectURI, req.State, oauthErr)
+		return
+	}
+
+	if sub.Action == "deny" {
+		h.redirectWithError(c, redirectURI, req.State, oauth.NewError(oauth.ErrAccessDenied, "The user denied the request"))
+		return
+	}
+
+	var user *user_model.User
+	if sub.MFAToken != "" {
+		user, ok = h.checkSecondFactor(c, client, req, scopes, &sub)
+	} else {
+		user, ok = h.checkPassword(c, client, req, scopes, &sub)
+	}
+	if !ok {
+		return
+	}
+	if err := h.lockouts.Succeeded(lockout.ForUser(user.ID, c.ClientIP())); err != nil {
+		log.Printf("Failed to reset failed login attempts: %v", err)
+	}
+
+	h.issueCode(c, client, user, req, redirectURI, scopes)
+}
+
+// Helper methods
+
+// resolveClient loads the client and picks the redirect URI. Failures are shown to the user
+// instead of being redirected, since the redirect URI cannot be trusted (RFC 6749 §4.1.2.1).
+func (h *AuthorizeHandler) resolveClient(c *gin.Context, req *model.AuthorizationRequest) (*model.Client, string, bool) {
+	if req.ClientID == "" {
+		h.renderError(c, http.StatusBadRequest, "The request is missing client_id")
+		return nil, "", false
+	}
+
+	client, err := h.clientRepo.GetClient(req.ClientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrClientNotFound) {
+			h.renderError(c, http.StatusBadRequest, "Unknown client")
+			return nil, "", false
+		}
+		h.renderError(c, http.StatusInternalServerError, "Failed to retrieve client")
+		return nil, "", false
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" {
+		if len(client.RedirectURIs) != 1 {
+			h.renderError(c, http.StatusBadRequest, "The request is missing redirect_uri")
+			return nil, "", false
+		}
+		redirectURI = client.RedirectURIs[0]
+	} else if !oauth.MatchRedirectURI(redirectURI, client.RedirectURIs) {
+		h.renderError(c, http.StatusBadRequest, "The redirect_uri is not registered for this client")
+		return nil, "", false
+	}
+
+	return client, redirectURI, true
+}
+
+// validateRequest checks the remaining parameters and returns the granted scopes
+func (h *AuthorizeHandler) validateRequest(client *model.Client, req *model.AuthorizationRequest) ([]string, *oauth.Error) {
+	if req.ResponseType != "code" {
+		return nil, oauth.NewError(oauth.ErrUnsupportedResponseType, "Only the code response type is supported")
+	}
+	if !client.AllowsGrant(oauth.GrantAuthorizationCode) {
+		return nil, oauth.NewError(oauth.ErrUnauthorizedClient, "The client may not use the authorization code grant")
+	}
+	if req.CodeChallengeMethod != oauth.CodeChallengeS256 || !oauth.ValidCodeChallenge(req.CodeChallenge) {
+		return nil, oauth.NewError(oauth.ErrInvalidRequest, "PKCE with code_challenge_method S256 is required")
+	}
+	if len(req.Nonce) > maxNonceLength {
+		return nil, oauth.NewError(oauth.ErrInvalidRequest, "The nonce is too long")
+	}
+	if slices.Contains(strings.Fields(req.Prompt), "none") {
+		return nil, oauth.NewError(oauth.ErrLoginRequired, "The user must sign in")
+	}
+
+	scopes, err := oauth.ParseScope(req.Scope)
+	if err != nil {
+		return nil, oauth.NewError(oauth.ErrInvalidScope, "The scope is malformed")
+	}
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !oauth.IsSubset(scopes, client.Scopes) {
+		return nil, oauth.NewError(oauth.ErrInvalidScope, "The client may not request this scope")
+	}
+	return scopes, nil
+}
+
+func (h *AuthorizeHandler) checkPassword(c *gin.Context, client *model.Client, req *model.AuthorizationRequest, scopes []string, sub *model.AuthorizationSubmission) (*user_model.User, bool) {
+	page := authorizePage{Identifier: sub.Identifier}
+
+	user, err := h.userRepo.GetUserByIdentifier(sub.Identifier)
+	if err != nil && err != sql.ErrNoRows {
+		h.renderError(c, http.StatusInternalServerError, "Failed to retrieve user")
+		return nil, false
+	}
+	// Service accounts have no password to sign in with
+	if user != nil && user.IsServiceAccount() {
+		user = nil
+	}
+
+	attempt := lockout.ForIdentifier(sub.Identifier, c.ClientIP())
+	if user != nil {
+		attempt = lockout.ForUser(user.ID, c.ClientIP())
+	}
+	if !h.checkLockout(c, client, req, scopes, attempt, page) {
+		return nil, false
+	}
+
+	if user == nil {
+		utils.CheckPasswordHash(sub.Password, utils.DummyPasswordHash)
+		h.recordFailure(attempt)
+		page.Error = "Invalid credentials"
+		h.renderLogin(c, http.StatusUnauthorized, client, req, scopes, page)
+		return nil, false
+	}
+	if !utils.CheckPasswordHash(sub.Password, user.Password) {
+		h.recordFailure(attempt)
+		page.Error = "Invalid credentials"
+		h.renderLogin(c, http.StatusUnauthorized, client, req, scopes, page)
+		return nil, false
+	}
+
+	if h.requireVerifiedEmail && !user.EmailVerified() {
+		page.Error = "Verify your email address before signing in"
+		h.renderLogin(c, http.StatusForbidden, client, req, scopes, page)
+		return nil, false
+	}
+	if !user.IsActive() {
+		page.Error = "This account is not active"
+		h.renderLogin(c, http.StatusForbidden, client, req, scopes, page)
+		return nil, false
+	}
+
+	mfaEnabled, err := h.mfa.Enabled(user.ID)
+	if err != nil {
+		h.renderError(c, http.StatusInternalServerError, "Failed to check MFA status")
+		return nil, false
+	}
+	if mfaEnabled {
+		page.MFAToken, err = h.tokens.IssueMFAChallenge(user.ID, user.CredentialVersion)
+		if err != nil {
+			h.renderError(c, http.StatusInternalServerError, "Failed to issue MFA challenge")
+			return nil, false
+		}
+		h.renderLogin(c, http.StatusOK, client, req, scopes, page)
+		return nil, false
+	}
+
+	return user, true
+}
+
+func (h *AuthorizeHandler) checkSecondFactor(c *gin.Context, client *model.Client, req *model.AuthorizationRequest, scopes []string, sub *model.AuthorizationSubmission) (*user_model.User, bool) {
+	expired := authorizePage{Error: "Your sign-in expired, please try again"}
+
+	claims, err := h.tokens.ValidateMFAChallenge(sub.MFAToken)
+	if err != nil {
+		h.renderLogin(c, http.StatusUnauthorized, client, req, scopes, expired)
+		return nil, false
+	}
+	userID, err := strconv.ParseInt(claims.Subject, 10, 32)
+	if err != nil {
+		h.renderLogin(c, http.StatusUnauthorized, client, req, scopes, expired)
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetUserByID(int32(userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.renderLogin(c, http.StatusUnauthorized, client, req, scopes, expired)
+			return nil, false
+		}
+		h.renderError(c, http.StatusInternalServerError, "Failed to retrieve user")
+		return nil, false
+	}
+	if user.CredentialVersion != claims.CredentialVersion {
+		h.renderLogin(c, http.StatusUnauthorized, client, req, scopes, expired)
+		return nil, false
+	}
+	if !user.IsActive() {
+		h.renderLogin(c, http.StatusForbidden, client, req, scopes, authorizePage{Error: "This account is not active"})
+		return nil, false
+	}
+
+	attempt := lockout.ForUser(user.ID, c.ClientIP())
+	if !h.checkLockout(c, client, req, scopes, attempt, authorizePage{MFAToken: sub.MFAToken}) {
+		return nil, false
+	}
+
+	if err := h.mfa.Verify(user.ID, sub.MFACode); err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) || errors.Is(err, mfa.ErrTOTPNotEnabled) {
+			h.recordFailure(attempt)
+			h.renderLogin(c, http.StatusUnauthorized, client, req, scopes, authorizePage{
+				MFAToken: sub.MFAToken,
+				Error:    "Invalid authentication code",
+			})
+			return nil, false
+		}
+		h.renderError(c, http.StatusInternalServerError, "Failed to verify MFA code")
+		return nil, false
+	}
+
+	return user, true
+}
+
+// checkLockout shows the sign-in form again with a 429 if the attempt's account or IP address is locked out
+func (h *AuthorizeHandler) checkLockout(c *gin.Context, client *model.Client, req *model.AuthorizationRequest, scopes []string, attempt lockout.Attempt, page authorizePage) bool {
+	retryAfter, err := h.lockouts.Check(attempt)
+	if err != nil {
+		h.renderError(c, http.StatusInternalServerError, "Failed to check login attempts")
+		return false
+	}
+	if retryAfter > 0 {
+		middleware.SetRetryAfter(c, retryAfter)
+		page.Error = "Too many failed attempts, try again later"
+		h.renderLogin(c, http.StatusTooManyRequests, client, req, scopes, page)
+		return false
+	}
+	return true
+}
+
+func (h *AuthorizeHandler) recordFailure(attempt lockout.Attempt) {
+	if err := h.lockouts.Failed(attempt); err != nil {
+		log.Printf("Failed to record failed login attempt: %v", err)
+	}
+}
+
+func (h *AuthorizeHandler) issueCode(c *gin.Context, client *model.Client, user *user_model.User, req *model.AuthorizationRequest, redirectURI string, scopes []string) {
+	code, err := utils.RandomToken(32)
+	if err != nil {
+		h.redirectWithError(c, redirectURI, req.State, oauth.NewError(oauth.ErrServerError, ""))
+		return
+	}
+	familyID, err := utils.RandomHex(16)
+	if err != nil {
+		h.redirectWithError(c, redirectURI, req.State, oauth.NewError(oauth.ErrServerError, ""))
+		return
+	}
+
+	err = h.tokenRepo.CreateAuthorizationCode(&model.AuthorizationCode{
+		ClientID: client.ClientID,
+		UserID:   user.ID,
+		// The token request must repeat redirect_uri only if the authorization request included it
+		RedirectURI:   req.RedirectURI,
+		Scope:         oauth.FormatScope(scopes),
+		CodeChallenge: req.CodeChallenge,
+		Nonce:         req.Nonce,
+		FamilyID:      familyID,
+		ExpiresAt:     time.Now().Add(h.codeTTL),
+	}, utils.HashToken(code))
+	if err != nil {
+		log.Printf("Failed to store authorization code: %v", err)
+		h.redirectWithError(c, redirectURI, req.State, oauth.NewError(oauth.ErrServerError, ""))
+		return
+	}
+
+	params := url.Values{}
+	params.Set("code", code)
+	h.redirect(c, redirectURI, req.State, params)
+}
+
+func (h *AuthorizeHandler) redirectWithError(c *gin.Context, redirectURI, state string, oauthErr *oauth.Error) {
+	params := url.Values{}
+	params.Set("error", oauthErr.Code)
+	if oauthErr.Description != "" {
+		params.Set("error_description", oauthErr.Description)
+	}
+	h.redirect(c, redirectURI, state, params)
+}
+
+// redirect sends the authorization response to the client, adding state and the issuer (RFC 9207)
+func (h *AuthorizeHandler) redirect(c *gin.Context, redirectURI, state string, params url.Values) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		h.renderError(c, http.StatusBadRequest, "The redirect_uri is invalid")
+		return
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	query.Set("iss", h.issuer)
+	u.RawQuery = query.Encode()
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, u.String())
+}
+
+func (h *AuthorizeHandler) renderLogin(c *gin.Context, status int, client *model.Client, req *model.AuthorizationRequest, scopes []string, page authorizePage) {
+	page.ClientName = client.Name
+	page.Scopes = scopes
+	page.ClientID = req.ClientID
+	page.RedirectURI = req.RedirectURI
+	page.Scope = req.Scope
+	page.State = req.State
+	page.Nonce = req.Nonce
+	page.Challenge = req.CodeChallenge
+	page.ChallengeMethod = req.CodeChallengeMethod
+
+	h.render(c, status, authorizeTemplate, page)
+}
+
+func (h *AuthorizeHandler) renderError(c *gin.Context, status int, message string) {
+	h.render(c, status, errorTemplate, message)
+}
+
+func (h *AuthorizeHandler) render(c *gin.Context, status int, tmpl *template.Template, data any) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+
+	if err := tmpl.Execute(c.Writer, data); err != nil {
+		log.Printf("Failed to render authorization page: %v", err)
+	}
+}
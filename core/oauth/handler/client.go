@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"go-backend-valos-id/core/audit"
+	"go-backend-valos-id/core/middleware"
+	"go-backend-valos-id/core/oauth"
+	"go-backend-valos-id/core/oauth/model"
+	"go-backend-valos-id/core/oauth/repository"
+	user_handler "go-backend-valos-id/core/user/handler"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ClientHandler struct {
+	clientRepo *repository.ClientRepository
+	// scopes lists the scopes clients may be registered for
+	scopes []string
+}
+
+func NewClientHandler(clientRepo *repository.ClientRepository, scopes []string) *ClientHandler {
+	return &ClientHandler{
+		clientRepo: clientRepo,
+		scopes:     scopes,
+	}
+}
+
+// CreateClient registers an OAuth client owned by the authenticated user.
+// The secret of a confidential client is only returned in this response.
+func (h *ClientHandler) CreateClient(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	var req model.ClientCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.createClient(c, &req, principal.UserID)
+}
+
+// ListClients lists the OAuth clients owned by the authenticated user
+func (h *ClientHandler) ListClients(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+	h.listClients(c, principal.UserID)
+}
+
+// GetClient returns one of the authenticated user's OAuth clients
+func (h *ClientHandler) GetClient(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	client, err := h.clientRepo.GetClient(c.Param("client_id"))
+	if err != nil && !errors.Is(err, repository.ErrClientNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve client",
+		})
+		return
+	}
+	if client == nil || client.OwnerID == nil || *client.OwnerID != principal.UserID {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Client not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": toClientResponse(client),
+	})
+}
+
+// DeleteClient removes one of the authenticated user's OAuth clients and every token issued to it
+func (h *ClientHandler) DeleteClient(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+	h.deleteClient(c, principal.UserID)
+}
+
+// CreateServiceAccountClient registers a confidential client for the service account loaded by
+// user_handler.LoadServiceAccount. The client may only use the client credentials grant, and its
+// tokens act for the service account. The secret is only returned in this response.
+func (h *ClientHandler) CreateServiceAccountClient(c *gin.Context) {
+	account, _ := user_handler.ServiceAccountFromContext(c)
+
+	var req model.ServiceAccountClientCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	h.createClient(c, &model.ClientCreateRequest{
+		Name:       req.Name,
+		ClientType: oauth.ClientConfidential,
+		GrantTypes: []string{oauth.GrantClientCredentials},
+		Scopes:     req.Scopes,
+	}, account.ID)
+}
+
+// ListServiceAccountClients lists the OAuth clients of a service account
+func (h *ClientHandler) ListServiceAccountClients(c *gin.Context) {
+	account, _ := user_handler.ServiceAccountFromContext(c)
+	h.listClients(c, account.ID)
+}
+
+// DeleteServiceAccountClient removes one of the OAuth clients of a service account and every token issued to it
+func (h *ClientHandler) DeleteServiceAccountClient(c *gin.Context) {
+	account, _ := user_handler.ServiceAccountFromContext(c)
+	h.deleteClient(c, account.ID)
+}
+
+// Helper methods
+
+// createClient validates and registers a client owned by ownerID
+func (h *ClientHandler) createClient(c *gin.Context, req *model.ClientCreateRequest, ownerID int32) {
+	if err := h.validateClient(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid client registration",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	clientID, err := utils.RandomHex(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate client ID",
+		})
+		return
+	}
+
+	client := &model.Client{
+		ClientID:     clientID,
+		Name:         req.Name,
+		ClientType:   req.ClientType,
+		RedirectURIs: nonNil(req.RedirectURIs),
+		GrantTypes:   req.GrantTypes,
+		Scopes:       nonNil(req.Scopes),
+		OwnerID:      &ownerID,
+	}
+
+	var clientSecret string
+	if client.IsConfidential() {
+		clientSecret, err = utils.RandomToken(32)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to generate client secret",
+			})
+			return
+		}
+		client.ClientSecretHash, err = utils.HashPassword(clientSecret)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to hash client secret",
+			})
+			return
+		}
+	}
+
+	created, err := h.clientRepo.CreateClient(client, audit.OriginFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create client",
+		})
+		return
+	}
+
+	response := gin.H{
+		"message": "Client created successfully",
+		"data":    toClientResponse(created),
+	}
+	if clientSecret != "" {
+		response["client_secret"] = clientSecret
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *ClientHandler) listClients(c *gin.Context, ownerID int32) {
+	clients, err := h.clientRepo.ListClientsByOwner(ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve clients",
+		})
+		return
+	}
+
+	responses := make([]model.ClientResponse, len(clients))
+	for i, client := range clients {
+		responses[i] = toClientResponse(client)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  responses,
+		"count": len(responses),
+	})
+}
+
+func (h *ClientHandler) deleteClient(c *gin.Context, ownerID int32) {
+	deleted, err := h.clientRepo.DeleteClient(c.Param("client_id"), ownerID, audit.OriginFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete client",
+		})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Client not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Client deleted successfully",
+	})
+}
+
+func (h *ClientHandler) validateClient(req *model.ClientCreateRequest) error {
+	for _, grantType := range req.GrantTypes {
+		if grantType == oauth.GrantClientCredentials && req.ClientType != oauth.ClientConfidential {
+			return errors.New("client_credentials requires a confidential client")
+		}
+	}
+	if slices.Contains(req.GrantTypes, oauth.GrantRefreshToken) && !slices.Contains(req.GrantTypes, oauth.GrantAuthorizationCode) {
+		return errors.New("refresh_token requires authorization_code")
+	}
+
+	if slices.Contains(req.GrantTypes, oauth.GrantAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		return errors.New("authorization_code requires at least one redirect URI")
+	}
+	for _, uri := range req.RedirectURIs {
+		if err := oauth.ValidateRedirectURI(uri, req.ClientType); err != nil {
+			return fmt.Errorf("%s: %w", uri, err)
+		}
+	}
+
+	for _, scope := range req.Scopes {
+		if !slices.Contains(h.scopes, scope) {
+			return fmt.Errorf("unsupported scope %q", scope)
+		}
+	}
+	return nil
+}
+
+func toClientResponse(client *model.Client) model.ClientResponse {
+	return model.ClientResponse{
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		ClientType:   client.ClientType,
+		RedirectURIs: client.RedirectURIs,
+		GrantTypes:   client.GrantTypes,
+		Scopes:       client.Scopes,
+		CreatedAt:    client.CreatedAt,
+		UpdatedAt:    client.UpdatedAt,
+	}
+}
+
+// nonNil keeps empty lists from being stored as NULL
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
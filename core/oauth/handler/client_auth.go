@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+
+	"go-backend-valos-id/core/oauth"
+	"go-backend-valos-id/core/oauth/model"
+	"go-backend-valos-id/core/oauth/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// clientEndpoint is embedded by the handlers of endpoints that OAuth clients call directly
+// (token, introspection and revocation). It authenticates the client and writes OAuth error responses.
+type clientEndpoint struct {
+	clientRepo *repository.ClientRepository
+}
+
+// authenticateClient identifies the client with HTTP Basic, client_secret_post or,
+// for public clients, the bare client_id
+func (h *clientEndpoint) authenticateClient(c *gin.Context, clientID, clientSecret string) (*model.Client, bool) {
+	formClientID := clientID
+	username, password, basic := c.Request.BasicAuth()
+	if basic {
+		if clientSecret != "" {
+			h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidRequest, "Use only one client authentication method"))
+			return nil, false
+		}
+		// Basic credentials are form-encoded before being joined (RFC 6749 §2.3.1)
+		var err error
+		if clientID, err = url.QueryUnescape(username); err != nil {
+			h.invalidClient(c, basic)
+			return nil, false
+		}
+		if clientSecret, err = url.QueryUnescape(password); err != nil {
+			h.invalidClient(c, basic)
+			return nil, false
+		}
+		if formClientID != "" && formClientID != clientID {
+			h.invalidClient(c, basic)
+			return nil, false
+		}
+	}
+
+	if clientID == "" {
+		h.invalidClient(c, basic)
+		return nil, false
+	}
+
+	client, err := h.clientRepo.GetClient(clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrClientNotFound) {
+			utils.CheckPasswordHash(clientSecret, utils.DummyPasswordHash)
+			h.invalidClient(c, basic)
+			return nil, false
+		}
+		h.serverError(c, "Failed to retrieve client", err)
+		return nil, false
+	}
+
+	if client.IsConfidential() {
+		if clientSecret == "" || !utils.CheckPasswordHash(clientSecret, client.ClientSecretHash) {
+			h.invalidClient(c, basic)
+			return nil, false
+		}
+	} else if clientSecret != "" {
+		h.invalidClient(c, basic)
+		return nil, false
+	}
+
+	return client, true
+}
+
+func (h *clientEndpoint) invalidClient(c *gin.Context, basic bool) {
+	if basic {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	h.respondWithError(c, http.StatusUnauthorized, oauth.NewError(oauth.ErrInvalidClient, "Client authentication failed"))
+}
+
+func (h *clientEndpoint) serverError(c *gin.Context, message string, err error) {
+	log.Printf("%s: %v", message, err)
+	h.respondWithError(c, http.StatusInternalServerError, oauth.NewError(oauth.ErrServerError, ""))
+}
+
+func (h *clientEndpoint) respondWithError(c *gin.Context, status int, oauthErr *oauth.Error) {
+	c.JSON(status, oauthErr)
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"go-backend-valos-id/core/auth/token"
+	"go-backend-valos-id/core/oauth/model"
+	"go-backend-valos-id/core/oauth/repository"
+	user_model "go-backend-valos-id/core/user/model"
+	user_repository "go-backend-valos-id/core/user/repository"
+)
+
+// errInactiveOwner is returned for clients whose owner is missing, deleted or not active
+var errInactiveOwner = errors.New("OAuth client owner is not active")
+
+// clientTokenGuard decides whether a validly signed access token issued to an OAuth client is still active.
+// The token and its grant must not be revoked, the client must still be registered with an active owner, if
+// it has one, and, for tokens issued for a user, the user must still exist with unchanged credentials.
+type clientTokenGuard struct {
+	clientRepo *repository.ClientRepository
+	tokenRepo  *repository.TokenRepository
+	userRepo   *user_repository.UserRepository
+}
+
+// check returns the user the token was issued for, or nil for client credentials tokens.
+// It returns token.ErrTokenRevoked if the token is no longer active.
+func (g *clientTokenGuard) check(claims *token.Claims) (*user_model.User, error) {
+	revoked, err := g.tokenRepo.IsAccessTokenRevoked(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, token.ErrTokenRevoked
+	}
+
+	if claims.SessionID != "" {
+		revoked, err := g.tokenRepo.IsRefreshTokenFamilyRevoked(claims.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, token.ErrTokenRevoked
+		}
+	}
+
+	client, err := g.clientRepo.GetClient(claims.ClientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrClientNotFound) {
+			return nil, token.ErrTokenRevoked
+		}
+		return nil, err
+	}
+
+	// Client credentials tokens are issued with the client as subject
+	if claims.Subject == claims.ClientID {
+		if _, err := clientOwner(g.userRepo, client); err != nil {
+			if errors.Is(err, errInactiveOwner) {
+				return nil, token.ErrTokenRevoked
+			}
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 32)
+	if err != nil {
+		return nil, token.ErrTokenRevoked
+	}
+	return g.checkUser(int32(userID), claims.CredentialVersion)
+}
+
+// checkUser returns the user if they still exist, are active and their credentials have not changed since credentialVersion
+func (g *clientTokenGuard) checkUser(userID, credentialVersion int32) (*user_model.User, error) {
+	user, err := g.userRepo.GetUserByID(userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, token.ErrTokenRevoked
+		}
+		return nil, err
+	}
+
+	// A password change since the token was issued invalidates it
+	if user.CredentialVersion != credentialVersion {
+		return nil, token.ErrTokenRevoked
+	}
+	if !user.IsActive() {
+		return nil, token.ErrTokenRevoked
+	}
+	return user, nil
+}
+
+// clientOwner returns the user owning client, or nil for clients nobody owns. It returns errInactiveOwner
+// if the owner is missing, deleted or not active: such clients may neither get nor use tokens.
+func clientOwner(userRepo *user_repository.UserRepository, client *model.Client) (*user_model.User, error) {
+	if client.OwnerID == nil {
+		return nil, nil
+	}
+	owner, err := userRepo.GetUserByID(*client.OwnerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errInactiveOwner
+		}
+		return nil, err
+	}
+	if !owner.IsActive() {
+		return nil, errInactiveOwner
+	}
+	return owner, nil
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"go-backend-valos-id/core/auth/token"
+	"go-backend-valos-id/core/oauth"
+	"go-backend-valos-id/core/oauth/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiscoveryHandler publishes the OpenID Connect provider metadata and the token verification keys
+type DiscoveryHandler struct {
+	tokens  *token.Manager
+	baseURL string
+	scopes  []string
+}
+
+func NewDiscoveryHandler(tokens *token.Manager, scopes []string) *DiscoveryHandler {
+	issuer := tokens.Issuer()
+	if u, err := url.Parse(issuer); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		log.Printf("JWT_ISSUER %q is not a URL; OpenID Connect clients will not be able to use discovery", issuer)
+	}
+	if alg, err := tokens.Algorithm(); err == nil && alg == token.AlgHS256 {
+		log.Printf("Tokens are signed with %s; OpenID Connect clients cannot verify ID tokens without an asymmetric key", alg)
+	}
+
+	return &DiscoveryHandler{
+		tokens:  tokens,
+		baseURL: strings.TrimSuffix(issuer, "/"),
+		scopes:  scopes,
+	}
+}
+
+// Configuration serves the discovery document, built from the endpoints, grants and keys actually in use
+func (h *DiscoveryHandler) Configuration(c *gin.Context) {
+	alg, err := h.tokens.Algorithm()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to load signing key",
+		})
+		return
+	}
+
+	claims := []string{"iss", "sub", "aud", "exp", "iat", "nonce", "azp"}
+	if slices.Contains(h.scopes, oauth.ScopeProfile) {
+		claims = append(claims, "preferred_username")
+	}
+	if slices.Contains(h.scopes, oauth.ScopeEmail) {
+		claims = append(claims, "email", "email_verified")
+	}
+
+	c.JSON(http.StatusOK, model.ProviderMetadata{
+		Issuer:                                     h.tokens.Issuer(),
+		AuthorizationEndpoint:                      h.baseURL + oauth.AuthorizationPath,
+		TokenEndpoint:                              h.baseURL + oauth.TokenPath,
+		UserInfoEndpoint:                           h.baseURL + oauth.UserInfoPath,
+		JWKSURI:                                    h.baseURL + oauth.JWKSPath,
+		ScopesSupported:                            h.scopes,
+		ResponseTypesSupported:                     []string{"code"},
+		ResponseModesSupported:                     []string{"query"},
+		GrantTypesSupported:                        []string{oauth.GrantAuthorizationCode, oauth.GrantRefreshToken, oauth.GrantClientCredentials},
+		SubjectTypesSupported:                      []string{"public"},
+		IDTokenSigningAlgValuesSupported:           []string{alg},
+		TokenEndpointAuthMethodsSupported:          []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:              []string{oauth.CodeChallengeS256},
+		ClaimsSupported:                            claims,
+		IntrospectionEndpoint:                      h.baseURL + oauth.IntrospectionPath,
+		IntrospectionEndpointAuthMethodsSupported:  []string{"client_secret_basic", "client_secret_post"},
+		RevocationEndpoint:                         h.baseURL + oauth.RevocationPath,
+		RevocationEndpointAuthMethodsSupported:     []string{"client_secret_basic", "client_secret_post", "none"},
+		AuthorizationResponseIssParameterSupported: true,
+	})
+}
+
+// JWKS serves the public keys tokens can be verified with. HMAC keys are never published,
+// so the set is empty while tokens are signed with HS256.
+func (h *DiscoveryHandler) JWKS(c *gin.Context) {
+	set, err := h.tokens.PublicKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to load signing keys",
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, set)
+}
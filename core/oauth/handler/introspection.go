@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend-valos-id/core/auth/token"
+	"go-backend-valos-id/core/middleware"
+	"go-backend-valos-id/core/oauth"
+	"go-backend-valos-id/core/oauth/model"
+	"go-backend-valos-id/core/oauth/repository"
+	user_repository "go-backend-valos-id/core/user/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IntrospectionHandler serves the token introspection endpoint (RFC 7662),
+// where resource servers registered as confidential clients ask whether a token is active
+type IntrospectionHandler struct {
+	clientEndpoint
+	guard       clientTokenGuard
+	tokenRepo   *repository.TokenRepository
+	tokens      *token.Manager
+	checkClaims middleware.ClaimsCheck
+}
+
+func NewIntrospectionHandler(
+	clientRepo *repository.ClientRepository,
+	tokenRepo *repository.TokenRepository,
+	userRepo *user_repository.UserRepository,
+	tokens *token.Manager,
+	checkClaims middleware.ClaimsCheck,
+) *IntrospectionHandler {
+	return &IntrospectionHandler{
+		clientEndpoint: clientEndpoint{clientRepo: clientRepo},
+		guard:          clientTokenGuard{clientRepo: clientRepo, tokenRepo: tokenRepo, userRepo: userRepo},
+		tokenRepo:      tokenRepo,
+		tokens:         tokens,
+		checkClaims:    checkClaims,
+	}
+}
+
+// Introspect describes an access token, or a refresh token issued to the calling client.
+// Tokens that are unknown, expired, revoked, issued to a deleted user or whose user's credentials changed
+// are reported as inactive without further detail.
+func (h *IntrospectionHandler) Introspect(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var req model.IntrospectionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidRequest, "The request body is malformed"))
+		return
+	}
+
+	client, ok := h.authenticateClient(c, req.ClientID, req.ClientSecret)
+	if !ok {
+		return
+	}
+	// Public clients cannot keep a secret, so anyone could introspect through them
+	if !client.IsConfidential() {
+		h.respondWithError(c, http.StatusUnauthorized, oauth.NewError(oauth.ErrInvalidClient, "Only confidential clients may introspect tokens"))
+		return
+	}
+
+	if req.Token == "" {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidRequest, "The request is missing token"))
+		return
+	}
+
+	var resp *model.IntrospectionResponse
+	var err error
+	if isJWT(req.Token) {
+		resp, err = h.introspectAccessToken(req.Token)
+	} else {
+		resp, err = h.introspectRefreshToken(client, req.Token)
+	}
+	if err != nil {
+		h.serverError(c, "Failed to introspect token", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Helper methods
+
+// introspectAccessToken describes a first-party access token or one issued to any OAuth client
+func (h *IntrospectionHandler) introspectAccessToken(raw string) (*model.IntrospectionResponse, error) {
+	claims, err := h.tokens.ValidateAccessToken(raw)
+	if errors.Is(err, token.ErrClientToken) {
+		return h.introspectClientAccessToken(raw)
+	}
+	if err != nil {
+		return inactiveToken(), nil
+	}
+
+	if err := h.checkClaims(claims); err != nil {
+		if errors.Is(err, token.ErrTokenRevoked) {
+			return inactiveToken(), nil
+		}
+		return nil, err
+	}
+
+	userID, err := strconv.ParseInt(claims.Subject, 10, 32)
+	if err != nil {
+		return inactiveToken(), nil
+	}
+	user, err := h.guard.checkUser(int32(userID), claims.CredentialVersion)
+	if err != nil {
+		if errors.Is(err, token.ErrTokenRevoked) {
+			return inactiveToken(), nil
+		}
+		return nil, err
+	}
+
+	resp := accessTokenResponse(claims)
+	resp.Username = user.Username
+	return resp, nil
+}
+
+func (h *IntrospectionHandler) introspectClientAccessToken(raw string) (*model.IntrospectionResponse, error) {
+	claims, err := h.tokens.ValidateClientAccessToken(raw)
+	if err != nil {
+		return inactiveToken(), nil
+	}
+
+	user, err := h.guard.check(claims)
+	if err != nil {
+		if errors.Is(err, token.ErrTokenRevoked) {
+			return inactiveToken(), nil
+		}
+		return nil, err
+	}
+
+	resp := accessTokenResponse(claims)
+	if user != nil {
+		resp.Username = user.Username
+	}
+	return resp, nil
+}
+
+// introspectRefreshToken describes an OAuth refresh token. Refresh tokens are never sent to resource servers,
+// so only the client holding one may introspect it; other clients learn nothing about it.
+func (h *IntrospectionHandler) introspectRefreshToken(client *model.Client, raw string) (*model.IntrospectionResponse, error) {
+	refreshToken, err := h.tokenRepo.GetRefreshToken(utils.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenInvalid) {
+			return inactiveToken(), nil
+		}
+		return nil, err
+	}
+
+	if refreshToken.ClientID != client.ClientID || !refreshToken.Usable(time.Now()) {
+		return inactiveToken(), nil
+	}
+
+	user, err := h.guard.checkUser(refreshToken.UserID, refreshToken.CredentialVersion)
+	if err != nil {
+		if errors.Is(err, token.ErrTokenRevoked) {
+			return inactiveToken(), nil
+		}
+		return nil, err
+	}
+
+	return &model.IntrospectionResponse{
+		Active:    true,
+		Scope:     refreshToken.Scope,
+		ClientID:  refreshToken.ClientID,
+		Username:  user.Username,
+		TokenType: oauth.GrantRefreshToken,
+		ExpiresAt: refreshToken.ExpiresAt.Unix(),
+		IssuedAt:  refreshToken.CreatedAt.Unix(),
+		Subject:   strconv.Itoa(int(user.ID)),
+		Issuer:    h.tokens.Issuer(),
+	}, nil
+}
+
+func accessTokenResponse(claims *token.Claims) *model.IntrospectionResponse {
+	return &model.IntrospectionResponse{
+		Active:      true,
+		Scope:       claims.Scope,
+		ClientID:    claims.ClientID,
+		AccountType: claims.AccountType,
+		TokenType:   "Bearer",
+		ExpiresAt:   claims.ExpiresAt,
+		IssuedAt:    claims.IssuedAt,
+		NotBefore:   claims.NotBefore,
+		Subject:     claims.Subject,
+		Audience:    claims.Audience,
+		Issuer:      claims.Issuer,
+		TokenID:     claims.ID,
+	}
+}
+
+func inactiveToken() *model.IntrospectionResponse {
+	return &model.IntrospectionResponse{Active: false}
+}
+
+// isJWT tells access tokens, which are JWTs, apart from opaque refresh tokens
+func isJWT(raw string) bool {
+	return strings.Count(raw, ".") == 2
+}
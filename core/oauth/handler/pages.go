@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"html/template"
+)
+
+// authorizePage is the data rendered by authorizeTemplate
+type authorizePage struct {
+	ClientName  string
+	Scopes      []string
+	Identifier  string
+	MFAToken    string
+	Error       string
+	ClientID    string
+	RedirectURI string
+	Scope       string
+	State       string
+	Nonce       string
+	// Challenge and ChallengeMethod carry the PKCE parameters
+	Challenge       string
+	ChallengeMethod string
+}
+
+var authorizeTemplate = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Sign in</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 24rem; margin: 4rem auto; padding: 0 1rem; color: #1f2328; }
+label { display: block; margin-top: 1rem; }
+input { display: block; width: 100%; padding: .5rem; margin-top: .25rem; box-sizing: border-box; }
+.error { color: #b00020; }
+.actions { display: flex; gap: .5rem; margin-top: 1.5rem; }
+</style>
+</head>
+<body>
+<h1>Sign in</h1>
+<p><strong>{{.ClientName}}</strong> wants to access your account.</p>
+{{if .Scopes}}<p>It is asking for:</p>
+<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<form method="post">
+<input type="hidden" name="response_type" value="code">
+<input type="hidden" name="client_id" value="{{.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Scope}}">
+<input type="hidden" name="state" value="{{.State}}">
+<input type="hidden" name="nonce" value="{{.Nonce}}">
+<input type="hidden" name="code_challenge" value="{{.Challenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.ChallengeMethod}}">
+{{if .MFAToken}}<input type="hidden" name="mfa_token" value="{{.MFAToken}}">
+<label>Authentication code
+<input name="mfa_code" inputmode="numeric" autocomplete="one-time-code" required autofocus>
+</label>
+{{else}}<label>Email or username
+<input name="identifier" value="{{.Identifier}}" autocomplete="username" required autofocus>
+</label>
+<label>Password
+<input type="password" name="password" autocomplete="current-password" required>
+</label>
+{{end}}<div class="actions">
+<button type="submit" name="action" value="allow">Continue</button>
+<button type="submit" name="action" value="deny" formnovalidate>Cancel</button>
+</div>
+</form>
+</body>
+</html>
+`))
+
+var errorTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Authorization error</title>
+</head>
+<body>
+<h1>Authorization error</h1>
+<p>{{.}}</p>
+</body>
+</html>
+`))
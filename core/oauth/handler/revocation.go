@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"go-backend-valos-id/core/auth/token"
+	"go-backend-valos-id/core/oauth"
+	"go-backend-valos-id/core/oauth/model"
+	"go-backend-valos-id/core/oauth/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevocationHandler serves the token revocation endpoint (RFC 7009)
+type RevocationHandler struct {
+	clientEndpoint
+	tokenRepo *repository.TokenRepository
+	tokens    *token.Manager
+}
+
+func NewRevocationHandler(
+	clientRepo *repository.ClientRepository,
+	tokenRepo *repository.TokenRepository,
+	tokens *token.Manager,
+) *RevocationHandler {
+	return &RevocationHandler{
+		clientEndpoint: clientEndpoint{clientRepo: clientRepo},
+		tokenRepo:      tokenRepo,
+		tokens:         tokens,
+	}
+}
+
+// Revoke revokes an access or refresh token issued to the calling client.
+// Revoking a refresh token revokes its whole grant, including the access tokens issued from it.
+// Unknown tokens and tokens of other clients are ignored, but still answered with 200 (RFC 7009 §2.2).
+func (h *RevocationHandler) Revoke(c *gin.Context) {
+	var req model.RevocationRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidRequest, "The request body is malformed"))
+		return
+	}
+
+	client, ok := h.authenticateClient(c, req.ClientID, req.ClientSecret)
+	if !ok {
+		return
+	}
+
+	if req.Token == "" {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidRequest, "The request is missing token"))
+		return
+	}
+
+	if isJWT(req.Token) {
+		// Tokens that no longer validate cannot be used anyway
+		claims, err := h.tokens.ValidateClientAccessToken(req.Token)
+		if err == nil && claims.ClientID == client.ClientID {
+			if err := h.tokenRepo.RevokeAccessToken(claims.ID, claims.ClientID, time.Unix(claims.ExpiresAt, 0)); err != nil {
+				h.serverError(c, "Failed to revoke access token", err)
+				return
+			}
+		}
+	} else {
+		refreshToken, err := h.tokenRepo.GetRefreshToken(utils.HashToken(req.Token))
+		if err != nil && !errors.Is(err, repository.ErrRefreshTokenInvalid) {
+			h.serverError(c, "Failed to retrieve refresh token", err)
+			return
+		}
+		if err == nil && refreshToken.ClientID == client.ClientID {
+			if err := h.tokenRepo.RevokeRefreshTokenFamily(refreshToken.FamilyID); err != nil {
+				h.serverError(c, "Failed to revoke refresh token", err)
+				return
+			}
+		}
+	}
+
+	c.Status(http.StatusOK)
+}
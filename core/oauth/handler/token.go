@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"go-backend-valos-id/core/auth/token"
+	"go-backend-valos-id/core/oauth"
+	"go-backend-valos-id/core/oauth/model"
+	"go-backend-valos-id/core/oauth/repository"
+	user_model "go-backend-valos-id/core/user/model"
+	user_repository "go-backend-valos-id/core/user/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHandler serves the token endpoint
+type TokenHandler struct {
+	clientEndpoint
+	tokenRepo       *repository.TokenRepository
+	userRepo        *user_repository.UserRepository
+	tokens          *token.Manager
+	refreshTokenTTL time.Duration
+}
+
+func NewTokenHandler(
+	clientRepo *repository.ClientRepository,
+	tokenRepo *repository.TokenRepository,
+	userRepo *user_repository.UserRepository,
+	tokens *token.Manager,
+	refreshTokenTTL time.Duration,
+) *TokenHandler {
+	return &TokenHandler{
+		clientEndpoint:  clientEndpoint{clientRepo: clientRepo},
+		tokenRepo:       tokenRepo,
+		userRepo:        userRepo,
+		tokens:          tokens,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+// Token issues tokens for the authorization_code, refresh_token and client_credentials grants.
+// Confidential clients authenticate with HTTP Basic or client_secret in the form body;
+// public clients only send their client_id.
+func (h *TokenHandler) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var req model.TokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidRequest, "The request body is malformed"))
+		return
+	}
+
+	client, ok := h.authenticateClient(c, req.ClientID, req.ClientSecret)
+	if !ok {
+		return
+	}
+
+	if req.GrantType == "" {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidRequest, "The request is missing grant_type"))
+		return
+	}
+	if req.GrantType != oauth.GrantAuthorizationCode && req.GrantType != oauth.GrantRefreshToken && req.GrantType != oauth.GrantClientCredentials {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrUnsupportedGrantType, ""))
+		return
+	}
+	if !client.AllowsGrant(req.GrantType) {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrUnauthorizedClient, "The client may not use this grant type"))
+		return
+	}
+
+	switch req.GrantType {
+	case oauth.GrantAuthorizationCode:
+		h.authorizationCodeGrant(c, client, &req)
+	case oauth.GrantRefreshToken:
+		h.refreshTokenGrant(c, client, &req)
+	case oauth.GrantClientCredentials:
+		h.clientCredentialsGrant(c, client, &req)
+	}
+}
+
+// Helper methods
+
+func (h *TokenHandler) authorizationCodeGrant(c *gin.Context, client *model.Client, req *model.TokenRequest) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidRequest, "The request is missing code or code_verifier"))
+		return
+	}
+
+	code, err := h.tokenRepo.ConsumeAuthorizationCode(utils.HashToken(req.Code))
+	if err != nil {
+		if errors.Is(err, repository.ErrAuthorizationCodeInvalid) || errors.Is(err, repository.ErrAuthorizationCodeReused) {
+			h.invalidGrant(c, "The authorization code is invalid or expired")
+			return
+		}
+		h.serverError(c, "Failed to redeem authorization code", err)
+		return
+	}
+
+	if code.ClientID != client.ClientID {
+		h.invalidGrant(c, "The authorization code was issued to another client")
+		return
+	}
+	if code.RedirectURI != req.RedirectURI {
+		h.invalidGrant(c, "The redirect_uri does not match the authorization request")
+		return
+	}
+	if !oauth.VerifyPKCE(req.CodeVerifier, code.CodeChallenge) {
+		h.invalidGrant(c, "The code_verifier does not match the code_challenge")
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(code.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.invalidGrant(c, "The authorization code is invalid or expired")
+			return
+		}
+		h.serverError(c, "Failed to retrieve user", err)
+		return
+	}
+	if !user.IsActive() {
+		h.invalidGrant(c, "The user account is not active")
+		return
+	}
+
+	h.respondWithTokens(c, client, user, code.Scope, code.FamilyID, code.Nonce)
+}
+
+func (h *TokenHandler) refreshTokenGrant(c *gin.Context, client *model.Client, req *model.TokenRequest) {
+	if req.RefreshToken == "" {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidRequest, "The request is missing refresh_token"))
+		return
+	}
+
+	scopes, err := oauth.ParseScope(req.Scope)
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidScope, "The scope is malformed"))
+		return
+	}
+
+	refreshToken, err := utils.RandomToken(32)
+	if err != nil {
+		h.serverError(c, "Failed to generate refresh token", err)
+		return
+	}
+
+	rotated, err := h.tokenRepo.RotateRefreshToken(utils.HashToken(req.RefreshToken), utils.HashToken(refreshToken), time.Now().Add(h.refreshTokenTTL))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenInvalid) || errors.Is(err, repository.ErrRefreshTokenReused) {
+			h.invalidGrant(c, "The refresh token is invalid or expired")
+			return
+		}
+		h.serverError(c, "Failed to rotate refresh token", err)
+		return
+	}
+
+	// A refresh token presented by the wrong client has leaked, so the whole family goes
+	if rotated.ClientID != client.ClientID {
+		h.revokeFamily(rotated.FamilyID)
+		h.invalidGrant(c, "The refresh token is invalid or expired")
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(rotated.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.invalidGrant(c, "The refresh token is invalid or expired")
+			return
+		}
+		h.serverError(c, "Failed to retrieve user", err)
+		return
+	}
+
+	// Changing the password ends every grant made with the old one
+	if user.CredentialVersion != rotated.CredentialVersion {
+		h.revokeFamily(rotated.FamilyID)
+		h.invalidGrant(c, "The refresh token is invalid or expired")
+		return
+	}
+	if !user.IsActive() {
+		h.invalidGrant(c, "The user account is not active")
+		return
+	}
+
+	// The access token may be narrowed to a subset of the granted scope; the grant itself keeps its scope
+	scope := rotated.Scope
+	if len(scopes) > 0 {
+		granted, _ := oauth.ParseScope(rotated.Scope)
+		if !oauth.IsSubset(scopes, granted) {
+			h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidScope, "The scope exceeds the original grant"))
+			return
+		}
+		scope = oauth.FormatScope(scopes)
+	}
+
+	accessToken, _, err := h.tokens.IssueClientAccessToken(client.ClientID, user.ID, rotated.FamilyID, scope, user.CredentialVersion)
+	if err != nil {
+		h.serverError(c, "Failed to issue access token", err)
+		return
+	}
+
+	response := model.TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(h.tokens.AccessTokenTTL().Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        scope,
+	}
+
+	// Refreshed ID tokens carry no nonce (OIDC Core §12.2)
+	if response.IDToken, err = h.issueIDToken(client, user, scope, ""); err != nil {
+		h.serverError(c, "Failed to issue ID token", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TokenHandler) clientCredentialsGrant(c *gin.Context, client *model.Client, req *model.TokenRequest) {
+	scopes, err := oauth.ParseScope(req.Scope)
+	if err != nil {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidScope, "The scope is malformed"))
+		return
+	}
+	// There is no user to identify, so the grant never includes openid
+	allowed := slices.DeleteFunc(slices.Clone(client.Scopes), func(scope string) bool {
+		return scope == oauth.ScopeOpenID
+	})
+	if len(scopes) == 0 {
+		scopes = allowed
+	}
+	if !oauth.IsSubset(scopes, allowed) {
+		h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidScope, "The client may not request this scope"))
+		return
+	}
+
+	scope := oauth.FormatScope(scopes)
+	owner, err := clientOwner(h.userRepo, client)
+	if err != nil {
+		if errors.Is(err, errInactiveOwner) {
+			_, _, basic := c.Request.BasicAuth()
+			h.invalidClient(c, basic)
+			return
+		}
+		h.serverError(c, "Failed to retrieve client owner", err)
+		return
+	}
+
+	// Clients of service accounts act for the service account; other clients act for themselves
+	var accessToken string
+	if owner != nil && owner.IsServiceAccount() {
+		accessToken, _, err = h.tokens.IssueServiceAccountToken(client.ClientID, owner.ID, scope, owner.CredentialVersion)
+	} else {
+		accessToken, _, err = h.tokens.IssueClientAccessToken(client.ClientID, 0, "", scope, 0)
+	}
+	if err != nil {
+		h.serverError(c, "Failed to issue access token", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(h.tokens.AccessTokenTTL().Seconds()),
+		Scope:       scope,
+	})
+}
+
+// respondWithTokens issues an access token for a user, an ID token if openid was granted and,
+// if the client may refresh it, the first refresh token of the grant
+func (h *TokenHandler) respondWithTokens(c *gin.Context, client *model.Client, user *user_model.User, scope, familyID, nonce string) {
+	accessToken, _, err := h.tokens.IssueClientAccessToken(client.ClientID, user.ID, familyID, scope, user.CredentialVersion)
+	if err != nil {
+		h.serverError(c, "Failed to issue access token", err)
+		return
+	}
+
+	response := model.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(h.tokens.AccessTokenTTL().Seconds()),
+		Scope:       scope,
+	}
+
+	if response.IDToken, err = h.issueIDToken(client, user, scope, nonce); err != nil {
+		h.serverError(c, "Failed to issue ID token", err)
+		return
+	}
+
+	if client.AllowsGrant(oauth.GrantRefreshToken) {
+		refreshToken, err := utils.RandomToken(32)
+		if err != nil {
+			h.serverError(c, "Failed to generate refresh token", err)
+			return
+		}
+
+		err = h.tokenRepo.CreateRefreshToken(&model.RefreshToken{
+			FamilyID:          familyID,
+			ClientID:          client.ClientID,
+			UserID:            user.ID,
+			Scope:             scope,
+			CredentialVersion: user.CredentialVersion,
+			ExpiresAt:         time.Now().Add(h.refreshTokenTTL),
+		}, utils.HashToken(refreshToken))
+		if err != nil {
+			h.serverError(c, "Failed to store refresh token", err)
+			return
+		}
+		response.RefreshToken = refreshToken
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// issueIDToken returns an ID token with the claims allowed by scope, or "" when openid was not granted
+func (h *TokenHandler) issueIDToken(client *model.Client, user *user_model.User, scope, nonce string) (string, error) {
+	scopes, _ := oauth.ParseScope(scope)
+	if !slices.Contains(scopes, oauth.ScopeOpenID) {
+		return "", nil
+	}
+	return h.tokens.IssueIDToken(client.ClientID, user.ID, nonce, profileClaims(user, scopes))
+}
+
+func (h *TokenHandler) revokeFamily(familyID string) {
+	if err := h.tokenRepo.RevokeRefreshTokenFamily(familyID); err != nil {
+		log.Printf("Failed to revoke OAuth refresh token family: %v", err)
+	}
+}
+
+func (h *TokenHandler) invalidGrant(c *gin.Context, description string) {
+	h.respondWithError(c, http.StatusBadRequest, oauth.NewError(oauth.ErrInvalidGrant, description))
+}
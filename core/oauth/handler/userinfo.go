@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"go-backend-valos-id/core/auth/token"
+	"go-backend-valos-id/core/middleware"
+	"go-backend-valos-id/core/oauth"
+	"go-backend-valos-id/core/oauth/model"
+	"go-backend-valos-id/core/oauth/repository"
+	user_model "go-backend-valos-id/core/user/model"
+	user_repository "go-backend-valos-id/core/user/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserInfoHandler serves the OpenID Connect userinfo endpoint
+type UserInfoHandler struct {
+	guard  clientTokenGuard
+	tokens *token.Manager
+}
+
+func NewUserInfoHandler(
+	clientRepo *repository.ClientRepository,
+	tokenRepo *repository.TokenRepository,
+	userRepo *user_repository.UserRepository,
+	tokens *token.Manager,
+) *UserInfoHandler {
+	return &UserInfoHandler{
+		guard:  clientTokenGuard{clientRepo: clientRepo, tokenRepo: tokenRepo, userRepo: userRepo},
+		tokens: tokens,
+	}
+}
+
+// UserInfo returns the claims about the user that the access token's scopes allow.
+// It needs an access token issued to an OAuth client with the openid scope.
+func (h *UserInfoHandler) UserInfo(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	raw, ok := middleware.BearerToken(c)
+	if !ok {
+		// Requests without credentials get a bare challenge (RFC 6750 §3.1)
+		c.Header("WWW-Authenticate", "Bearer")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := h.tokens.ValidateClientAccessToken(raw)
+	if err != nil {
+		h.invalidToken(c)
+		return
+	}
+
+	scopes, err := oauth.ParseScope(claims.Scope)
+	if err != nil || !slices.Contains(scopes, oauth.ScopeOpenID) {
+		c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+		c.JSON(http.StatusForbidden, oauth.NewError(oauth.ErrInsufficientScope, "The access token was not granted the openid scope"))
+		return
+	}
+
+	user, err := h.guard.check(claims)
+	if err != nil {
+		if errors.Is(err, token.ErrTokenRevoked) {
+			h.invalidToken(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, oauth.NewError(oauth.ErrServerError, ""))
+		return
+	}
+	// Client credentials tokens speak for no user
+	if user == nil {
+		h.invalidToken(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.UserInfoResponse{
+		Subject:       claims.Subject,
+		ProfileClaims: profileClaims(user, scopes),
+	})
+}
+
+// Helper methods
+
+func (h *UserInfoHandler) invalidToken(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+	c.JSON(http.StatusUnauthorized, oauth.NewError(oauth.ErrInvalidToken, "The access token is invalid or expired"))
+}
+
+// profileClaims maps the user to the standard claims released by the granted scopes
+func profileClaims(user *user_model.User, scopes []string) token.ProfileClaims {
+	var claims token.ProfileClaims
+	if slices.Contains(scopes, oauth.ScopeProfile) {
+		claims.PreferredUsername = user.Username
+	}
+	if slices.Contains(scopes, oauth.ScopeEmail) {
+		verified := user.EmailVerified()
+		claims.Email = user.Email
+		claims.EmailVerified = &verified
+	}
+	return claims
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"go-backend-valos-id/core/oauth"
+)
+
+type Client struct {
+	ID               int32     `json:"id" db:"id"`
+	ClientID         string    `json:"client_id" db:"client_id"`
+	ClientSecretHash string    `json:"-" db:"client_secret_hash"`
+	Name             string    `json:"name" db:"name"`
+	ClientType       string    `json:"client_type" db:"client_type"`
+	RedirectURIs     []string  `json:"redirect_uris" db:"redirect_uris"`
+	GrantTypes       []string  `json:"grant_types" db:"grant_types"`
+	Scopes           []string  `json:"scopes" db:"scopes"`
+	OwnerID          *int32    `json:"owner_id" db:"owner_id"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsConfidential reports whether the client authenticates with a secret
+func (c *Client) IsConfidential() bool {
+	return c.ClientType == oauth.ClientConfidential
+}
+
+// AllowsGrant reports whether the client is registered for the grant type
+func (c *Client) AllowsGrant(grantType string) bool {
+	return slices.Contains(c.GrantTypes, grantType)
+}
+
+type ClientCreateRequest struct {
+	Name         string   `json:"name" binding:"required,max=100"`
+	ClientType   string   `json:"client_type" binding:"required,oneof=confidential public"`
+	RedirectURIs []string `json:"redirect_uris"`
+	GrantTypes   []string `json:"grant_types" binding:"required,min=1,dive,oneof=authorization_code refresh_token client_credentials"`
+	Scopes       []string `json:"scopes"`
+}
+
+// ServiceAccountClientCreateRequest registers a client for a service account; it is always
+// a confidential client limited to the client credentials grant
+type ServiceAccountClientCreateRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes"`
+}
+
+type ClientResponse struct {
+	ClientID     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	ClientType   string    `json:"client_type"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	GrantTypes   []string  `json:"grant_types"`
+	Scopes       []string  `json:"scopes"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
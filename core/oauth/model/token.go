@@ -0,0 +1,127 @@
+package model
+
+import (
+	"time"
+
+	"go-backend-valos-id/core/auth/token"
+)
+
+type AuthorizationCode struct {
+	ID       int32  `json:"id" db:"id"`
+	ClientID string `json:"client_id" db:"client_id"`
+	UserID   int32  `json:"user_id" db:"user_id"`
+	// RedirectURI is empty when the authorization request did not include one
+	RedirectURI   string `json:"redirect_uri" db:"redirect_uri"`
+	Scope         string `json:"scope" db:"scope"`
+	CodeChallenge string `json:"-" db:"code_challenge"`
+	// Nonce is the OpenID Connect nonce to echo in the ID token
+	Nonce     string    `json:"-" db:"nonce"`
+	FamilyID  string    `json:"-" db:"family_id"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
+
+type RefreshToken struct {
+	ID                int32      `json:"id" db:"id"`
+	FamilyID          string     `json:"-" db:"family_id"`
+	ClientID          string     `json:"client_id" db:"client_id"`
+	UserID            int32      `json:"user_id" db:"user_id"`
+	Scope             string     `json:"scope" db:"scope"`
+	CredentialVersion int32      `json:"-" db:"credential_version"`
+	ExpiresAt         time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt            *time.Time `json:"-" db:"used_at"`
+	RevokedAt         *time.Time `json:"-" db:"revoked_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Usable reports whether the refresh token can still be exchanged: it is unused, not revoked and not expired
+func (t *RefreshToken) Usable(now time.Time) bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// AuthorizationRequest holds the parameters of a request to the authorization endpoint
+type AuthorizationRequest struct {
+	ResponseType        string `form:"response_type"`
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+	// Nonce and Prompt are OpenID Connect parameters
+	Nonce  string `form:"nonce"`
+	Prompt string `form:"prompt"`
+}
+
+// AuthorizationSubmission is the login form posted back to the authorization endpoint
+type AuthorizationSubmission struct {
+	AuthorizationRequest
+	Identifier string `form:"identifier"`
+	Password   string `form:"password"`
+	// MFAToken carries a passed password check into the second-factor step
+	MFAToken string `form:"mfa_token"`
+	MFACode  string `form:"mfa_code"`
+	// Action is "deny" when the user cancels
+	Action string `form:"action"`
+}
+
+// TokenRequest holds the form parameters of a request to the token endpoint
+type TokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	// IDToken is returned when the grant includes the openid scope
+	IDToken string `json:"id_token,omitempty"`
+}
+
+// IntrospectionRequest holds the form parameters of a request to the introspection endpoint (RFC 7662 §2.1)
+type IntrospectionRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// IntrospectionResponse describes a token (RFC 7662 §2.2). Inactive tokens only report Active.
+type IntrospectionResponse struct {
+	Active    bool           `json:"active"`
+	Scope     string         `json:"scope,omitempty"`
+	ClientID  string         `json:"client_id,omitempty"`
+	Username  string         `json:"username,omitempty"`
+	TokenType string         `json:"token_type,omitempty"`
+	ExpiresAt int64          `json:"exp,omitempty"`
+	IssuedAt  int64          `json:"iat,omitempty"`
+	NotBefore int64          `json:"nbf,omitempty"`
+	Subject   string         `json:"sub,omitempty"`
+	Audience  token.Audience `json:"aud,omitempty"`
+	Issuer    string         `json:"iss,omitempty"`
+	TokenID   string         `json:"jti,omitempty"`
+	// AccountType is "service" for tokens acting for a service account; not part of RFC 7662
+	AccountType string `json:"acct,omitempty"`
+}
+
+// RevocationRequest holds the form parameters of a request to the revocation endpoint (RFC 7009 §2.1)
+type RevocationRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// UserInfoResponse holds the claims returned by the userinfo endpoint
+type UserInfoResponse struct {
+	Subject string `json:"sub"`
+	token.ProfileClaims
+}
@@ -0,0 +1,192 @@
+// Package oauth holds the protocol rules shared by the OAuth 2.1 authorization server:
+// grant and client types, scopes, PKCE and redirect URI validation, and error responses.
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Endpoint paths, relative to the issuer URL
+const (
+	AuthorizationPath = "/oauth/authorize"
+	TokenPath         = "/oauth/token"
+	UserInfoPath      = "/oauth/userinfo"
+	IntrospectionPath = "/oauth/introspect"
+	RevocationPath    = "/oauth/revoke"
+	JWKSPath          = "/.well-known/jwks.json"
+	DiscoveryPath     = "/.well-known/openid-configuration"
+)
+
+// Grant types
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantClientCredentials = "client_credentials"
+)
+
+// Client types (RFC 6749 §2.1)
+const (
+	ClientConfidential = "confidential"
+	ClientPublic       = "public"
+)
+
+// OpenID Connect scopes; openid requests an ID token, profile and email select its claims
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// CodeChallengeS256 is the only PKCE method accepted; plain is not allowed by OAuth 2.1
+const CodeChallengeS256 = "S256"
+
+// Error codes (RFC 6749 §4.1.2.1 and §5.2)
+const (
+	ErrInvalidRequest          = "invalid_request"
+	ErrInvalidClient           = "invalid_client"
+	ErrInvalidGrant            = "invalid_grant"
+	ErrUnauthorizedClient      = "unauthorized_client"
+	ErrUnsupportedGrantType    = "unsupported_grant_type"
+	ErrUnsupportedResponseType = "unsupported_response_type"
+	ErrInvalidScope            = "invalid_scope"
+	ErrAccessDenied            = "access_denied"
+	ErrServerError             = "server_error"
+	// ErrLoginRequired answers prompt=none, since signing in always needs the user (OIDC Core §3.1.2.6)
+	ErrLoginRequired = "login_required"
+	// Bearer token errors (RFC 6750 §3.1)
+	ErrInvalidToken      = "invalid_token"
+	ErrInsufficientScope = "insufficient_scope"
+)
+
+// Error is an OAuth error response
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+func NewError(code, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
+
+var (
+	pkceVerifierPattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+	// scope-token = 1*( %x21 / %x23-5B / %x5D-7E )
+	scopeTokenPattern = regexp.MustCompile(`^[\x21\x23-\x5B\x5D-\x7E]+$`)
+)
+
+// ParseScope splits a space-delimited scope string into its unique tokens
+func ParseScope(scope string) ([]string, error) {
+	var scopes []string
+	for _, s := range strings.Fields(scope) {
+		if !scopeTokenPattern.MatchString(s) {
+			return nil, errors.New("invalid scope token")
+		}
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes, nil
+}
+
+// FormatScope joins scopes into a space-delimited scope string
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// IsSubset reports whether every scope in requested is in allowed
+func IsSubset(requested, allowed []string) bool {
+	for _, s := range requested {
+		if !slices.Contains(allowed, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidCodeChallenge reports whether challenge is a well-formed S256 code challenge
+func ValidCodeChallenge(challenge string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// VerifyPKCE checks a code verifier against the S256 challenge from the authorization request (RFC 7636 §4.6)
+func VerifyPKCE(verifier, challenge string) bool {
+	if !pkceVerifierPattern.MatchString(verifier) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// ValidateRedirectURI checks a redirect URI at client registration.
+// URIs must be absolute without a fragment; plain http is only allowed for loopback addresses,
+// and private-use schemes only for public (native) clients.
+func ValidateRedirectURI(raw, clientType string) error {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() {
+		return errors.New("redirect URI must be an absolute URI")
+	}
+	if u.Fragment != "" || strings.Contains(raw, "#") {
+		return errors.New("redirect URI must not contain a fragment")
+	}
+
+	switch u.Scheme {
+	case "https":
+		if u.Host == "" {
+			return errors.New("redirect URI must have a host")
+		}
+		return nil
+	case "http":
+		if !isLoopback(u.Hostname()) {
+			return errors.New("http redirect URIs are only allowed for loopback addresses")
+		}
+		return nil
+	}
+
+	if clientType != ClientPublic {
+		return errors.New("private-use URI schemes are only allowed for public clients")
+	}
+	return nil
+}
+
+// MatchRedirectURI reports whether requested is one of the registered redirect URIs.
+// Matching is exact, except that the port of loopback URIs may vary (RFC 8252 §7.3).
+func MatchRedirectURI(requested string, registered []string) bool {
+	if slices.Contains(registered, requested) {
+		return true
+	}
+
+	req, err := url.Parse(requested)
+	if err != nil || req.Scheme != "http" || !isLoopback(req.Hostname()) {
+		return false
+	}
+	for _, r := range registered {
+		reg, err := url.Parse(r)
+		if err != nil {
+			continue
+		}
+		if reg.Scheme == req.Scheme && reg.Hostname() == req.Hostname() && reg.Path == req.Path && reg.RawQuery == req.RawQuery {
+			return true
+		}
+	}
+	return false
+}
+
+func isLoopback(host string) bool {
+	return host == "127.0.0.1" || host == "::1" || host == "localhost"
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	audit_model "go-backend-valos-id/core/audit/model"
+	audit_repository "go-backend-valos-id/core/audit/repository"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/oauth/model"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrClientNotFound = errors.New("OAuth client not found")
+
+type ClientRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewClientRepository(pool *pgxpool.Pool) *ClientRepository {
+	return &ClientRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// CreateClient registers a client, recording it in the audit log
+func (r *ClientRepository) CreateClient(client *model.Client, origin *audit_model.Origin) (*model.Client, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	var secretHash pgtype.Text
+	if client.ClientSecretHash != "" {
+		secretHash = pgtype.Text{String: client.ClientSecretHash, Valid: true}
+	}
+	var ownerID pgtype.Int4
+	if client.OwnerID != nil {
+		ownerID = pgtype.Int4{Int32: *client.OwnerID, Valid: true}
+	}
+
+	result, err := qtx.CreateOAuthClient(ctx, repository.CreateOAuthClientParams{
+		ClientID:         client.ClientID,
+		ClientSecretHash: secretHash,
+		Name:             client.Name,
+		ClientType:       client.ClientType,
+		RedirectUris:     client.RedirectURIs,
+		GrantTypes:       client.GrantTypes,
+		Scopes:           client.Scopes,
+		OwnerID:          ownerID,
+		CreatedAt:        utils.ToEpochMillis(now),
+		UpdatedAt:        utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created := r.sqlcClientToModel(&result)
+	err = audit_repository.Record(ctx, qtx, origin, audit_model.ActionOAuthClientCreated, audit_model.TargetOAuthClient, created.ClientID, nil, created)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetClient returns the client with clientID. Like their API keys, the clients of deleted users are not
+// found until the users are restored.
+func (r *ClientRepository) GetClient(clientID string) (*model.Client, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetOAuthClientByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrClientNotFound
+		}
+		return nil, err
+	}
+
+	return r.sqlcClientToModel(&result), nil
+}
+
+func (r *ClientRepository) ListClientsByOwner(ownerID int32) ([]*model.Client, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListOAuthClientsByOwner(ctx, pgtype.Int4{Int32: ownerID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make([]*model.Client, len(results))
+	for i := range results {
+		clients[i] = r.sqlcClientToModel(&results[i])
+	}
+	return clients, nil
+}
+
+// DeleteClient removes a client owned by ownerID together with its codes and refresh tokens, recording it
+// in the audit log. It reports false if no such client exists.
+func (r *ClientRepository) DeleteClient(clientID string, ownerID int32, origin *audit_model.Origin) (bool, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	result, err := qtx.DeleteOAuthClient(ctx, repository.DeleteOAuthClientParams{
+		ClientID: clientID,
+		OwnerID:  pgtype.Int4{Int32: ownerID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	err = audit_repository.Record(ctx, qtx, origin, audit_model.ActionOAuthClientDeleted, audit_model.TargetOAuthClient, clientID, r.sqlcClientToModel(&result), nil)
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Helper method to convert sqlc OauthClient to model Client
+func (r *ClientRepository) sqlcClientToModel(sqlcClient *repository.OauthClient) *model.Client {
+	client := &model.Client{
+		ID:               sqlcClient.ID,
+		ClientID:         sqlcClient.ClientID,
+		ClientSecretHash: sqlcClient.ClientSecretHash.String,
+		Name:             sqlcClient.Name,
+		ClientType:       sqlcClient.ClientType,
+		RedirectURIs:     sqlcClient.RedirectUris,
+		GrantTypes:       sqlcClient.GrantTypes,
+		Scopes:           sqlcClient.Scopes,
+		CreatedAt:        utils.FromEpochMillis(sqlcClient.CreatedAt),
+		UpdatedAt:        utils.FromEpochMillis(sqlcClient.UpdatedAt),
+	}
+	if sqlcClient.OwnerID.Valid {
+		client.OwnerID = &sqlcClient.OwnerID.Int32
+	}
+	return client
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/oauth/model"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	// ErrAuthorizationCodeInvalid is returned for unknown or expired authorization codes
+	ErrAuthorizationCodeInvalid = errors.New("authorization code is invalid")
+	// ErrAuthorizationCodeReused is returned when a code is redeemed twice; tokens issued for it are revoked
+	ErrAuthorizationCodeReused = errors.New("authorization code was reused")
+	// ErrRefreshTokenInvalid is returned for unknown, expired or revoked refresh tokens
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again
+	ErrRefreshTokenReused = errors.New("refresh token was reused")
+)
+
+type TokenRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewTokenRepository(pool *pgxpool.Pool) *TokenRepository {
+	return &TokenRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// CreateAuthorizationCode stores the hash of a newly issued authorization code
+func (r *TokenRepository) CreateAuthorizationCode(code *model.AuthorizationCode, codeHash string) error {
+	ctx := context.Background()
+
+	return r.queries.CreateOAuthAuthorizationCode(ctx, repository.CreateOAuthAuthorizationCodeParams{
+		CodeHash:      codeHash,
+		ClientID:      code.ClientID,
+		UserID:        code.UserID,
+		RedirectUri:   code.RedirectURI,
+		Scope:         code.Scope,
+		CodeChallenge: code.CodeChallenge,
+		Nonce:         code.Nonce,
+		FamilyID:      code.FamilyID,
+		ExpiresAt:     code.ExpiresAt.UnixMilli(),
+		CreatedAt:     utils.ToEpochMillis(time.Now()),
+	})
+}
+
+// ConsumeAuthorizationCode marks a code as used and returns it.
+// Redeeming a code a second time revokes the refresh tokens issued for it.
+func (r *TokenRepository) ConsumeAuthorizationCode(codeHash string) (*model.AuthorizationCode, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	result, err := qtx.GetOAuthAuthorizationCodeByHash(ctx, codeHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAuthorizationCodeInvalid
+		}
+		return nil, err
+	}
+
+	if result.UsedAt.Valid {
+		return nil, r.revokeFamily(ctx, tx, qtx, result.FamilyID, now, ErrAuthorizationCodeReused)
+	}
+	if now.UnixMilli() >= result.ExpiresAt {
+		return nil, ErrAuthorizationCodeInvalid
+	}
+
+	rows, err := qtx.ConsumeOAuthAuthorizationCode(ctx, repository.ConsumeOAuthAuthorizationCodeParams{
+		ID:     result.ID,
+		UsedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		// A concurrent request redeemed the same code first
+		return nil, r.revokeFamily(ctx, tx, qtx, result.FamilyID, now, ErrAuthorizationCodeReused)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &model.AuthorizationCode{
+		ID:            result.ID,
+		ClientID:      result.ClientID,
+		UserID:        result.UserID,
+		RedirectURI:   result.RedirectUri,
+		Scope:         result.Scope,
+		CodeChallenge: result.CodeChallenge,
+		Nonce:         result.Nonce,
+		FamilyID:      result.FamilyID,
+		ExpiresAt:     time.UnixMilli(result.ExpiresAt),
+	}, nil
+}
+
+// CreateRefreshToken stores the hash of a newly issued refresh token
+func (r *TokenRepository) CreateRefreshToken(token *model.RefreshToken, tokenHash string) error {
+	ctx := context.Background()
+
+	return r.queries.CreateOAuthRefreshToken(ctx, repository.CreateOAuthRefreshTokenParams{
+		TokenHash:         tokenHash,
+		FamilyID:          token.FamilyID,
+		ClientID:          token.ClientID,
+		UserID:            token.UserID,
+		Scope:             token.Scope,
+		CredentialVersion: token.CredentialVersion,
+		ExpiresAt:         token.ExpiresAt.UnixMilli(),
+		CreatedAt:         utils.ToEpochMillis(time.Now()),
+	})
+}
+
+// RotateRefreshToken consumes the refresh token identified by tokenHash and stores its successor,
+// which keeps the client, user, scope and credential version. Presenting a token that was already
+// consumed revokes the whole family.
+func (r *TokenRepository) RotateRefreshToken(tokenHash, newTokenHash string, newExpiresAt time.Time) (*model.RefreshToken, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := r.queries.WithTx(tx)
+
+	current, err := qtx.GetOAuthRefreshTokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	if current.RevokedAt.Valid {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if current.UsedAt.Valid {
+		return nil, r.revokeFamily(ctx, tx, qtx, current.FamilyID, now, ErrRefreshTokenReused)
+	}
+	if now.UnixMilli() >= current.ExpiresAt {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	rows, err := qtx.MarkOAuthRefreshTokenUsed(ctx, repository.MarkOAuthRefreshTokenUsedParams{
+		ID:     current.ID,
+		UsedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		// A concurrent request rotated the same token first
+		return nil, r.revokeFamily(ctx, tx, qtx, current.FamilyID, now, ErrRefreshTokenReused)
+	}
+
+	err = qtx.CreateOAuthRefreshToken(ctx, repository.CreateOAuthRefreshTokenParams{
+		TokenHash:         newTokenHash,
+		FamilyID:          current.FamilyID,
+		ClientID:          current.ClientID,
+		UserID:            current.UserID,
+		Scope:             current.Scope,
+		CredentialVersion: current.CredentialVersion,
+		ExpiresAt:         newExpiresAt.UnixMilli(),
+		CreatedAt:         utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &model.RefreshToken{
+		FamilyID:          current.FamilyID,
+		ClientID:          current.ClientID,
+		UserID:            current.UserID,
+		Scope:             current.Scope,
+		CredentialVersion: current.CredentialVersion,
+		ExpiresAt:         newExpiresAt,
+	}, nil
+}
+
+// RevokeRefreshTokenFamily revokes every refresh token issued from the same authorization
+func (r *TokenRepository) RevokeRefreshTokenFamily(familyID string) error {
+	ctx := context.Background()
+
+	return r.queries.RevokeOAuthRefreshTokenFamily(ctx, repository.RevokeOAuthRefreshTokenFamilyParams{
+		FamilyID:  familyID,
+		RevokedAt: utils.ToEpochMillis(time.Now()),
+	})
+}
+
+// GetRefreshToken returns the refresh token identified by tokenHash in whatever state it is in
+func (r *TokenRepository) GetRefreshToken(tokenHash string) (*model.RefreshToken, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetOAuthRefreshTokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	return &model.RefreshToken{
+		ID:                result.ID,
+		FamilyID:          result.FamilyID,
+		ClientID:          result.ClientID,
+		UserID:            result.UserID,
+		Scope:             result.Scope,
+		CredentialVersion: result.CredentialVersion,
+		ExpiresAt:         time.UnixMilli(result.ExpiresAt),
+		UsedAt:            utils.NullableFromEpochMillis(result.UsedAt),
+		RevokedAt:         utils.NullableFromEpochMillis(result.RevokedAt),
+		CreatedAt:         utils.FromEpochMillis(result.CreatedAt),
+	}, nil
+}
+
+// IsRefreshTokenFamilyRevoked reports whether the grant identified by familyID has been revoked
+func (r *TokenRepository) IsRefreshTokenFamilyRevoked(familyID string) (bool, error) {
+	ctx := context.Background()
+
+	return r.queries.IsOAuthRefreshTokenFamilyRevoked(ctx, familyID)
+}
+
+// RevokeAccessToken records the jti of an access token as revoked until the token expires.
+// Entries of tokens that have expired anyway are cleaned up on the way.
+func (r *TokenRepository) RevokeAccessToken(jti, clientID string, expiresAt time.Time) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := r.queries.DeleteExpiredOAuthRevokedAccessTokens(ctx, now.UnixMilli()); err != nil {
+		return err
+	}
+
+	return r.queries.RevokeOAuthAccessToken(ctx, repository.RevokeOAuthAccessTokenParams{
+		Jti:       jti,
+		ClientID:  clientID,
+		ExpiresAt: expiresAt.UnixMilli(),
+		RevokedAt: now.UnixMilli(),
+	})
+}
+
+// IsAccessTokenRevoked reports whether the access token with the given jti has been revoked
+func (r *TokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	ctx := context.Background()
+
+	return r.queries.IsOAuthAccessTokenRevoked(ctx, jti)
+}
+
+// revokeFamily commits the revocation of a family and reports the reuse as reuseErr
+func (r *TokenRepository) revokeFamily(ctx context.Context, tx pgx.Tx, qtx *repository.Queries, familyID string, now time.Time, reuseErr error) error {
+	err := qtx.RevokeOAuthRefreshTokenFamily(ctx, repository.RevokeOAuthRefreshTokenFamilyParams{
+		FamilyID:  familyID,
+		RevokedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	return reuseErr
+}
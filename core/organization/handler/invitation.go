@@ -0,0 +1,455 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go-backend-valos-id/core/audit"
+	"go-backend-valos-id/core/mail"
+	"go-backend-valos-id/core/middleware"
+	"go-backend-valos-id/core/organization/model"
+	"go-backend-valos-id/core/organization/repository"
+	user_model "go-backend-valos-id/core/user/model"
+	user_repository "go-backend-valos-id/core/user/repository"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// InvitationHandler serves invitations into organizations. Owners and admins invite by email and manage
+// pending invitations; the invited person accepts or declines with the token from the emailed link.
+type InvitationHandler struct {
+	orgRepo        *repository.OrganizationRepository
+	invitationRepo *repository.InvitationRepository
+	userRepo       *user_repository.UserRepository
+	memberships    MembershipInvalidator
+	mailer         mail.Sender
+	appBaseURL     string
+	invitationTTL  time.Duration
+}
+
+func NewInvitationHandler(
+	orgRepo *repository.OrganizationRepository,
+	invitationRepo *repository.InvitationRepository,
+	userRepo *user_repository.UserRepository,
+	memberships MembershipInvalidator,
+	mailer mail.Sender,
+	appBaseURL string,
+	invitationTTL time.Duration,
+) *InvitationHandler {
+	return &InvitationHandler{
+		orgRepo:        orgRepo,
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		memberships:    memberships,
+		mailer:         mailer,
+		appBaseURL:     appBaseURL,
+		invitationTTL:  invitationTTL,
+	}
+}
+
+// CreateInvitation invites an email address into the organization and mails the accept link.
+// Only owners may invite owners.
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	caller, ok := callerMembership(c, h.orgRepo)
+	if !ok {
+		return
+	}
+
+	var req model.InvitationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !h.canManageInvitation(c, caller, req.Role) {
+		return
+	}
+
+	existing, err := h.userRepo.GetUserByEmail(req.Email)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+	if existing != nil {
+		member, err := h.orgRepo.IsMember(caller.OrganizationID, existing.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check organization membership",
+			})
+			return
+		}
+		if member {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "User is already a member of this organization",
+			})
+			return
+		}
+	}
+
+	invitationToken, err := utils.RandomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create invitation",
+		})
+		return
+	}
+
+	invitation := &model.Invitation{
+		OrganizationID: caller.OrganizationID,
+		Email:          req.Email,
+		Role:           req.Role,
+		InvitedBy:      &caller.UserID,
+		ExpiresAt:      time.Now().Add(h.invitationTTL),
+	}
+	if err := h.invitationRepo.CreateInvitation(invitation, utils.HashToken(invitationToken), audit.OriginFromContext(c)); err != nil {
+		h.invitationError(c, err, "Failed to create invitation")
+		return
+	}
+
+	if !h.sendInvitation(c, invitation, invitationToken) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Invitation created successfully",
+		"data":    invitation,
+	})
+}
+
+// ListInvitations lists the pending invitations of an organization, including expired ones that can be resent
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	caller, ok := callerMembership(c, h.orgRepo)
+	if !ok {
+		return
+	}
+	if !model.CanManageMembers(caller.Role) {
+		middleware.AbortForbidden(c, "Only owners and admins can manage invitations")
+		return
+	}
+
+	invitations, err := h.invitationRepo.ListPendingInvitations(caller.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve invitations",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  invitations,
+		"count": len(invitations),
+	})
+}
+
+// ResendInvitation mails a pending invitation again with a new link and a fresh expiry.
+// The previous link stops working.
+func (h *InvitationHandler) ResendInvitation(c *gin.Context) {
+	caller, invitation, ok := h.findInvitation(c)
+	if !ok {
+		return
+	}
+	if !h.canManageInvitation(c, caller, invitation.Role) {
+		return
+	}
+
+	invitationToken, err := utils.RandomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to resend invitation",
+		})
+		return
+	}
+
+	expiresAt := time.Now().Add(h.invitationTTL)
+	renewed, err := h.invitationRepo.RenewInvitation(invitation, utils.HashToken(invitationToken), expiresAt, audit.OriginFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to resend invitation",
+		})
+		return
+	}
+	if !renewed {
+		h.notPending(c)
+		return
+	}
+	invitation.ExpiresAt = expiresAt
+
+	if !h.sendInvitation(c, invitation, invitationToken) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation resent successfully",
+		"data":    invitation,
+	})
+}
+
+// RevokeInvitation revokes a pending invitation so that its link stops working
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	caller, invitation, ok := h.findInvitation(c)
+	if !ok {
+		return
+	}
+	if !h.canManageInvitation(c, caller, invitation.Role) {
+		return
+	}
+
+	revoked, err := h.invitationRepo.RevokeInvitation(invitation, audit.OriginFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke invitation",
+		})
+		return
+	}
+	if !revoked {
+		h.notPending(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation revoked successfully",
+	})
+}
+
+// AcceptInvitation joins the organization with an invitation token. The account registered with the invited
+// address joins it; if there is none, an account is created from the username and password in the request.
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req model.InvitationAcceptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	invitation, err := h.invitationRepo.GetPendingInvitationByToken(utils.HashToken(req.Token))
+	if err != nil {
+		h.invitationError(c, err, "Failed to retrieve invitation")
+		return
+	}
+
+	user, err := h.userRepo.GetUserByEmail(invitation.Email)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+
+	created := user == nil
+	if created {
+		var ok bool
+		if user, ok = h.createUser(c, invitation, &req); !ok {
+			return
+		}
+	}
+
+	// Holding the mailed token, the caller acts as the invited user
+	origin := audit.OriginFromContext(c)
+	origin.ActorID = &user.ID
+	if err := h.invitationRepo.AcceptInvitation(invitation, user.ID, origin); err != nil {
+		h.invitationError(c, err, "Failed to accept invitation")
+		return
+	}
+	h.memberships.MembershipChanged(invitation.OrganizationID, user.ID)
+
+	org, err := h.orgRepo.GetOrganization(invitation.OrganizationID)
+	if err != nil {
+		organizationError(c, err, "Failed to retrieve organization")
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{
+		"message": "Invitation accepted successfully",
+		"data": model.UserOrganization{
+			Organization: *org,
+			Role:         invitation.Role,
+		},
+	})
+}
+
+// DeclineInvitation declines an invitation with its token
+func (h *InvitationHandler) DeclineInvitation(c *gin.Context) {
+	var req model.InvitationDeclineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	invitation, err := h.invitationRepo.GetPendingInvitationByToken(utils.HashToken(req.Token))
+	if err != nil {
+		h.invitationError(c, err, "Failed to retrieve invitation")
+		return
+	}
+
+	if err := h.invitationRepo.DeclineInvitation(invitation, audit.OriginFromContext(c)); err != nil {
+		h.invitationError(c, err, "Failed to decline invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation declined successfully",
+	})
+}
+
+// Helper methods
+
+// findInvitation loads the caller's membership and the invitation named by the :invitation_id parameter
+func (h *InvitationHandler) findInvitation(c *gin.Context) (*model.Member, *model.Invitation, bool) {
+	caller, ok := callerMembership(c, h.orgRepo)
+	if !ok {
+		return nil, nil, false
+	}
+
+	invitationID, err := parseID(c.Param("invitation_id"), "invitation")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return nil, nil, false
+	}
+
+	invitation, err := h.invitationRepo.GetInvitation(caller.OrganizationID, invitationID)
+	if err != nil {
+		h.invitationError(c, err, "Failed to retrieve invitation")
+		return nil, nil, false
+	}
+	return caller, invitation, true
+}
+
+// canManageInvitation lets owners and admins manage invitations, and only owners those granting the owner role.
+// It writes the error response when access is denied.
+func (h *InvitationHandler) canManageInvitation(c *gin.Context, caller *model.Member, role string) bool {
+	if !model.CanManageMembers(caller.Role) {
+		middleware.AbortForbidden(c, "Only owners and admins can manage invitations")
+		return false
+	}
+	if role == model.OrgRoleOwner && caller.Role != model.OrgRoleOwner {
+		middleware.AbortForbidden(c, "Only owners can invite owners")
+		return false
+	}
+	return true
+}
+
+// createUser registers the account an invitation is accepted with, like user registration does.
+// The invited address needs no separate verification since accepting proves it.
+func (h *InvitationHandler) createUser(c *gin.Context, invitation *model.Invitation, req *model.InvitationAcceptRequest) (*user_model.User, bool) {
+	if req.Username == "" || req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Username and password are required to create an account for the invited email",
+		})
+		return nil, false
+	}
+	if err := utils.ValidatePasswordStrength(req.Password, req.Username, invitation.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Password is too weak",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to hash password",
+		})
+		return nil, false
+	}
+
+	user := &user_model.User{
+		Username: req.Username,
+		Email:    invitation.Email,
+		Password: hashedPassword,
+	}
+	if err := h.userRepo.CreateUser(user, audit.OriginFromContext(c)); err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "User already exists",
+				"details": pgErr.Message,
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create user",
+		})
+		return nil, false
+	}
+	return user, true
+}
+
+// sendInvitation mails the accept link of an invitation, writing the error response if that fails.
+// The invitation stays pending, so it can be resent.
+func (h *InvitationHandler) sendInvitation(c *gin.Context, invitation *model.Invitation, invitationToken string) bool {
+	org, err := h.orgRepo.GetOrganization(invitation.OrganizationID)
+	if err != nil {
+		organizationError(c, err, "Failed to retrieve organization")
+		return false
+	}
+
+	link := fmt.Sprintf("%s/accept-invitation?token=%s", h.appBaseURL, url.QueryEscape(invitationToken))
+	err = h.mailer.Send(mail.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You have been invited to join %s", org.Name),
+		Body: fmt.Sprintf("Hi,\n\nYou have been invited to join %s as %s. Use the link below to accept or decline the invitation. It expires on %s.\n\n%s\n\nIf you were not expecting this invitation you can ignore this email.\n",
+			org.Name, invitation.Role, invitation.ExpiresAt.UTC().Format(time.RFC1123), link),
+	})
+	if err != nil {
+		log.Printf("Failed to send invitation email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to send invitation email; the invitation can be resent",
+		})
+		return false
+	}
+	return true
+}
+
+func (h *InvitationHandler) notPending(c *gin.Context) {
+	c.JSON(http.StatusConflict, gin.H{
+		"error": "Invitation is no longer pending",
+	})
+}
+
+func (h *InvitationHandler) invitationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Invitation not found",
+		})
+	case errors.Is(err, repository.ErrInvitationExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "A pending invitation for this email already exists; resend it instead",
+		})
+	case errors.Is(err, repository.ErrInvitationInvalid):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid or expired invitation",
+		})
+	case errors.Is(err, repository.ErrAlreadyMember):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "User is already a member of this organization",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go-backend-valos-id/core/audit"
+	"go-backend-valos-id/core/middleware"
+	"go-backend-valos-id/core/organization/model"
+	"go-backend-valos-id/core/organization/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MembershipInvalidator is told about membership changes so that access tokens
+// acting within an organization stop working at once for users who left it
+type MembershipInvalidator interface {
+	MembershipChanged(organizationID, userID int32)
+}
+
+// OrganizationHandler serves organizations and their members. Only members can see an organization;
+// owners and admins manage its members, and only owners manage other owners.
+type OrganizationHandler struct {
+	orgRepo     *repository.OrganizationRepository
+	memberships MembershipInvalidator
+}
+
+func NewOrganizationHandler(orgRepo *repository.OrganizationRepository, memberships MembershipInvalidator) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgRepo:     orgRepo,
+		memberships: memberships,
+	}
+}
+
+// CreateOrganization creates an organization owned by the caller
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		middleware.AbortUnauthorized(c, "Authentication required")
+		return
+	}
+
+	var req model.OrganizationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !model.ValidSlug(req.Slug) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Slug may only contain lowercase letters, digits and hyphens",
+		})
+		return
+	}
+
+	org := &model.Organization{
+		Slug: req.Slug,
+		Name: req.Name,
+	}
+	if err := h.orgRepo.CreateOrganization(org, principal.UserID, audit.OriginFromContext(c)); err != nil {
+		if errors.Is(err, repository.ErrOrganizationExists) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Organization with this slug already exists",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create organization",
+		})
+		return
+	}
+	h.memberships.MembershipChanged(org.ID, principal.UserID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Organization created successfully",
+		"data": model.UserOrganization{
+			Organization: *org,
+			Role:         model.OrgRoleOwner,
+		},
+	})
+}
+
+// ListOrganizations lists the organizations the caller belongs to
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		middleware.AbortUnauthorized(c, "Authentication required")
+		return
+	}
+
+	orgs, err := h.orgRepo.ListUserOrganizations(principal.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve organizations",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  orgs,
+		"count": len(orgs),
+	})
+}
+
+// GetOrganization returns an organization the caller belongs to, with the caller's role in it
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	caller, ok := callerMembership(c, h.orgRepo)
+	if !ok {
+		return
+	}
+
+	org, err := h.orgRepo.GetOrganization(caller.OrganizationID)
+	if err != nil {
+		organizationError(c, err, "Failed to retrieve organization")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": model.UserOrganization{
+			Organization: *org,
+			Role:         caller.Role,
+		},
+	})
+}
+
+// ListMembers lists the members of an organization the caller belongs to
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	caller, ok := callerMembership(c, h.orgRepo)
+	if !ok {
+		return
+	}
+
+	members, err := h.orgRepo.ListMembers(caller.OrganizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve members",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  members,
+		"count": len(members),
+	})
+}
+
+// UpdateMemberRole changes the role of a member. Owners and admins may change roles,
+// but only owners may make or unmake owners. The last owner cannot be demoted.
+func (h *OrganizationHandler) UpdateMemberRole(c *gin.Context) {
+	caller, ok := callerMembership(c, h.orgRepo)
+	if !ok {
+		return
+	}
+
+	userID, err := parseID(c.Param("user_id"), "user")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var req model.MemberRoleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	target, err := h.orgRepo.GetMember(caller.OrganizationID, userID)
+	if err != nil {
+		organizationError(c, err, "Failed to retrieve member")
+		return
+	}
+
+	if !model.CanManageMembers(caller.Role) {
+		middleware.AbortForbidden(c, "Only owners and admins can change member roles")
+		return
+	}
+	if (target.Role == model.OrgRoleOwner || req.Role == model.OrgRoleOwner) && caller.Role != model.OrgRoleOwner {
+		middleware.AbortForbidden(c, "Only owners can change the owners of an organization")
+		return
+	}
+
+	if err := h.orgRepo.UpdateMemberRole(caller.OrganizationID, userID, req.Role, audit.OriginFromContext(c)); err != nil {
+		organizationError(c, err, "Failed to update member role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member role updated successfully",
+	})
+}
+
+// RemoveMember removes a member from an organization. Members may leave on their own;
+// owners and admins may remove others, but only owners may remove owners.
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	caller, ok := callerMembership(c, h.orgRepo)
+	if !ok {
+		return
+	}
+
+	userID, err := parseID(c.Param("user_id"), "user")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if userID != caller.UserID {
+		target, err := h.orgRepo.GetMember(caller.OrganizationID, userID)
+		if err != nil {
+			organizationError(c, err, "Failed to retrieve member")
+			return
+		}
+
+		if !model.CanManageMembers(caller.Role) {
+			middleware.AbortForbidden(c, "Only owners and admins can remove members")
+			return
+		}
+		if target.Role == model.OrgRoleOwner && caller.Role != model.OrgRoleOwner {
+			middleware.AbortForbidden(c, "Only owners can remove owners")
+			return
+		}
+	}
+
+	if err := h.orgRepo.RemoveMember(caller.OrganizationID, userID, audit.OriginFromContext(c)); err != nil {
+		organizationError(c, err, "Failed to remove member")
+		return
+	}
+	h.memberships.MembershipChanged(caller.OrganizationID, userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Member removed successfully",
+	})
+}
+
+// Helper functions, shared with InvitationHandler
+
+// callerMembership loads the caller's membership in the organization named by the :id parameter.
+// Organizations the caller does not belong to are reported as not found.
+func callerMembership(c *gin.Context, orgRepo *repository.OrganizationRepository) (*model.Member, bool) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		middleware.AbortUnauthorized(c, "Authentication required")
+		return nil, false
+	}
+
+	organizationID, err := parseID(c.Param("id"), "organization")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+
+	member, err := orgRepo.GetMember(organizationID, principal.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMemberNotFound) {
+			err = repository.ErrOrganizationNotFound
+		}
+		organizationError(c, err, "Failed to retrieve organization")
+		return nil, false
+	}
+	return member, true
+}
+
+func organizationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Organization not found",
+		})
+	case errors.Is(err, repository.ErrMemberNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Member not found",
+		})
+	case errors.Is(err, repository.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "An organization must keep at least one owner",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
+
+func parseID(value, name string) (int32, error) {
+	id, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s ID", name)
+	}
+	if id <= 0 {
+		return 0, fmt.Errorf("%s ID must be positive", name)
+	}
+	return int32(id), nil
+}
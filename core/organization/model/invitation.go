@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+)
+
+// Invitation statuses. Only pending invitations can be accepted, declined, revoked or resent.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+	InvitationRevoked  = "revoked"
+)
+
+type Invitation struct {
+	ID             int32  `json:"id" db:"id"`
+	OrganizationID int32  `json:"organization_id" db:"organization_id"`
+	Email          string `json:"email" db:"email"`
+	Role           string `json:"role" db:"role"`
+	// InvitedBy is nil once the inviting user is deleted
+	InvitedBy   *int32     `json:"invited_by" db:"invited_by"`
+	Status      string     `json:"status" db:"status"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at" db:"responded_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Expired reports whether a pending invitation can no longer be accepted without being resent
+func (i *Invitation) Expired() bool {
+	return !time.Now().Before(i.ExpiresAt)
+}
+
+type InvitationCreateRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+	Role  string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// InvitationAcceptRequest accepts an invitation. Username and password are only needed
+// when no account exists for the invited address yet, to create one; the password must meet
+// utils.ValidatePasswordStrength like every other chosen password.
+type InvitationAcceptRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Username string `json:"username" binding:"omitempty,min=3,max=50"`
+	Password string `json:"password"`
+}
+
+type InvitationDeclineRequest struct {
+	Token string `json:"token" binding:"required"`
+}
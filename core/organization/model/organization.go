@@ -0,0 +1,62 @@
+package model
+
+import (
+	"regexp"
+	"time"
+)
+
+// Roles a member can hold within an organization. They are independent of the global RBAC roles.
+const (
+	// OrgRoleOwner can do everything, including managing other owners
+	OrgRoleOwner = "owner"
+	// OrgRoleAdmin manages members other than owners
+	OrgRoleAdmin = "admin"
+	// OrgRoleMember can see the organization and its members
+	OrgRoleMember = "member"
+)
+
+// slugPattern is the format of organization slugs: lowercase letters, digits and inner hyphens
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ValidSlug reports whether slug is a well-formed organization slug
+func ValidSlug(slug string) bool {
+	return slugPattern.MatchString(slug)
+}
+
+// CanManageMembers reports whether an organization role may change the members of the organization
+func CanManageMembers(role string) bool {
+	return role == OrgRoleOwner || role == OrgRoleAdmin
+}
+
+type Organization struct {
+	ID        int32     `json:"id" db:"id"`
+	Slug      string    `json:"slug" db:"slug"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UserOrganization is an organization along with the role a user holds in it
+type UserOrganization struct {
+	Organization
+	Role string `json:"role"`
+}
+
+type Member struct {
+	OrganizationID int32     `json:"organization_id" db:"organization_id"`
+	UserID         int32     `json:"user_id" db:"user_id"`
+	Username       string    `json:"username" db:"username"`
+	Email          string    `json:"email" db:"email"`
+	Role           string    `json:"role" db:"role"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type OrganizationCreateRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Slug string `json:"slug" binding:"required,min=2,max=50"`
+}
+
+type MemberRoleUpdateRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member"`
+}
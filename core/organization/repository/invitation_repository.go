@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	audit_model "go-backend-valos-id/core/audit/model"
+	audit_repository "go-backend-valos-id/core/audit/repository"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/organization/model"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrInvitationNotFound = errors.New("invitation not found")
+	// ErrInvitationExists is returned when the address already has a pending invitation to the organization
+	ErrInvitationExists = errors.New("a pending invitation for this email already exists")
+	// ErrInvitationInvalid is returned for tokens that are unknown, expired or no longer pending
+	ErrInvitationInvalid = errors.New("invitation is invalid or expired")
+	// ErrAlreadyMember is returned when accepting an invitation on behalf of an existing member
+	ErrAlreadyMember = errors.New("user is already a member of the organization")
+)
+
+type InvitationRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewInvitationRepository(pool *pgxpool.Pool) *InvitationRepository {
+	return &InvitationRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// CreateInvitation stores a pending invitation accepted with the token whose hash is given,
+// recording it in the audit log
+func (r *InvitationRepository) CreateInvitation(invitation *model.Invitation, tokenHash string, origin *audit_model.Origin) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	var invitedBy pgtype.Int4
+	if invitation.InvitedBy != nil {
+		invitedBy = pgtype.Int4{Int32: *invitation.InvitedBy, Valid: true}
+	}
+
+	result, err := qtx.CreateOrganizationInvitation(ctx, repository.CreateOrganizationInvitationParams{
+		OrganizationID: invitation.OrganizationID,
+		Email:          invitation.Email,
+		Role:           invitation.Role,
+		TokenHash:      tokenHash,
+		InvitedBy:      invitedBy,
+		ExpiresAt:      invitation.ExpiresAt.UnixMilli(),
+		CreatedAt:      utils.ToEpochMillis(now),
+		UpdatedAt:      utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return ErrInvitationExists
+		}
+		return err
+	}
+
+	created := r.sqlcInvitationToModel(&result)
+	if err := r.recordChange(ctx, qtx, origin, audit_model.ActionInvitationCreated, nil, created); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	*invitation = *created
+	return nil
+}
+
+// GetInvitation retrieves an invitation of an organization by its ID
+func (r *InvitationRepository) GetInvitation(organizationID, id int32) (*model.Invitation, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetOrganizationInvitationByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	if result.OrganizationID != organizationID {
+		return nil, ErrInvitationNotFound
+	}
+
+	return r.sqlcInvitationToModel(&result), nil
+}
+
+// GetPendingInvitationByToken retrieves the invitation a token was issued for,
+// or ErrInvitationInvalid if it is unknown, expired or no longer pending
+func (r *InvitationRepository) GetPendingInvitationByToken(tokenHash string) (*model.Invitation, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetOrganizationInvitationByTokenHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvitationInvalid
+		}
+		return nil, err
+	}
+
+	invitation := r.sqlcInvitationToModel(&result)
+	if invitation.Status != model.InvitationPending || invitation.Expired() {
+		return nil, ErrInvitationInvalid
+	}
+	return invitation, nil
+}
+
+// ListPendingInvitations returns the pending invitations of an organization, including expired ones
+func (r *InvitationRepository) ListPendingInvitations(organizationID int32) ([]model.Invitation, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListPendingOrganizationInvitations(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]model.Invitation, len(results))
+	for i := range results {
+		invitations[i] = *r.sqlcInvitationToModel(&results[i])
+	}
+
+	return invitations, nil
+}
+
+// RenewInvitation replaces the token of a pending invitation and extends its expiry, recording it in the audit log.
+// It returns false if the invitation is no longer pending.
+func (r *InvitationRepository) RenewInvitation(invitation *model.Invitation, tokenHash string, expiresAt time.Time, origin *audit_model.Origin) (bool, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	rows, err := qtx.RenewOrganizationInvitation(ctx, repository.RenewOrganizationInvitationParams{
+		ID:        invitation.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt.UnixMilli(),
+		UpdatedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	renewed := *invitation
+	renewed.ExpiresAt = time.UnixMilli(expiresAt.UnixMilli())
+	renewed.UpdatedAt = now
+	if err := r.recordChange(ctx, qtx, origin, audit_model.ActionInvitationRenewed, invitation, &renewed); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RevokeInvitation revokes a pending invitation, returning false if it is no longer pending
+func (r *InvitationRepository) RevokeInvitation(invitation *model.Invitation, origin *audit_model.Origin) (bool, error) {
+	return r.setStatus(invitation, model.InvitationRevoked, audit_model.ActionInvitationRevoked, origin)
+}
+
+// DeclineInvitation declines a pending invitation
+func (r *InvitationRepository) DeclineInvitation(invitation *model.Invitation, origin *audit_model.Origin) error {
+	declined, err := r.setStatus(invitation, model.InvitationDeclined, audit_model.ActionInvitationDeclined, origin)
+	if err != nil {
+		return err
+	}
+	if !declined {
+		return ErrInvitationInvalid
+	}
+	return nil
+}
+
+// AcceptInvitation adds the user to the organization with the invited role and marks the invitation accepted,
+// recording both in the audit log. The token was mailed to the invited address, so the user's email is marked
+// verified if it is that address.
+func (r *InvitationRepository) AcceptInvitation(invitation *model.Invitation, userID int32, origin *audit_model.Origin) error {
+	ctx := context.Background()
+	respondedAt := time.Now()
+	now := utils.ToEpochMillis(respondedAt)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	rows, err := qtx.SetOrganizationInvitationStatus(ctx, repository.SetOrganizationInvitationStatusParams{
+		ID:          invitation.ID,
+		Status:      model.InvitationAccepted,
+		RespondedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrInvitationInvalid
+	}
+
+	err = qtx.AddOrganizationMember(ctx, repository.AddOrganizationMemberParams{
+		OrganizationID: invitation.OrganizationID,
+		UserID:         userID,
+		Role:           invitation.Role,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return ErrAlreadyMember
+		}
+		return err
+	}
+
+	_, err = qtx.SetUserEmailVerified(ctx, repository.SetUserEmailVerifiedParams{
+		ID:              userID,
+		EmailVerifiedAt: now,
+		Email:           pgtype.Text{String: invitation.Email, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	accepted := respond(invitation, model.InvitationAccepted, respondedAt)
+	if err := r.recordChange(ctx, qtx, origin, audit_model.ActionInvitationAccepted, invitation, accepted); err != nil {
+		return err
+	}
+	err = recordMemberChange(ctx, qtx, origin, audit_model.ActionOrganizationMemberAdded, invitation.OrganizationID, userID, nil, &model.Member{Role: invitation.Role})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// setStatus answers a pending invitation with status and records action in the audit log.
+// It returns false if the invitation is no longer pending.
+func (r *InvitationRepository) setStatus(invitation *model.Invitation, status, action string, origin *audit_model.Origin) (bool, error) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	rows, err := qtx.SetOrganizationInvitationStatus(ctx, repository.SetOrganizationInvitationStatusParams{
+		ID:          invitation.ID,
+		Status:      status,
+		RespondedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	if err := r.recordChange(ctx, qtx, origin, action, invitation, respond(invitation, status, now)); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// recordChange writes an audit event about an invitation in the transaction of qtx
+func (r *InvitationRepository) recordChange(ctx context.Context, qtx *repository.Queries, origin *audit_model.Origin, action string, before, after *model.Invitation) error {
+	invitation := after
+	if invitation == nil {
+		invitation = before
+	}
+	return audit_repository.Record(ctx, qtx, origin, action, audit_model.TargetInvitation, strconv.FormatInt(int64(invitation.ID), 10), before, after)
+}
+
+// respond returns a copy of a pending invitation answered with status at respondedAt
+func respond(invitation *model.Invitation, status string, respondedAt time.Time) *model.Invitation {
+	responded := *invitation
+	responded.Status = status
+	responded.RespondedAt = &respondedAt
+	responded.UpdatedAt = respondedAt
+	return &responded
+}
+
+// Helper method to convert sqlc OrganizationInvitation to model Invitation
+func (r *InvitationRepository) sqlcInvitationToModel(sqlcInvitation *repository.OrganizationInvitation) *model.Invitation {
+	invitation := &model.Invitation{
+		ID:             sqlcInvitation.ID,
+		OrganizationID: sqlcInvitation.OrganizationID,
+		Email:          sqlcInvitation.Email,
+		Role:           sqlcInvitation.Role,
+		Status:         sqlcInvitation.Status,
+		ExpiresAt:      time.UnixMilli(sqlcInvitation.ExpiresAt),
+		RespondedAt:    utils.NullableFromEpochMillis(sqlcInvitation.RespondedAt),
+		CreatedAt:      utils.FromEpochMillis(sqlcInvitation.CreatedAt),
+		UpdatedAt:      utils.FromEpochMillis(sqlcInvitation.UpdatedAt),
+	}
+	if sqlcInvitation.InvitedBy.Valid {
+		invitation.InvitedBy = &sqlcInvitation.InvitedBy.Int32
+	}
+	return invitation
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	audit_model "go-backend-valos-id/core/audit/model"
+	audit_repository "go-backend-valos-id/core/audit/repository"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/organization/model"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrOrganizationExists   = errors.New("organization with this slug already exists")
+	ErrMemberNotFound       = errors.New("organization member not found")
+	// ErrLastOwner is returned when a change would leave an organization without owners
+	ErrLastOwner = errors.New("cannot remove the last owner")
+)
+
+type OrganizationRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewOrganizationRepository(pool *pgxpool.Pool) *OrganizationRepository {
+	return &OrganizationRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// CreateOrganization creates an organization with ownerID as its first owner, recording it in the audit log
+func (r *OrganizationRepository) CreateOrganization(org *model.Organization, ownerID int32, origin *audit_model.Origin) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	result, err := qtx.CreateOrganization(ctx, repository.CreateOrganizationParams{
+		Slug:      org.Slug,
+		Name:      org.Name,
+		CreatedAt: utils.ToEpochMillis(now),
+		UpdatedAt: utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return ErrOrganizationExists
+		}
+		return err
+	}
+
+	err = qtx.AddOrganizationMember(ctx, repository.AddOrganizationMemberParams{
+		OrganizationID: result.ID,
+		UserID:         ownerID,
+		Role:           model.OrgRoleOwner,
+		CreatedAt:      utils.ToEpochMillis(now),
+		UpdatedAt:      utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return err
+	}
+
+	created := r.sqlcOrganizationToModel(&result)
+	err = audit_repository.Record(ctx, qtx, origin, audit_model.ActionOrganizationCreated, audit_model.TargetOrganization, strconv.FormatInt(int64(created.ID), 10), nil, created)
+	if err != nil {
+		return err
+	}
+	err = recordMemberChange(ctx, qtx, origin, audit_model.ActionOrganizationMemberAdded, created.ID, ownerID, nil, &model.Member{Role: model.OrgRoleOwner})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	*org = *created
+	return nil
+}
+
+// GetOrganization retrieves an organization by its ID
+func (r *OrganizationRepository) GetOrganization(id int32) (*model.Organization, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetOrganizationByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+
+	return r.sqlcOrganizationToModel(&result), nil
+}
+
+// ListUserOrganizations returns the organizations a user belongs to with the user's role in each
+func (r *OrganizationRepository) ListUserOrganizations(userID int32) ([]model.UserOrganization, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListOrganizationsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	orgs := make([]model.UserOrganization, len(results))
+	for i, result := range results {
+		orgs[i] = model.UserOrganization{
+			Organization: model.Organization{
+				ID:        result.ID,
+				Slug:      result.Slug,
+				Name:      result.Name,
+				CreatedAt: utils.FromEpochMillis(result.CreatedAt),
+				UpdatedAt: utils.FromEpochMillis(result.UpdatedAt),
+			},
+			Role: result.Role,
+		}
+	}
+
+	return orgs, nil
+}
+
+// GetMember returns the membership of a user in an organization, without the user's details
+func (r *OrganizationRepository) GetMember(organizationID, userID int32) (*model.Member, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetOrganizationMember(ctx, repository.GetOrganizationMemberParams{
+		OrganizationID: organizationID,
+		UserID:         userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+
+	return &model.Member{
+		OrganizationID: result.OrganizationID,
+		UserID:         result.UserID,
+		Role:           result.Role,
+		CreatedAt:      utils.FromEpochMillis(result.CreatedAt),
+		UpdatedAt:      utils.FromEpochMillis(result.UpdatedAt),
+	}, nil
+}
+
+// IsMember reports whether a user belongs to an organization
+func (r *OrganizationRepository) IsMember(organizationID, userID int32) (bool, error) {
+	ctx := context.Background()
+
+	return r.queries.IsOrganizationMember(ctx, repository.IsOrganizationMemberParams{
+		OrganizationID: organizationID,
+		UserID:         userID,
+	})
+}
+
+// ListMembers returns the members of an organization
+func (r *OrganizationRepository) ListMembers(organizationID int32) ([]model.Member, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListOrganizationMembers(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]model.Member, len(results))
+	for i, result := range results {
+		members[i] = model.Member{
+			OrganizationID: result.OrganizationID,
+			UserID:         result.UserID,
+			Username:       result.Username,
+			Email:          result.Email.String,
+			Role:           result.Role,
+			CreatedAt:      utils.FromEpochMillis(result.CreatedAt),
+			UpdatedAt:      utils.FromEpochMillis(result.UpdatedAt),
+		}
+	}
+
+	return members, nil
+}
+
+// UpdateMemberRole changes the role of a member, refusing to demote the last owner, and records it in the audit log
+func (r *OrganizationRepository) UpdateMemberRole(organizationID, userID int32, role string, origin *audit_model.Origin) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	if err := r.lockOrganization(ctx, qtx, organizationID); err != nil {
+		return err
+	}
+	before, err := r.getMember(ctx, qtx, organizationID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := qtx.UpdateOrganizationMemberRole(ctx, repository.UpdateOrganizationMemberRoleParams{
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Role:           role,
+		UpdatedAt:      utils.ToEpochMillis(time.Now()),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrMemberNotFound
+	}
+
+	if err := r.ensureOwner(ctx, qtx, organizationID); err != nil {
+		return err
+	}
+	if before.Role != role {
+		err = recordMemberChange(ctx, qtx, origin, audit_model.ActionOrganizationMemberRoleChanged, organizationID, userID, before, &model.Member{Role: role})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// RemoveMember removes a user from an organization, refusing to remove the last owner, and records it in
+// the audit log. Sessions of the user acting within the organization leave it.
+func (r *OrganizationRepository) RemoveMember(organizationID, userID int32, origin *audit_model.Origin) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	if err := r.lockOrganization(ctx, qtx, organizationID); err != nil {
+		return err
+	}
+	before, err := r.getMember(ctx, qtx, organizationID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := qtx.DeleteOrganizationMember(ctx, repository.DeleteOrganizationMemberParams{
+		OrganizationID: organizationID,
+		UserID:         userID,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrMemberNotFound
+	}
+
+	if err := r.ensureOwner(ctx, qtx, organizationID); err != nil {
+		return err
+	}
+
+	err = qtx.ClearSessionsOrganization(ctx, repository.ClearSessionsOrganizationParams{
+		UserID:         userID,
+		OrganizationID: pgtype.Int4{Int32: organizationID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := recordMemberChange(ctx, qtx, origin, audit_model.ActionOrganizationMemberRemoved, organizationID, userID, before, nil); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// recordMemberChange writes an audit event about the membership of a user in the transaction of qtx.
+// before and after hold the role of the member around the change, nil when joining or leaving.
+func recordMemberChange(ctx context.Context, qtx *repository.Queries, origin *audit_model.Origin, action string, organizationID, userID int32, before, after *model.Member) error {
+	snapshot := func(member *model.Member) any {
+		if member == nil {
+			return nil
+		}
+		return map[string]any{"role": member.Role}
+	}
+	targetID := fmt.Sprintf("%d:%d", organizationID, userID)
+	return audit_repository.Record(ctx, qtx, origin, action, audit_model.TargetOrganizationMember, targetID, snapshot(before), snapshot(after))
+}
+
+// getMember returns the membership of a user within the transaction of qtx, or ErrMemberNotFound
+func (r *OrganizationRepository) getMember(ctx context.Context, qtx *repository.Queries, organizationID, userID int32) (*model.Member, error) {
+	result, err := qtx.GetOrganizationMember(ctx, repository.GetOrganizationMemberParams{
+		OrganizationID: organizationID,
+		UserID:         userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+
+	return &model.Member{
+		OrganizationID: result.OrganizationID,
+		UserID:         result.UserID,
+		Role:           result.Role,
+	}, nil
+}
+
+// lockOrganization serializes membership changes of an organization, so that two owners
+// cannot demote each other at the same time
+func (r *OrganizationRepository) lockOrganization(ctx context.Context, qtx *repository.Queries, organizationID int32) error {
+	_, err := qtx.GetOrganizationByIDForUpdate(ctx, organizationID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrOrganizationNotFound
+	}
+	return err
+}
+
+// ensureOwner returns ErrLastOwner if the organization has no owner left
+func (r *OrganizationRepository) ensureOwner(ctx context.Context, qtx *repository.Queries, organizationID int32) error {
+	owners, err := qtx.CountOrganizationOwners(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// Helper method to convert sqlc Organization to model Organization
+func (r *OrganizationRepository) sqlcOrganizationToModel(sqlcOrg *repository.Organization) *model.Organization {
+	return &model.Organization{
+		ID:        sqlcOrg.ID,
+		Slug:      sqlcOrg.Slug,
+		Name:      sqlcOrg.Name,
+		CreatedAt: utils.FromEpochMillis(sqlcOrg.CreatedAt),
+		UpdatedAt: utils.FromEpochMillis(sqlcOrg.UpdatedAt),
+	}
+}
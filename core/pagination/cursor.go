@@ -0,0 +1,114 @@
+// Package pagination implements the cursors of keyset paginated listings. A cursor holds the position
+// of a row in a listing ordered by (created_at, id) and a digest of the listing's filter, signed so that
+// clients treat it as opaque and cannot forge positions or carry them over to another listing.
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"go-backend-valos-id/core/config"
+)
+
+// ErrInvalidCursor is returned for cursors that are malformed, were not signed with the current key
+// or were issued for a listing with another filter
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position a page starts after. A backward cursor asks for the page ending before it instead.
+type Cursor struct {
+	CreatedAt int64 `json:"c"`
+	ID        int32 `json:"i"`
+	Backward  bool  `json:"b,omitempty"`
+	// Filter is the FilterDigest of the listing the cursor was issued for
+	Filter string `json:"f"`
+}
+
+// FilterDigest returns a short digest of the values filtering a listing, for Cursor.Filter.
+// Listings whose rows may differ must pass different values.
+func FilterDigest(values ...string) string {
+	hash := sha256.New()
+	for _, value := range values {
+		// Length prefixes keep ("ab", "c") apart from ("a", "bc")
+		fmt.Fprintf(hash, "%d:%s;", len(value), value)
+	}
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:12])
+}
+
+// Signer encodes cursors and checks the cursors clients send back with HMAC-SHA256
+type Signer struct {
+	key []byte
+}
+
+func NewSigner(key []byte) (*Signer, error) {
+	if len(key) < 32 {
+		return nil, errors.New("pagination cursor key must be at least 32 bytes")
+	}
+	return &Signer{key: key}, nil
+}
+
+// NewSignerFromConfig builds a Signer from the base64 encoded PAGINATION_CURSOR_KEY
+func NewSignerFromConfig(cfg *config.PaginationConfig) (*Signer, error) {
+	if cfg.CursorKey == "" {
+		// Fall back to an ephemeral key so local setups work without configuration
+		log.Println("PAGINATION_CURSOR_KEY is not set, using an ephemeral key; cursors will not survive a restart or work across instances")
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate pagination cursor key: %w", err)
+		}
+		return NewSigner(key)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(cfg.CursorKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PAGINATION_CURSOR_KEY: %w", err)
+	}
+	return NewSigner(key)
+}
+
+// Encode returns the opaque form of a cursor: its payload and signature, base64url encoded and joined by a dot
+func (s *Signer) Encode(cursor Cursor) string {
+	payload, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(s.sign(payload))
+}
+
+// Decode checks the signature of an opaque cursor and that it was issued for the listing whose
+// FilterDigest is filter, and returns the cursor it holds
+func (s *Signer) Decode(value, filter string) (*Cursor, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if !hmac.Equal(signature, s.sign(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Filter != filter {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+func (s *Signer) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
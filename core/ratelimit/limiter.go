@@ -0,0 +1,124 @@
+// Package ratelimit limits how many requests a caller may send, using a sliding window.
+// Requests are counted in fixed windows; the count of the previous window is weighed in by how much of it
+// still overlaps the sliding window, which smooths out bursts at window boundaries.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"go-backend-valos-id/core/config"
+	"go-backend-valos-id/core/middleware"
+	"go-backend-valos-id/core/ratelimit/repository"
+)
+
+// cleanupInterval is how often expired counters are removed
+const cleanupInterval = time.Minute
+
+// Limiter is a middleware.RateLimiter allowing a number of requests per window and key.
+// Refused requests are counted too, so callers that keep retrying stay limited.
+type Limiter struct {
+	store    Store
+	name     string
+	requests int
+	window   time.Duration
+	now      func() time.Time
+}
+
+// NewLimiter creates a limiter for rule. Its counters are kept apart from other limiters on the same store by name.
+func NewLimiter(store Store, name string, rule config.RateLimitRule) *Limiter {
+	return &Limiter{
+		store:    store,
+		name:     name,
+		requests: rule.Requests,
+		window:   rule.Window,
+		now:      time.Now,
+	}
+}
+
+// NewStoreFromConfig returns the store selected by cfg, checking the configured rules
+func NewStoreFromConfig(cfg *config.RateLimitConfig, repo *repository.CounterRepository) (Store, error) {
+	for name, rule := range map[string]config.RateLimitRule{"AUTH": cfg.Auth, "OAUTH": cfg.OAuth, "API": cfg.API} {
+		if rule.Requests < 0 || (rule.Requests > 0 && rule.Window <= 0) {
+			return nil, fmt.Errorf("RATE_LIMIT_%s_REQUESTS must not be negative and RATE_LIMIT_%s_WINDOW must be positive", name, name)
+		}
+	}
+
+	switch cfg.Store {
+	case config.RateLimitStoreMemory:
+		return NewMemoryStore(), nil
+	case config.RateLimitStoreDatabase:
+		return repo, nil
+	default:
+		return nil, fmt.Errorf("unsupported rate limit store %q", cfg.Store)
+	}
+}
+
+// Allow counts a request against key
+func (l *Limiter) Allow(key string) (middleware.RateLimitResult, error) {
+	if l.requests <= 0 {
+		return middleware.RateLimitResult{Allowed: true}, nil
+	}
+
+	now := l.now()
+	windowStart := now.Truncate(l.window)
+	elapsed := now.Sub(windowStart)
+
+	current, previous, err := l.store.IncrementRateLimitCounter(l.name+":"+key, windowStart, windowStart.Add(-l.window), windowStart.Add(2*l.window))
+	if err != nil {
+		return middleware.RateLimitResult{}, err
+	}
+
+	limit := float64(l.requests)
+	estimate := float64(previous)*(1-elapsed.Seconds()/l.window.Seconds()) + float64(current)
+
+	result := middleware.RateLimitResult{
+		Allowed:   estimate <= limit,
+		Limit:     l.requests,
+		Window:    l.window,
+		Remaining: max(0, int(math.Floor(limit-estimate))),
+		Reset:     l.window - elapsed,
+	}
+	if !result.Allowed {
+		result.Reset = l.retryAfter(current, previous, elapsed)
+	}
+	return result, nil
+}
+
+// retryAfter returns how long a refused caller has to wait until another request is allowed,
+// assuming it sends none in the meantime
+func (l *Limiter) retryAfter(current, previous int, elapsed time.Duration) time.Duration {
+	window := float64(l.window)
+	limit := float64(l.requests)
+
+	// Within the current window, once enough of the previous window has slid out
+	if current < l.requests && previous > 0 {
+		wait := window*(1-(limit-float64(current)-1)/float64(previous)) - float64(elapsed)
+		return max(0, time.Duration(wait))
+	}
+
+	// Otherwise in the next window, once enough of the current one has slid out
+	wait := window * (1 - (limit-1)/float64(current))
+	return l.window - elapsed + max(0, time.Duration(wait))
+}
+
+// Run removes expired counters from store until ctx is cancelled
+func Run(ctx context.Context, store Store) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := store.DeleteExpiredRateLimitCounters(time.Now()); err != nil {
+			log.Printf("Failed to delete expired rate limit counters: %v", err)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"go-backend-valos-id/core/config"
+	"go-backend-valos-id/core/middleware"
+)
+
+// windowStart is aligned to whole minutes, so offsets from it are offsets into a window
+var windowStart = time.Unix(1_700_000_040, 0)
+
+// burst sends requests at once, at an offset from windowStart
+type burst struct {
+	at       time.Duration
+	requests int
+}
+
+// testLimiter returns a limiter on a new MemoryStore whose clock is set by the returned function
+func testLimiter(rule config.RateLimitRule) (*Limiter, func(at time.Duration)) {
+	limiter := NewLimiter(NewMemoryStore(), "test", rule)
+	now := windowStart
+	limiter.now = func() time.Time { return now }
+	return limiter, func(at time.Duration) { now = windowStart.Add(at) }
+}
+
+// send replays bursts and returns the result of the last request
+func send(t *testing.T, limiter *Limiter, setClock func(time.Duration), bursts []burst) middleware.RateLimitResult {
+	t.Helper()
+	var result middleware.RateLimitResult
+	for _, b := range bursts {
+		setClock(b.at)
+		for i := 0; i < b.requests; i++ {
+			var err error
+			if result, err = limiter.Allow("203.0.113.1"); err != nil {
+				t.Fatalf("Allow: %v", err)
+			}
+		}
+	}
+	return result
+}
+
+func TestLimiterAllow(t *testing.T) {
+	rule := config.RateLimitRule{Requests: 10, Window: time.Minute}
+
+	tests := []struct {
+		name   string
+		bursts []burst
+		want   middleware.RateLimitResult
+	}{
+		{
+			name:   "first request",
+			bursts: []burst{{0, 1}},
+			want:   middleware.RateLimitResult{Allowed: true, Remaining: 9, Reset: time.Minute},
+		},
+		{
+			name:   "last request of the window",
+			bursts: []burst{{0, 9}, {time.Minute - time.Millisecond, 1}},
+			want:   middleware.RateLimitResult{Allowed: true, Remaining: 0, Reset: time.Millisecond},
+		},
+		{
+			name:   "over the limit",
+			bursts: []burst{{0, 11}},
+			want:   middleware.RateLimitResult{Allowed: false, Remaining: 0},
+		},
+		{
+			// The previous window still counts in full at the start of the next one
+			name:   "window boundary",
+			bursts: []burst{{59 * time.Second, 10}, {time.Minute, 1}},
+			want:   middleware.RateLimitResult{Allowed: false, Remaining: 0},
+		},
+		{
+			name:   "half of the previous window",
+			bursts: []burst{{0, 10}, {90 * time.Second, 1}},
+			want:   middleware.RateLimitResult{Allowed: true, Remaining: 4, Reset: 30 * time.Second},
+		},
+		{
+			name:   "previous window forgotten",
+			bursts: []burst{{0, 10}, {2 * time.Minute, 1}},
+			want:   middleware.RateLimitResult{Allowed: true, Remaining: 9, Reset: time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, setClock := testLimiter(rule)
+			got := send(t, limiter, setClock, tt.bursts)
+
+			if got.Allowed != tt.want.Allowed || got.Remaining != tt.want.Remaining {
+				t.Fatalf("Allow = %+v, want allowed %v with %d remaining", got, tt.want.Allowed, tt.want.Remaining)
+			}
+			if got.Limit != rule.Requests || got.Window != rule.Window {
+				t.Fatalf("Allow = %+v, want limit %d per %v", got, rule.Requests, rule.Window)
+			}
+			if tt.want.Allowed && got.Reset != tt.want.Reset {
+				t.Fatalf("Reset = %v, want %v", got.Reset, tt.want.Reset)
+			}
+		})
+	}
+}
+
+func TestLimiterRetryAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		rule config.RateLimitRule
+		// bursts end with a refused request
+		bursts []burst
+	}{
+		{
+			name:   "within the window",
+			rule:   config.RateLimitRule{Requests: 10, Window: time.Minute},
+			bursts: []burst{{0, 10}, {30 * time.Second, 1}},
+		},
+		{
+			name:   "at the window boundary",
+			rule:   config.RateLimitRule{Requests: 10, Window: time.Minute},
+			bursts: []burst{{59 * time.Second, 10}, {time.Minute, 1}},
+		},
+		{
+			name:   "early in the next window",
+			rule:   config.RateLimitRule{Requests: 10, Window: time.Minute},
+			bursts: []burst{{30 * time.Second, 10}, {61 * time.Second, 1}},
+		},
+		{
+			name:   "both windows full",
+			rule:   config.RateLimitRule{Requests: 10, Window: time.Minute},
+			bursts: []burst{{0, 10}, {time.Minute, 10}, {90 * time.Second, 1}},
+		},
+		{
+			name:   "kept retrying",
+			rule:   config.RateLimitRule{Requests: 10, Window: time.Minute},
+			bursts: []burst{{0, 100}},
+		},
+		{
+			name:   "one request per window",
+			rule:   config.RateLimitRule{Requests: 1, Window: time.Minute},
+			bursts: []burst{{0, 1}, {time.Minute + time.Second, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, setClock := testLimiter(tt.rule)
+			refused := send(t, limiter, setClock, tt.bursts)
+			if refused.Allowed {
+				t.Fatalf("Allow = %+v, want refused", refused)
+			}
+			if refused.Reset <= 0 || refused.Reset > 2*tt.rule.Window {
+				t.Fatalf("Reset = %v, want within (0, %v]", refused.Reset, 2*tt.rule.Window)
+			}
+
+			// Replaying the same requests, the next one is refused just before Reset and allowed just after it
+			last := tt.bursts[len(tt.bursts)-1].at
+			for _, probe := range []struct {
+				at          time.Duration
+				wantAllowed bool
+			}{
+				{last + refused.Reset - time.Millisecond, false},
+				{last + refused.Reset + time.Millisecond, true},
+			} {
+				limiter, setClock := testLimiter(tt.rule)
+				got := send(t, limiter, setClock, append(tt.bursts, burst{probe.at, 1}))
+				if got.Allowed != probe.wantAllowed {
+					t.Fatalf("Allow %v after the refused request = %v, want %v (Reset %v)", probe.at-last, got.Allowed, probe.wantAllowed, refused.Reset)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterResetNeverNegative(t *testing.T) {
+	rule := config.RateLimitRule{Requests: 5, Window: time.Minute}
+	limiter, setClock := testLimiter(rule)
+
+	// A caller sending two requests every second, across several windows
+	for at := time.Duration(0); at < 3*rule.Window; at += 500 * time.Millisecond {
+		result := send(t, limiter, setClock, []burst{{at, 1}})
+		if result.Reset < 0 || result.Reset > 2*rule.Window {
+			t.Fatalf("Reset at %v = %v, want within [0, %v]", at, result.Reset, 2*rule.Window)
+		}
+		if result.Remaining < 0 {
+			t.Fatalf("Remaining at %v = %d, want at least 0", at, result.Remaining)
+		}
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	limiter, setClock := testLimiter(config.RateLimitRule{})
+	result := send(t, limiter, setClock, []burst{{0, 1000}})
+	if !result.Allowed || result.Limit != 0 {
+		t.Fatalf("Allow = %+v, want allowed without a limit", result)
+	}
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-backend-valos-id/core/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CounterRepository keeps rate limit counters in the database, shared by every instance
+type CounterRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewCounterRepository(pool *pgxpool.Pool) *CounterRepository {
+	return &CounterRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// IncrementRateLimitCounter counts a request against key in the window starting at windowStart,
+// returning the window's count and the count of the window before it
+func (r *CounterRepository) IncrementRateLimitCounter(key string, windowStart, previousWindowStart, expiresAt time.Time) (int, int, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.IncrementRateLimitCounter(ctx, repository.IncrementRateLimitCounterParams{
+		Key:                 key,
+		WindowStart:         windowStart.UnixMilli(),
+		ExpiresAt:           expiresAt.UnixMilli(),
+		PreviousWindowStart: previousWindowStart.UnixMilli(),
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int(result.CurrentCount), int(result.PreviousCount), nil
+}
+
+// DeleteExpiredRateLimitCounters removes counters that expired before now
+func (r *CounterRepository) DeleteExpiredRateLimitCounters(now time.Time) (int64, error) {
+	ctx := context.Background()
+
+	return r.queries.DeleteExpiredRateLimitCounters(ctx, now.UnixMilli())
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Store keeps request counters per key and fixed window. repository.CounterRepository shares them
+// between instances through the database; MemoryStore keeps them in-process.
+type Store interface {
+	// IncrementRateLimitCounter counts a request against key in the window starting at windowStart,
+	// returning the window's count and the count of the window before it. The counter may be
+	// forgotten after expiresAt.
+	IncrementRateLimitCounter(key string, windowStart, previousWindowStart, expiresAt time.Time) (int, int, error)
+	// DeleteExpiredRateLimitCounters removes counters that expired before now
+	DeleteExpiredRateLimitCounters(now time.Time) (int64, error)
+}
+
+type counterKey struct {
+	key         string
+	windowStart int64
+}
+
+type counter struct {
+	count     int
+	expiresAt time.Time
+}
+
+// MemoryStore is a Store for single instance deployments
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[counterKey]*counter
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[counterKey]*counter)}
+}
+
+func (s *MemoryStore) IncrementRateLimitCounter(key string, windowStart, previousWindowStart, expiresAt time.Time) (int, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.counters[counterKey{key, windowStart.UnixMilli()}]
+	if !ok {
+		current = &counter{expiresAt: expiresAt}
+		s.counters[counterKey{key, windowStart.UnixMilli()}] = current
+	}
+	current.count++
+
+	var previous int
+	if c, ok := s.counters[counterKey{key, previousWindowStart.UnixMilli()}]; ok {
+		previous = c.count
+	}
+	return current.count, previous, nil
+}
+
+func (s *MemoryStore) DeleteExpiredRateLimitCounters(now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for key, c := range s.counters {
+		if c.expiresAt.Before(now) {
+			delete(s.counters, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
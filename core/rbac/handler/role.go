@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go-backend-valos-id/core/audit"
+	"go-backend-valos-id/core/rbac"
+	"go-backend-valos-id/core/rbac/model"
+	"go-backend-valos-id/core/rbac/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler serves the administration of roles and of the roles held by users
+type RoleHandler struct {
+	roleRepo   *repository.RoleRepository
+	authorizer *rbac.Authorizer
+}
+
+func NewRoleHandler(roleRepo *repository.RoleRepository, authorizer *rbac.Authorizer) *RoleHandler {
+	return &RoleHandler{
+		roleRepo:   roleRepo,
+		authorizer: authorizer,
+	}
+}
+
+// ListRoles lists every role with its permissions
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleRepo.ListRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve roles",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  roles,
+		"count": len(roles),
+	})
+}
+
+// CreateRole creates a role with the given permissions
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req model.RoleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	role := &model.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: nonNil(req.Permissions),
+	}
+	if err := h.roleRepo.CreateRole(role, audit.OriginFromContext(c)); err != nil {
+		h.roleError(c, err, "Failed to create role")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Role created successfully",
+		"data":    role,
+	})
+}
+
+// GetRole returns a role with its permissions
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, ok := h.findRole(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": role,
+	})
+}
+
+// UpdateRole renames a role and replaces its permissions; omitting permissions keeps them.
+// Built-in roles keep their names, and admin always holds every permission.
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	role, ok := h.findRole(c)
+	if !ok {
+		return
+	}
+
+	var req model.RoleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if role.BuiltIn() && req.Name != role.Name {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Built-in roles cannot be renamed",
+		})
+		return
+	}
+	if role.Name == model.RoleAdmin && req.Permissions != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "The permissions of the admin role cannot be changed",
+		})
+		return
+	}
+
+	role.Name = req.Name
+	role.Description = req.Description
+	if req.Permissions != nil {
+		role.Permissions = req.Permissions
+	}
+	if err := h.roleRepo.UpdateRole(role, audit.OriginFromContext(c)); err != nil {
+		h.roleError(c, err, "Failed to update role")
+		return
+	}
+	h.authorizer.RolesChanged()
+
+	updated, err := h.roleRepo.GetRole(role.ID)
+	if err != nil {
+		h.roleError(c, err, "Failed to retrieve role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"data":    updated,
+	})
+}
+
+// DeleteRole deletes a role that is not built in and takes it away from every user
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	role, ok := h.findRole(c)
+	if !ok {
+		return
+	}
+
+	if role.BuiltIn() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Built-in roles cannot be deleted",
+		})
+		return
+	}
+
+	if err := h.roleRepo.DeleteRole(role.ID, audit.OriginFromContext(c)); err != nil {
+		h.roleError(c, err, "Failed to delete role")
+		return
+	}
+	h.authorizer.RolesChanged()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role deleted successfully",
+	})
+}
+
+// ListPermissions lists the permissions roles can be given
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.roleRepo.ListPermissions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve permissions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  permissions,
+		"count": len(permissions),
+	})
+}
+
+// ListUserRoles lists the roles held by a user
+func (h *RoleHandler) ListUserRoles(c *gin.Context) {
+	userID, err := parseID(c.Param("id"), "user")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	roles, err := h.roleRepo.ListUserRoles(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve roles",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  roles,
+		"count": len(roles),
+	})
+}
+
+// AssignUserRole gives a user a role
+func (h *RoleHandler) AssignUserRole(c *gin.Context) {
+	userID, err := parseID(c.Param("id"), "user")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var req model.UserRoleAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if err := h.roleRepo.AssignRole(userID, req.RoleID, audit.OriginFromContext(c)); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		h.roleError(c, err, "Failed to assign role")
+		return
+	}
+	h.authorizer.UserRolesChanged(userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role assigned successfully",
+	})
+}
+
+// RemoveUserRole takes a role away from a user. The last admin cannot lose the admin role.
+func (h *RoleHandler) RemoveUserRole(c *gin.Context) {
+	userID, err := parseID(c.Param("id"), "user")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	roleID, err := parseID(c.Param("role_id"), "role")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	removed, err := h.roleRepo.RemoveRole(userID, roleID, audit.OriginFromContext(c))
+	if err != nil {
+		if errors.Is(err, repository.ErrLastAdmin) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Cannot remove the admin role from the last admin",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to remove role",
+		})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "User does not hold this role",
+		})
+		return
+	}
+	h.authorizer.UserRolesChanged(userID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role removed successfully",
+	})
+}
+
+// Helper methods
+
+// findRole loads the role named by the :id parameter, writing the error response if there is none
+func (h *RoleHandler) findRole(c *gin.Context) (*model.Role, bool) {
+	roleID, err := parseID(c.Param("id"), "role")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+
+	role, err := h.roleRepo.GetRole(roleID)
+	if err != nil {
+		h.roleError(c, err, "Failed to retrieve role")
+		return nil, false
+	}
+	return role, true
+}
+
+func (h *RoleHandler) roleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Role not found",
+		})
+	case errors.Is(err, repository.ErrRoleExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Role with this name already exists",
+		})
+	case errors.Is(err, repository.ErrUnknownPermission):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Unknown permission",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": message,
+		})
+	}
+}
+
+func parseID(value, name string) (int32, error) {
+	id, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s ID", name)
+	}
+	if id <= 0 {
+		return 0, fmt.Errorf("%s ID must be positive", name)
+	}
+	return int32(id), nil
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Built-in roles, seeded by migration. Their names are fixed since code refers to them.
+const (
+	// RoleAdmin holds every permission
+	RoleAdmin = "admin"
+	// RoleUser is given to every account on registration
+	RoleUser = "user"
+)
+
+// Permissions checked by the API
+const (
+	PermissionUsersRead   = "users:read"
+	PermissionUsersUpdate = "users:update"
+	PermissionUsersDelete = "users:delete"
+	PermissionRolesManage = "roles:manage"
+
+	PermissionServiceAccountsManage = "service_accounts:manage"
+	PermissionAuditRead             = "audit:read"
+
+	// Organization permissions gate the organization routes; organization roles decide the rest
+	PermissionOrganizationsRead   = "organizations:read"
+	PermissionOrganizationsManage = "organizations:manage"
+)
+
+type Role struct {
+	ID          int32     `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// BuiltIn reports whether the role is one of the seeded roles, which cannot be renamed or deleted
+func (r *Role) BuiltIn() bool {
+	return IsBuiltInRole(r.Name)
+}
+
+// IsBuiltInRole reports whether name is the name of a seeded role
+func IsBuiltInRole(name string) bool {
+	return slices.Contains([]string{RoleAdmin, RoleUser}, name)
+}
+
+type Permission struct {
+	ID          int32     `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type RoleCreateRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=50"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+type RoleUpdateRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=50"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+type UserRoleAssignRequest struct {
+	RoleID int32 `json:"role_id" binding:"required"`
+}
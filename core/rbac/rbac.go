@@ -0,0 +1,49 @@
+// Package rbac answers whether a user may perform an action, from the permissions of the roles they hold.
+package rbac
+
+import (
+	"slices"
+	"time"
+
+	"go-backend-valos-id/core/rbac/repository"
+	"go-backend-valos-id/core/utils"
+)
+
+// Authorizer checks user permissions. Each user's permissions are cached in-process;
+// changes made through this instance take effect immediately, others within the cache TTL.
+type Authorizer struct {
+	roleRepo    *repository.RoleRepository
+	permissions *utils.TTLCache[int32, []string]
+}
+
+func NewAuthorizer(roleRepo *repository.RoleRepository, cacheTTL time.Duration) *Authorizer {
+	return &Authorizer{
+		roleRepo:    roleRepo,
+		permissions: utils.NewTTLCache[int32, []string](cacheTTL),
+	}
+}
+
+// HasPermission reports whether any of the user's roles grants permission
+func (a *Authorizer) HasPermission(userID int32, permission string) (bool, error) {
+	permissions, ok := a.permissions.Get(userID)
+	if !ok {
+		var err error
+		permissions, err = a.roleRepo.ListUserPermissions(userID)
+		if err != nil {
+			return false, err
+		}
+		a.permissions.Set(userID, permissions)
+	}
+
+	return slices.Contains(permissions, permission), nil
+}
+
+// UserRolesChanged drops the cached permissions of a user whose roles changed
+func (a *Authorizer) UserRolesChanged(userID int32) {
+	a.permissions.Delete(userID)
+}
+
+// RolesChanged drops every cached permission after a role's permissions changed or a role was deleted
+func (a *Authorizer) RolesChanged() {
+	a.permissions.Clear()
+}
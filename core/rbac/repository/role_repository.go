@@ -0,0 +1,453 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	audit_model "go-backend-valos-id/core/audit/model"
+	audit_repository "go-backend-valos-id/core/audit/repository"
+	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/rbac/model"
+	"go-backend-valos-id/core/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrRoleNotFound = errors.New("role not found")
+	ErrRoleExists   = errors.New("role with this name already exists")
+	// ErrUnknownPermission is returned when a role is given a permission that does not exist
+	ErrUnknownPermission = errors.New("unknown permission")
+	// ErrLastAdmin is returned when removing the admin role from its last holder
+	ErrLastAdmin = errors.New("cannot remove the last admin")
+	// ErrUserNotFound is returned when assigning a role to a user that does not exist
+	ErrUserNotFound = errors.New("user not found")
+)
+
+type RoleRepository struct {
+	pool    *pgxpool.Pool
+	queries *repository.Queries
+}
+
+func NewRoleRepository(pool *pgxpool.Pool) *RoleRepository {
+	return &RoleRepository{
+		pool:    pool,
+		queries: repository.New(pool),
+	}
+}
+
+// CreateRole creates a role with the given permissions, recording it in the audit log
+func (r *RoleRepository) CreateRole(role *model.Role, origin *audit_model.Origin) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	result, err := qtx.CreateRole(ctx, repository.CreateRoleParams{
+		Name:        role.Name,
+		Description: role.Description,
+		CreatedAt:   utils.ToEpochMillis(now),
+		UpdatedAt:   utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return roleError(err)
+	}
+
+	if err := r.setPermissions(ctx, qtx, result.ID, role.Permissions); err != nil {
+		return err
+	}
+	after, err := r.roleSnapshot(ctx, qtx, &result)
+	if err != nil {
+		return err
+	}
+	if err := audit_repository.Record(ctx, qtx, origin, audit_model.ActionRoleCreated, audit_model.TargetRole, roleTargetID(result.ID), nil, after); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	role.ID = result.ID
+	role.CreatedAt = now
+	role.UpdatedAt = now
+	return nil
+}
+
+// GetRole returns a role with its permissions
+func (r *RoleRepository) GetRole(id int32) (*model.Role, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetRoleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	roles := []*model.Role{r.sqlcRoleToModel(&result)}
+	if err := r.loadPermissions(ctx, roles); err != nil {
+		return nil, err
+	}
+	return roles[0], nil
+}
+
+// GetRoleByName returns a role without its permissions
+func (r *RoleRepository) GetRoleByName(name string) (*model.Role, error) {
+	ctx := context.Background()
+
+	result, err := r.queries.GetRoleByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	return r.sqlcRoleToModel(&result), nil
+}
+
+// ListRoles returns every role with its permissions
+func (r *RoleRepository) ListRoles() ([]*model.Role, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]*model.Role, len(results))
+	for i := range results {
+		roles[i] = r.sqlcRoleToModel(&results[i])
+	}
+	if err := r.loadPermissions(ctx, roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// UpdateRole renames a role and replaces its permissions, recording it in the audit log
+func (r *RoleRepository) UpdateRole(role *model.Role, origin *audit_model.Origin) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	existing, err := qtx.GetRoleByIDForUpdate(ctx, role.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+	before, err := r.roleSnapshot(ctx, qtx, &existing)
+	if err != nil {
+		return err
+	}
+
+	err = qtx.UpdateRole(ctx, repository.UpdateRoleParams{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		UpdatedAt:   utils.ToEpochMillis(now),
+	})
+	if err != nil {
+		return roleError(err)
+	}
+
+	if err := qtx.DeleteRolePermissions(ctx, role.ID); err != nil {
+		return err
+	}
+	if err := r.setPermissions(ctx, qtx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	existing.Name = role.Name
+	existing.Description = role.Description
+	after, err := r.roleSnapshot(ctx, qtx, &existing)
+	if err != nil {
+		return err
+	}
+	if err := audit_repository.Record(ctx, qtx, origin, audit_model.ActionRoleUpdated, audit_model.TargetRole, roleTargetID(role.ID), before, after); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	role.UpdatedAt = now
+	return nil
+}
+
+// DeleteRole deletes a role and removes it from every user holding it, recording it in the audit log
+func (r *RoleRepository) DeleteRole(id int32, origin *audit_model.Origin) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	existing, err := qtx.GetRoleByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+	before, err := r.roleSnapshot(ctx, qtx, &existing)
+	if err != nil {
+		return err
+	}
+
+	if err := qtx.DeleteRole(ctx, id); err != nil {
+		return err
+	}
+	if err := audit_repository.Record(ctx, qtx, origin, audit_model.ActionRoleDeleted, audit_model.TargetRole, roleTargetID(id), before, nil); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ListPermissions returns every permission that roles can be given
+func (r *RoleRepository) ListPermissions() ([]*model.Permission, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make([]*model.Permission, len(results))
+	for i, result := range results {
+		permissions[i] = &model.Permission{
+			ID:          result.ID,
+			Name:        result.Name,
+			Description: result.Description,
+			CreatedAt:   utils.FromEpochMillis(result.CreatedAt),
+		}
+	}
+	return permissions, nil
+}
+
+// ListUserRoles returns the roles of a user, without their permissions
+func (r *RoleRepository) ListUserRoles(userID int32) ([]*model.Role, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]*model.Role, len(results))
+	for i := range results {
+		roles[i] = r.sqlcRoleToModel(&results[i])
+	}
+	return roles, nil
+}
+
+// ListUserPermissions returns the names of every permission a user holds through their roles
+func (r *RoleRepository) ListUserPermissions(userID int32) ([]string, error) {
+	ctx := context.Background()
+
+	return r.queries.ListUserPermissionNames(ctx, userID)
+}
+
+// AssignRole gives a user a role, recording it in the audit log; assigning a role the user already holds does nothing
+func (r *RoleRepository) AssignRole(userID, roleID int32, origin *audit_model.Origin) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	role, err := qtx.GetRoleByID(ctx, roleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+
+	rows, err := qtx.AssignUserRole(ctx, repository.AssignUserRoleParams{
+		UserID:    userID,
+		RoleID:    roleID,
+		CreatedAt: utils.ToEpochMillis(time.Now()),
+	})
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			if pgErr.ConstraintName == "user_roles_role_id_fkey" {
+				return ErrRoleNotFound
+			}
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if rows > 0 {
+		if err := r.recordRoleChange(ctx, qtx, origin, audit_model.ActionUserRoleAssigned, userID, nil, &role); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// RemoveRole takes a role away from a user. It reports false if the user did not hold the role,
+// and refuses to remove the admin role from the last admin.
+func (r *RoleRepository) RemoveRole(userID, roleID int32, origin *audit_model.Origin) (bool, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	// Locking the role serializes concurrent removals, so two admins cannot remove each other
+	role, err := qtx.GetRoleByIDForUpdate(ctx, roleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	rows, err := qtx.RemoveUserRole(ctx, repository.RemoveUserRoleParams{
+		UserID: userID,
+		RoleID: roleID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if rows > 0 && role.Name == model.RoleAdmin {
+		remaining, err := qtx.CountUsersWithRole(ctx, roleID)
+		if err != nil {
+			return false, err
+		}
+		if remaining == 0 {
+			return false, ErrLastAdmin
+		}
+	}
+
+	if rows > 0 {
+		if err := r.recordRoleChange(ctx, qtx, origin, audit_model.ActionUserRoleRemoved, userID, &role, nil); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// recordRoleChange writes an audit event about the roles of a user in the transaction of qtx.
+// before and after hold the role taken away or given, if any.
+func (r *RoleRepository) recordRoleChange(ctx context.Context, qtx *repository.Queries, origin *audit_model.Origin, action string, userID int32, before, after *repository.Role) error {
+	snapshot := func(role *repository.Role) any {
+		if role == nil {
+			return nil
+		}
+		return map[string]any{"role_id": role.ID, "role": role.Name}
+	}
+	return audit_repository.Record(ctx, qtx, origin, action, audit_model.TargetUser, strconv.FormatInt(int64(userID), 10), snapshot(before), snapshot(after))
+}
+
+// roleSnapshot is the state of a role recorded in the audit log: its name, description and permissions
+func (r *RoleRepository) roleSnapshot(ctx context.Context, qtx *repository.Queries, role *repository.Role) (map[string]any, error) {
+	permissions, err := qtx.ListRolePermissionNamesByRole(ctx, role.ID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"name":        role.Name,
+		"description": role.Description,
+		"permissions": permissions,
+	}, nil
+}
+
+func roleTargetID(id int32) string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// setPermissions grants the named permissions to a role after checking that they all exist
+func (r *RoleRepository) setPermissions(ctx context.Context, qtx *repository.Queries, roleID int32, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+	permissions = slices.Compact(slices.Sorted(slices.Values(permissions)))
+
+	count, err := qtx.CountPermissionsByName(ctx, permissions)
+	if err != nil {
+		return err
+	}
+	if count != int64(len(permissions)) {
+		return ErrUnknownPermission
+	}
+
+	return qtx.AddRolePermissions(ctx, repository.AddRolePermissionsParams{
+		RoleID: roleID,
+		Names:  permissions,
+	})
+}
+
+// loadPermissions fills in the permissions of roles
+func (r *RoleRepository) loadPermissions(ctx context.Context, roles []*model.Role) error {
+	results, err := r.queries.ListRolePermissionNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	byRole := make(map[int32][]string)
+	for _, result := range results {
+		byRole[result.RoleID] = append(byRole[result.RoleID], result.Name)
+	}
+	for _, role := range roles {
+		role.Permissions = byRole[role.ID]
+		if role.Permissions == nil {
+			role.Permissions = []string{}
+		}
+	}
+	return nil
+}
+
+// roleError maps a unique violation on the role name to ErrRoleExists
+func roleError(err error) error {
+	if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+		return ErrRoleExists
+	}
+	return err
+}
+
+// Helper method to convert sqlc Role to model Role
+func (r *RoleRepository) sqlcRoleToModel(sqlcRole *repository.Role) *model.Role {
+	return &model.Role{
+		ID:          sqlcRole.ID,
+		Name:        sqlcRole.Name,
+		Description: sqlcRole.Description,
+		CreatedAt:   utils.FromEpochMillis(sqlcRole.CreatedAt),
+		UpdatedAt:   utils.FromEpochMillis(sqlcRole.UpdatedAt),
+	}
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"go-backend-valos-id/core/audit"
+	audit_model "go-backend-valos-id/core/audit/model"
+	audit_repository "go-backend-valos-id/core/audit/repository"
+	"go-backend-valos-id/core/auth/keys"
+	auth_repository "go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/config"
+	"go-backend-valos-id/core/db"
+	rbac_repository "go-backend-valos-id/core/rbac/repository"
+	user_repository "go-backend-valos-id/core/user/repository"
+)
+
+// RunCommand runs an administrative command, such as "keys rotate", instead of the server
+func RunCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no command given")
+	}
+
+	switch args[0] {
+	case "keys":
+		return runKeysCommand(args[1:])
+	case "roles":
+		return runRolesCommand(args[1:])
+	case "audit":
+		return runAuditCommand(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+// runKeysCommand manages the database key store: "keys list" shows the published keys,
+// "keys rotate" activates a new key immediately. Running servers pick it up within JWT_KEY_CACHE_TTL.
+func runKeysCommand(args []string) error {
+	if len(args) != 1 || (args[0] != "list" && args[0] != "rotate") {
+		return errors.New("usage: keys list|rotate")
+	}
+
+	authConfig := config.NewAuthConfig()
+	if authConfig.JWTKeySource != config.KeySourceDatabase {
+		return fmt.Errorf("keys are managed in the database only when JWT_KEY_SOURCE is %q", config.KeySourceDatabase)
+	}
+
+	database, err := db.NewDatabase(config.NewDatabaseConfig())
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	store, err := keys.NewStoreFromConfig(authConfig, auth_repository.NewSigningKeyRepository(database.Pool))
+	if err != nil {
+		return err
+	}
+
+	if args[0] == "rotate" {
+		key, err := store.Rotate()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Activated signing key %s (%s)\n", key.KeyID, key.Algorithm)
+		return nil
+	}
+
+	stored, err := store.ListKeys()
+	if err != nil {
+		return err
+	}
+	for _, key := range stored {
+		expires := "-"
+		if key.ExpiresAt != nil {
+			expires = key.ExpiresAt.Format(time.RFC3339)
+		}
+		fmt.Printf("%-44s %-6s %-8s activated %s, published until %s\n",
+			key.KeyID, key.Algorithm, key.Status, key.ActivatedAt.Format(time.RFC3339), expires)
+	}
+	return nil
+}
+
+// runRolesCommand gives a user a role: "roles assign <email or username> <role>".
+// It bootstraps the first admin, who can then manage roles through the API.
+func runRolesCommand(args []string) error {
+	if len(args) != 3 || args[0] != "assign" {
+		return errors.New("usage: roles assign <email or username> <role>")
+	}
+
+	database, err := db.NewDatabase(config.NewDatabaseConfig())
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	user, err := user_repository.NewUserRepository(database.Pool).GetUserByIdentifier(args[1])
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("user %q not found", args[1])
+		}
+		return err
+	}
+
+	roleRepo := rbac_repository.NewRoleRepository(database.Pool)
+	role, err := roleRepo.GetRoleByName(args[2])
+	if err != nil {
+		if errors.Is(err, rbac_repository.ErrRoleNotFound) {
+			return fmt.Errorf("role %q not found", args[2])
+		}
+		return err
+	}
+
+	if err := roleRepo.AssignRole(user.ID, role.ID, nil); err != nil {
+		return err
+	}
+	fmt.Printf("Assigned role %s to %s\n", role.Name, user.Username)
+	return nil
+}
+
+// runAuditCommand checks the audit log: "audit verify" walks its hash chain and fails at the first break,
+// "audit export <file>" writes the whole log with its signed checkpoints to a JSON file for archiving, and
+// "audit verify <file>" checks such an export without the database. Checkpoint signatures are checked with
+// the keys of AUDIT_CHECKPOINT_KEY_FILE and AUDIT_CHECKPOINT_PUBLIC_KEYS_FILE.
+func runAuditCommand(args []string) error {
+	switch {
+	case len(args) == 1 && args[0] == "verify":
+	case len(args) == 2 && (args[0] == "verify" || args[0] == "export"):
+	default:
+		return errors.New("usage: audit verify [file]|export <file>")
+	}
+
+	checkpointKeys, err := audit.NewCheckpointKeysFromConfig(config.NewAuditConfig(), config.NewAuthConfig().Issuer)
+	if err != nil {
+		return err
+	}
+
+	if args[0] == "verify" && len(args) == 2 {
+		export, err := readExport(args[1])
+		if err != nil {
+			return err
+		}
+		verification, err := export.Verify(checkpointKeys)
+		if err != nil {
+			return err
+		}
+		return reportVerification(verification)
+	}
+
+	database, err := db.NewDatabase(config.NewDatabaseConfig())
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	auditRepo := audit_repository.NewAuditRepository(database.Pool)
+
+	if args[0] == "export" {
+		export, err := audit.NewExport(auditRepo, checkpointKeys)
+		if err != nil {
+			return err
+		}
+		if err := writeExport(args[1], export); err != nil {
+			return err
+		}
+		fmt.Printf("Exported %d events and %d checkpoints to %s\n", len(export.Events), len(export.Checkpoints), args[1])
+		return nil
+	}
+
+	verification, err := auditRepo.VerifyChain(checkpointKeys)
+	if err != nil {
+		return err
+	}
+	return reportVerification(verification)
+}
+
+// writeExport writes an audit export as indented JSON
+func writeExport(path string, export *audit.Export) error {
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// readExport reads an audit export written by writeExport
+func readExport(path string) (*audit.Export, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var export audit.Export
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid audit export: %w", err)
+	}
+	return &export, nil
+}
+
+// reportVerification prints an intact chain and turns a broken one into an error
+func reportVerification(verification *audit_model.Verification) error {
+	if verification.Break != nil {
+		return fmt.Errorf("audit chain broken at event %d: %s", verification.Break.EventID, verification.Break.Reason)
+	}
+	fmt.Printf("Audit chain intact: %d events and %d checkpoints verified, %d events recorded before chaining\n",
+		verification.EventsChecked, verification.CheckpointsChecked, verification.UnchainedEvents)
+	return nil
+}
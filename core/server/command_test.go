@@ -0,0 +1,184 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend-valos-id/core/audit"
+	"go-backend-valos-id/core/audit/model"
+	"go-backend-valos-id/core/auth/token"
+)
+
+// newCheckpointKey returns checkpoint keys signing with a new Ed25519 key and the path of its PEM public key
+func newCheckpointKey(t *testing.T) (*audit.CheckpointKeys, string) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "checkpoint.pub.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	key := &token.Key{Algorithm: token.AlgEdDSA, Private: private, Public: public}
+	key.ID, _ = token.Thumbprint(key)
+	return audit.NewCheckpointKeys("valos-id", key, nil), path
+}
+
+// newExport returns an export of three chained events checkpointed at the last one by keys
+func newExport(t *testing.T, keys *audit.CheckpointKeys) *audit.Export {
+	t.Helper()
+	export := &audit.Export{Keys: keys.PublicKeys()}
+	prevHash := ""
+	for i := int64(1); i <= 3; i++ {
+		event := &model.Event{
+			ID:         i,
+			Action:     model.ActionRoleCreated,
+			TargetType: model.TargetRole,
+			TargetID:   "editor",
+			Changes:    json.RawMessage(`{"after":{"name":"editor","permissions":["users:read"]}}`),
+			CreatedAt:  time.UnixMilli(1_700_000_000_000 + i),
+			PrevHash:   prevHash,
+		}
+		hash, err := model.ChainHash(prevHash, event)
+		if err != nil {
+			t.Fatalf("ChainHash: %v", err)
+		}
+		event.Hash = hash
+		prevHash = hash
+		export.Events = append(export.Events, event)
+	}
+
+	signature, err := keys.SignClaims(model.CheckpointClaims{
+		Issuer:    keys.Issuer(),
+		Purpose:   model.CheckpointPurpose,
+		EventID:   3,
+		EventHash: prevHash,
+	})
+	if err != nil {
+		t.Fatalf("SignClaims: %v", err)
+	}
+	export.Checkpoints = []model.Checkpoint{{ID: 1, EventID: 3, EventHash: prevHash, Signature: signature, CreatedAt: time.UnixMilli(1_700_000_001_000)}}
+	return export
+}
+
+func TestAuditVerifyExport(t *testing.T) {
+	keys, publicPath := newCheckpointKey(t)
+	otherKeys, _ := newCheckpointKey(t)
+
+	tests := []struct {
+		name string
+		// export returns the export to write; an empty wantErr means it must verify
+		export  func() *audit.Export
+		wantErr string
+	}{
+		{
+			name:   "intact",
+			export: func() *audit.Export { return newExport(t, keys) },
+		},
+		{
+			name: "edited event",
+			export: func() *audit.Export {
+				export := newExport(t, keys)
+				export.Events[1].TargetID = "admin"
+				return export
+			},
+			wantErr: "broken at event 2: event content does not match its hash",
+		},
+		{
+			name: "deleted event",
+			export: func() *audit.Export {
+				export := newExport(t, keys)
+				export.Events = append(export.Events[:1], export.Events[2:]...)
+				return export
+			},
+			wantErr: "broken at event 3: previous hash does not match the event before it",
+		},
+		{
+			name: "truncated log",
+			export: func() *audit.Export {
+				export := newExport(t, keys)
+				export.Events = export.Events[:2]
+				return export
+			},
+			wantErr: "broken at event 3: checkpointed event is missing",
+		},
+		{
+			// The keys in the export are not trusted: only the configured ones are
+			name:    "checkpoint signed with another key",
+			export:  func() *audit.Export { return newExport(t, otherKeys) },
+			wantErr: "broken at event 3: checkpoint signature is not valid",
+		},
+	}
+
+	// Only the public key is configured, as on a machine verifying archived exports
+	t.Setenv("AUDIT_CHECKPOINT_KEY_FILE", "")
+	t.Setenv("AUDIT_CHECKPOINT_PUBLIC_KEYS_FILE", publicPath)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.json")
+			if err := writeExport(path, tt.export()); err != nil {
+				t.Fatalf("writeExport: %v", err)
+			}
+
+			err := runAuditCommand([]string{"verify", path})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("audit verify: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("audit verify error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuditExportRoundTrip(t *testing.T) {
+	keys, _ := newCheckpointKey(t)
+	export := newExport(t, keys)
+
+	path := filepath.Join(t.TempDir(), "audit.json")
+	if err := writeExport(path, export); err != nil {
+		t.Fatalf("writeExport: %v", err)
+	}
+	read, err := readExport(path)
+	if err != nil {
+		t.Fatalf("readExport: %v", err)
+	}
+
+	if len(read.Events) != len(export.Events) || len(read.Checkpoints) != len(export.Checkpoints) {
+		t.Fatalf("read %d events and %d checkpoints, want %d and %d", len(read.Events), len(read.Checkpoints), len(export.Events), len(export.Checkpoints))
+	}
+	if len(read.Keys.Keys) != 1 || read.Keys.Keys[0].KeyID != export.Keys.Keys[0].KeyID {
+		t.Fatalf("keys = %+v, want %+v", read.Keys, export.Keys)
+	}
+	for i, event := range read.Events {
+		if event.Hash != export.Events[i].Hash || !event.CreatedAt.Equal(export.Events[i].CreatedAt) {
+			t.Fatalf("event %d = %+v, want %+v", event.ID, event, export.Events[i])
+		}
+	}
+
+	verification, err := read.Verify(keys)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if !verification.Valid || verification.EventsChecked != 3 || verification.CheckpointsChecked != 1 {
+		t.Fatalf("verification = %+v, want 3 events and 1 checkpoint verified", verification)
+	}
+}
@@ -1,15 +1,44 @@
 package server
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"sort"
 	"strings"
 
+	"go-backend-valos-id/core/audit"
+	audit_handler "go-backend-valos-id/core/audit/handler"
+	audit_repository "go-backend-valos-id/core/audit/repository"
+	"go-backend-valos-id/core/auth/apikey"
+	auth_handler "go-backend-valos-id/core/auth/handler"
+	"go-backend-valos-id/core/auth/keys"
+	"go-backend-valos-id/core/auth/lockout"
+	"go-backend-valos-id/core/auth/mfa"
+	auth_repository "go-backend-valos-id/core/auth/repository"
+	"go-backend-valos-id/core/auth/session"
+	"go-backend-valos-id/core/auth/token"
+	"go-backend-valos-id/core/auth/verification"
+	"go-backend-valos-id/core/auth/webauthn"
 	"go-backend-valos-id/core/config"
 	"go-backend-valos-id/core/db"
 	"go-backend-valos-id/core/handlers"
+	"go-backend-valos-id/core/mail"
 	"go-backend-valos-id/core/middleware"
+	"go-backend-valos-id/core/oauth"
+	oauth_handler "go-backend-valos-id/core/oauth/handler"
+	oauth_repository "go-backend-valos-id/core/oauth/repository"
+	org_handler "go-backend-valos-id/core/organization/handler"
+	org_repository "go-backend-valos-id/core/organization/repository"
+	"go-backend-valos-id/core/pagination"
+	"go-backend-valos-id/core/ratelimit"
+	ratelimit_repository "go-backend-valos-id/core/ratelimit/repository"
+	"go-backend-valos-id/core/rbac"
+	rbac_handler "go-backend-valos-id/core/rbac/handler"
+	rbac_model "go-backend-valos-id/core/rbac/model"
+	rbac_repository "go-backend-valos-id/core/rbac/repository"
 	user_handler "go-backend-valos-id/core/user/handler"
+	"go-backend-valos-id/core/user/purge"
 	user_repository "go-backend-valos-id/core/user/repository"
 
 	"github.com/gin-gonic/gin"
@@ -28,11 +57,40 @@ var methodOrder = map[string]int{
 }
 
 type Server struct {
-	router        *gin.Engine
-	pool          *pgxpool.Pool
-	healthHandler *handlers.HealthHandler
-	userHandler   *user_handler.UserHandler
-	database      *db.Database // Keep reference for cleanup
+	router          *gin.Engine
+	pool            *pgxpool.Pool
+	healthHandler   *handlers.HealthHandler
+	authHandler     *auth_handler.AuthHandler
+	sessionHandler  *auth_handler.SessionHandler
+	passwordHandler *auth_handler.PasswordHandler
+	emailHandler    *auth_handler.EmailVerificationHandler
+	mfaHandler      *auth_handler.MFAHandler
+	webauthnHandler *auth_handler.WebAuthnHandler
+	apiKeyHandler   *auth_handler.APIKeyHandler
+	lockoutHandler  *auth_handler.LockoutHandler
+	userHandler     *user_handler.UserHandler
+	serviceAccounts *user_handler.ServiceAccountHandler
+	oauthClients    *oauth_handler.ClientHandler
+	oauthAuthorize  *oauth_handler.AuthorizeHandler
+	oauthToken      *oauth_handler.TokenHandler
+	oauthUserInfo   *oauth_handler.UserInfoHandler
+	oauthIntrospect *oauth_handler.IntrospectionHandler
+	oauthRevoke     *oauth_handler.RevocationHandler
+	oidcDiscovery   *oauth_handler.DiscoveryHandler
+	roleHandler     *rbac_handler.RoleHandler
+	auditHandler    *audit_handler.AuditHandler
+	orgHandler      *org_handler.OrganizationHandler
+	invitations     *org_handler.InvitationHandler
+	tokenManager    *token.Manager
+	sessionGuard    *session.Guard
+	apiKeys         *apikey.Validator
+	authorizer      *rbac.Authorizer
+	rateLimits      *config.RateLimitConfig
+	trustedProxies  []string
+	rateLimitStore  ratelimit.Store
+	database        *db.Database // Keep reference for cleanup
+	// stopBackground cancels background jobs such as signing key rotation
+	stopBackground context.CancelFunc
 }
 
 func NewServer() *Server {
@@ -40,8 +98,18 @@ func NewServer() *Server {
 }
 
 func (s *Server) Initialize() error {
-	// Initialize database configuration
+	// Initialize configuration
 	dbConfig := config.NewDatabaseConfig()
+	authConfig := config.NewAuthConfig()
+	mailConfig := config.NewMailConfig()
+	webauthnConfig := config.NewWebAuthnConfig()
+	oauthConfig := config.NewOAuthConfig()
+	lockoutConfig := config.NewLockoutConfig()
+	auditConfig := config.NewAuditConfig()
+	userConfig := config.NewUserConfig()
+	paginationConfig := config.NewPaginationConfig()
+	s.rateLimits = config.NewRateLimitConfig()
+	s.trustedProxies = config.NewServerConfig().TrustedProxies
 
 	// Initialize database connection
 	database, err := db.NewDatabase(dbConfig)
@@ -51,26 +119,154 @@ func (s *Server) Initialize() error {
 	s.pool = database.Pool
 	s.database = database // Keep reference for cleanup
 
+	background, stopBackground := context.WithCancel(context.Background())
+	s.stopBackground = stopBackground
+
 	// Initialize repositories
 	userRepo := user_repository.NewUserRepository(s.pool)
+	refreshTokenRepo := auth_repository.NewRefreshTokenRepository(s.pool)
+	sessionRepo := auth_repository.NewSessionRepository(s.pool)
+	passwordResetRepo := auth_repository.NewPasswordResetRepository(s.pool)
+	emailVerificationRepo := auth_repository.NewEmailVerificationRepository(s.pool)
+	mfaRepo := auth_repository.NewMFARepository(s.pool)
+	webauthnRepo := auth_repository.NewWebAuthnRepository(s.pool)
+	apiKeyRepo := auth_repository.NewAPIKeyRepository(s.pool)
+	oauthClientRepo := oauth_repository.NewClientRepository(s.pool)
+	oauthTokenRepo := oauth_repository.NewTokenRepository(s.pool)
+	roleRepo := rbac_repository.NewRoleRepository(s.pool)
+	orgRepo := org_repository.NewOrganizationRepository(s.pool)
+	invitationRepo := org_repository.NewInvitationRepository(s.pool)
+	loginAttemptRepo := auth_repository.NewLoginAttemptRepository(s.pool)
+	auditRepo := audit_repository.NewAuditRepository(s.pool)
+
+	// Initialize mail delivery
+	mailer, err := mail.NewSenderFromConfig(mailConfig)
+	if err != nil {
+		return err
+	}
+
+	// Initialize token issuing
+	keyProvider, err := s.newKeyProvider(background, authConfig)
+	if err != nil {
+		return err
+	}
+	s.tokenManager = token.NewManager(authConfig, keyProvider)
+	s.sessionGuard = session.NewGuard(sessionRepo, userRepo, orgRepo, authConfig.SessionCacheTTL)
+	s.apiKeys = apikey.NewValidator(apiKeyRepo)
+	s.authorizer = rbac.NewAuthorizer(roleRepo, authConfig.PermissionCacheTTL)
+	emailVerifier := verification.NewEmailVerifier(emailVerificationRepo, mailer, mailConfig.AppBaseURL, authConfig.EmailVerificationTTL)
+
+	// Initialize brute-force protection
+	lockouts, err := lockout.NewTrackerFromConfig(lockoutConfig, loginAttemptRepo, userRepo, auditRepo)
+	if err != nil {
+		return err
+	}
+	go lockouts.Run(background)
+
+	// Initialize rate limiting
+	s.rateLimitStore, err = ratelimit.NewStoreFromConfig(s.rateLimits, ratelimit_repository.NewCounterRepository(s.pool))
+	if err != nil {
+		return err
+	}
+	go ratelimit.Run(background, s.rateLimitStore)
+
+	// Initialize purging of deleted users
+	go purge.NewPurgerFromConfig(userConfig, userRepo).Run(background)
+
+	// Initialize audit log checkpoints
+	checkpointKeys, err := audit.NewCheckpointKeysFromConfig(auditConfig, authConfig.Issuer)
+	if err != nil {
+		return err
+	}
+	if checkpointKeys.CanSign() {
+		go audit.NewCheckpointer(auditRepo, checkpointKeys, auditConfig.CheckpointInterval).Run(background)
+	}
+
+	// Initialize pagination cursors
+	cursors, err := pagination.NewSignerFromConfig(paginationConfig)
+	if err != nil {
+		return err
+	}
+
+	// Initialize MFA
+	mfaCipher, err := mfa.NewSecretCipherFromConfig(authConfig)
+	if err != nil {
+		return err
+	}
+	mfaService := mfa.NewService(mfaRepo, mfaCipher, authConfig.MFAIssuer)
 
 	// Initialize handlers
 	s.healthHandler = handlers.NewHealthHandler(s.pool)
-	s.userHandler = user_handler.NewUserHandler(userRepo)
+	s.authHandler = auth_handler.NewAuthHandler(userRepo, refreshTokenRepo, sessionRepo, s.sessionGuard, s.tokenManager, mfaService, orgRepo, lockouts, authConfig.RefreshTokenTTL, authConfig.RequireVerifiedEmail)
+	s.sessionHandler = auth_handler.NewSessionHandler(sessionRepo, s.sessionGuard)
+	s.passwordHandler = auth_handler.NewPasswordHandler(userRepo, sessionRepo, passwordResetRepo, s.sessionGuard, s.tokenManager, lockouts, mailer, mailConfig.AppBaseURL, authConfig.PasswordResetTTL)
+	s.emailHandler = auth_handler.NewEmailVerificationHandler(userRepo, emailVerificationRepo, emailVerifier)
+	s.mfaHandler = auth_handler.NewMFAHandler(userRepo, mfaService, lockouts)
+	s.webauthnHandler = auth_handler.NewWebAuthnHandler(userRepo, webauthnRepo, webauthn.NewRelyingParty(webauthnConfig), s.authHandler, webauthnConfig.ChallengeTTL)
+	s.apiKeyHandler = auth_handler.NewAPIKeyHandler(apiKeyRepo)
+	s.lockoutHandler = auth_handler.NewLockoutHandler(userRepo, lockouts)
+	s.userHandler = user_handler.NewUserHandler(userRepo, emailVerifier, s.sessionGuard, s.authorizer, cursors, authConfig.RequireVerifiedEmail)
+	s.serviceAccounts = user_handler.NewServiceAccountHandler(userRepo, s.sessionGuard)
+	s.oauthClients = oauth_handler.NewClientHandler(oauthClientRepo, oauthConfig.Scopes)
+	s.oauthAuthorize = oauth_handler.NewAuthorizeHandler(oauthClientRepo, oauthTokenRepo, userRepo, mfaService, s.tokenManager, lockouts, authConfig.Issuer, oauthConfig.AuthorizationCodeTTL, authConfig.RequireVerifiedEmail)
+	s.oauthToken = oauth_handler.NewTokenHandler(oauthClientRepo, oauthTokenRepo, userRepo, s.tokenManager, oauthConfig.RefreshTokenTTL)
+	s.oauthUserInfo = oauth_handler.NewUserInfoHandler(oauthClientRepo, oauthTokenRepo, userRepo, s.tokenManager)
+	s.oauthIntrospect = oauth_handler.NewIntrospectionHandler(oauthClientRepo, oauthTokenRepo, userRepo, s.tokenManager, s.sessionGuard.CheckClaims)
+	s.oauthRevoke = oauth_handler.NewRevocationHandler(oauthClientRepo, oauthTokenRepo, s.tokenManager)
+	s.oidcDiscovery = oauth_handler.NewDiscoveryHandler(s.tokenManager, oauthConfig.Scopes)
+	s.roleHandler = rbac_handler.NewRoleHandler(roleRepo, s.authorizer)
+	s.auditHandler = audit_handler.NewAuditHandler(auditRepo, checkpointKeys)
+	s.orgHandler = org_handler.NewOrganizationHandler(orgRepo, s.sessionGuard)
+	s.invitations = org_handler.NewInvitationHandler(orgRepo, invitationRepo, userRepo, s.sessionGuard, mailer, mailConfig.AppBaseURL, authConfig.InvitationTTL)
 
 	// Setup router
-	s.setupRouter()
+	if err := s.setupRouter(); err != nil {
+		return err
+	}
 
 	return nil
 }
 
-func (s *Server) setupRouter() {
+// newKeyProvider returns the signing keys selected by JWT_KEY_SOURCE. The database key store
+// gets an active key before the server starts and is rotated in the background until ctx is cancelled.
+func (s *Server) newKeyProvider(ctx context.Context, cfg *config.AuthConfig) (token.KeyProvider, error) {
+	switch cfg.JWTKeySource {
+	case config.KeySourceConfig:
+		provider, err := token.NewKeyProviderFromConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	case config.KeySourceDatabase:
+		store, err := keys.NewStoreFromConfig(cfg, auth_repository.NewSigningKeyRepository(s.pool))
+		if err != nil {
+			return nil, err
+		}
+		if err := store.RotateIfDue(); err != nil {
+			return nil, fmt.Errorf("failed to prepare signing key: %w", err)
+		}
+		go store.Run(ctx)
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unsupported JWT key source %q", cfg.JWTKeySource)
+	}
+}
+
+func (s *Server) setupRouter() error {
 	// Set Gin mode
 	gin.SetMode(gin.ReleaseMode)
 
 	// Create router
 	s.router = gin.New()
 
+	// Only believe forwarded client IPs from the configured proxies; Gin trusts every proxy by default
+	if err := s.router.SetTrustedProxies(s.trustedProxies); err != nil {
+		return fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+	if len(s.trustedProxies) == 0 {
+		log.Println("TRUSTED_PROXIES is not set, rate limits and lockouts count clients by connection address; behind a reverse proxy every client shares its address")
+	}
+
 	// Add middleware - use built-in gin.Logger for route logging
 	s.router.Use(middleware.RequestID())
 	s.router.Use(gin.Logger())
@@ -83,27 +279,178 @@ func (s *Server) setupRouter() {
 
 	// Log all registered routes on startup
 	logRegisteredRoutes(s.router)
+	return nil
 }
 
 func (s *Server) setupRoutes() {
-	// Health check routes
+	// Health check routes are public
 	s.router.GET("/ping", s.healthHandler.Ping)
 	s.router.GET("/health", s.healthHandler.HealthCheck)
 	s.router.GET("/ready", s.healthHandler.Readiness)
 	s.router.GET("/live", s.healthHandler.Liveness)
 
+	// Public routes are rate limited per IP address, authenticated API routes per user or API key
+	authLimit := middleware.RateLimit(ratelimit.NewLimiter(s.rateLimitStore, "auth", s.rateLimits.Auth), middleware.RateLimitByIP)
+	oauthLimit := middleware.RateLimit(ratelimit.NewLimiter(s.rateLimitStore, "oauth", s.rateLimits.OAuth), middleware.RateLimitByIP)
+	apiLimit := middleware.RateLimit(ratelimit.NewLimiter(s.rateLimitStore, "api", s.rateLimits.API), middleware.RateLimitByPrincipal)
+
+	// OAuth and OpenID Connect endpoints follow their specifications rather than the JSON API conventions
+	oauthRoutes := s.router.Group("", oauthLimit)
+	{
+		oauthRoutes.GET(oauth.DiscoveryPath, s.oidcDiscovery.Configuration)
+		oauthRoutes.GET(oauth.JWKSPath, s.oidcDiscovery.JWKS)
+		oauthRoutes.GET(oauth.AuthorizationPath, s.oauthAuthorize.Authorize)
+		oauthRoutes.POST(oauth.AuthorizationPath, s.oauthAuthorize.Submit)
+		oauthRoutes.POST(oauth.TokenPath, s.oauthToken.Token)
+		oauthRoutes.GET(oauth.UserInfoPath, s.oauthUserInfo.UserInfo)
+		oauthRoutes.POST(oauth.UserInfoPath, s.oauthUserInfo.UserInfo)
+		oauthRoutes.POST(oauth.IntrospectionPath, s.oauthIntrospect.Introspect)
+		oauthRoutes.POST(oauth.RevocationPath, s.oauthRevoke.Revoke)
+	}
+
+	authenticate := middleware.Authenticate(s.tokenManager, s.sessionGuard.CheckClaims)
+	// Routes that scripts may call also accept personal API keys; account and session management does not
+	authenticateAPI := middleware.AuthenticateAPIKey(s.apiKeys, authenticate)
+	requirePermission := func(permission string) gin.HandlerFunc {
+		return middleware.RequirePermission(s.authorizer, permission)
+	}
+
 	// API routes v1
 	v1 := s.router.Group("/api/v1")
 	{
-		// User routes
+		// Auth routes are public apart from logout
+		auth := v1.Group("/auth", authLimit)
+		{
+			auth.POST("/login", s.authHandler.Login)
+			auth.POST("/mfa/verify", s.authHandler.VerifyMFA)
+			auth.POST("/webauthn/login/begin", s.webauthnHandler.BeginLogin)
+			auth.POST("/webauthn/login/finish", s.webauthnHandler.FinishLogin)
+			auth.POST("/refresh", s.authHandler.Refresh)
+			auth.POST("/password/forgot", s.passwordHandler.ForgotPassword)
+			auth.POST("/password/reset", s.passwordHandler.ResetPassword)
+			auth.POST("/email/verify", s.emailHandler.VerifyEmail)
+			auth.POST("/email/resend", s.emailHandler.ResendVerification)
+			auth.POST("/logout", authenticate, s.sessionHandler.Logout)
+			auth.POST("/logout-all", authenticate, s.sessionHandler.LogoutAll)
+			auth.POST("/organization", authenticate, s.authHandler.SwitchOrganization)
+		}
+
+		// Routes acting on the authenticated user
+		me := v1.Group("/me", authenticate, apiLimit)
+		{
+			me.GET("/sessions", s.sessionHandler.ListSessions)
+			me.DELETE("/sessions/:id", s.sessionHandler.DeleteSession)
+			me.POST("/password", s.passwordHandler.ChangePassword)
+			me.GET("/mfa", s.mfaHandler.GetStatus)
+			me.POST("/mfa/totp", s.mfaHandler.EnrollTOTP)
+			me.POST("/mfa/totp/confirm", s.mfaHandler.ConfirmTOTP)
+			me.DELETE("/mfa/totp", s.mfaHandler.DisableTOTP)
+			me.POST("/webauthn/register/begin", s.webauthnHandler.BeginRegistration)
+			me.POST("/webauthn/register/finish", s.webauthnHandler.FinishRegistration)
+			me.GET("/webauthn/credentials", s.webauthnHandler.ListCredentials)
+			me.DELETE("/webauthn/credentials/:id", s.webauthnHandler.DeleteCredential)
+			me.POST("/api-keys", s.apiKeyHandler.CreateAPIKey)
+			me.GET("/api-keys", s.apiKeyHandler.ListAPIKeys)
+			me.DELETE("/api-keys/:id", s.apiKeyHandler.RevokeAPIKey)
+		}
+
+		// User routes, registration stays public
 		users := v1.Group("/users")
 		{
-			users.POST("", s.userHandler.CreateUser)
-			users.GET("", s.userHandler.GetAllUsers)
-			users.GET("/paginate", s.userHandler.GetUsersWithPagination)
-			users.GET("/:id", s.userHandler.GetUserByID)
-			users.PUT("/:id", s.userHandler.UpdateUser)
-			users.DELETE("/:id", s.userHandler.DeleteUser)
+			users.POST("", authLimit, s.userHandler.CreateUser)
+		}
+
+		// Users may read and update their own account; everything else needs a permission.
+		// Callers acting within an organization list its members instead of every user.
+		protectedUsers := users.Group("", authenticateAPI, apiLimit)
+		{
+			protectedUsers.GET("", s.userHandler.ListUsers)
+			protectedUsers.GET("/paginate", s.userHandler.GetUsersWithPagination)
+			protectedUsers.GET("/:id", s.userHandler.GetUserByID)
+			protectedUsers.PUT("/:id", s.userHandler.UpdateUser)
+			protectedUsers.DELETE("/:id", requirePermission(rbac_model.PermissionUsersDelete), s.userHandler.DeleteUser)
+			protectedUsers.POST("/:id/restore", requirePermission(rbac_model.PermissionUsersDelete), s.userHandler.RestoreUser)
+			protectedUsers.GET("/:id/status", requirePermission(rbac_model.PermissionUsersRead), s.userHandler.GetUserStatus)
+			protectedUsers.POST("/:id/activate", requirePermission(rbac_model.PermissionUsersUpdate), s.userHandler.ActivateUser)
+			protectedUsers.POST("/:id/suspend", requirePermission(rbac_model.PermissionUsersUpdate), s.userHandler.SuspendUser)
+			protectedUsers.GET("/:id/lockout", requirePermission(rbac_model.PermissionUsersUpdate), s.lockoutHandler.GetUserLockout)
+			protectedUsers.POST("/:id/unlock", requirePermission(rbac_model.PermissionUsersUpdate), s.lockoutHandler.UnlockUser)
+			protectedUsers.GET("/:id/roles", requirePermission(rbac_model.PermissionRolesManage), s.roleHandler.ListUserRoles)
+			protectedUsers.POST("/:id/roles", requirePermission(rbac_model.PermissionRolesManage), s.roleHandler.AssignUserRole)
+			protectedUsers.DELETE("/:id/roles/:role_id", requirePermission(rbac_model.PermissionRolesManage), s.roleHandler.RemoveUserRole)
+		}
+
+		// Service accounts and their credentials are managed by administrators
+		serviceAccounts := v1.Group("/service-accounts", authenticateAPI, apiLimit, requirePermission(rbac_model.PermissionServiceAccountsManage))
+		{
+			serviceAccounts.POST("", s.serviceAccounts.CreateServiceAccount)
+			serviceAccounts.GET("", s.serviceAccounts.ListServiceAccounts)
+
+			serviceAccount := serviceAccounts.Group("/:id", s.serviceAccounts.LoadServiceAccount)
+			serviceAccount.GET("", s.serviceAccounts.GetServiceAccount)
+			serviceAccount.DELETE("", s.serviceAccounts.DeleteServiceAccount)
+			serviceAccount.POST("/api-keys", s.apiKeyHandler.CreateServiceAccountAPIKey)
+			serviceAccount.GET("/api-keys", s.apiKeyHandler.ListServiceAccountAPIKeys)
+			serviceAccount.DELETE("/api-keys/:key_id", s.apiKeyHandler.RevokeServiceAccountAPIKey)
+			serviceAccount.POST("/clients", s.oauthClients.CreateServiceAccountClient)
+			serviceAccount.GET("/clients", s.oauthClients.ListServiceAccountClients)
+			serviceAccount.DELETE("/clients/:client_id", s.oauthClients.DeleteServiceAccountClient)
+		}
+
+		// Blocks of IP addresses that failed to log in too often are lifted by administrators
+		v1.DELETE("/lockouts/ips/:ip", authenticateAPI, apiLimit, requirePermission(rbac_model.PermissionUsersUpdate), s.lockoutHandler.UnlockIP)
+
+		// Role administration
+		roles := v1.Group("/roles", authenticateAPI, apiLimit, requirePermission(rbac_model.PermissionRolesManage))
+		{
+			roles.GET("", s.roleHandler.ListRoles)
+			roles.POST("", s.roleHandler.CreateRole)
+			roles.GET("/:id", s.roleHandler.GetRole)
+			roles.PUT("/:id", s.roleHandler.UpdateRole)
+			roles.DELETE("/:id", s.roleHandler.DeleteRole)
+		}
+		v1.GET("/permissions", authenticateAPI, apiLimit, requirePermission(rbac_model.PermissionRolesManage), s.roleHandler.ListPermissions)
+
+		// The audit log of changes to users and their roles
+		auditLog := v1.Group("/audit", authenticateAPI, apiLimit, requirePermission(rbac_model.PermissionAuditRead))
+		{
+			auditLog.GET("", s.auditHandler.ListEvents)
+			auditLog.GET("/verify", s.auditHandler.VerifyChain)
+			auditLog.GET("/checkpoints", s.auditHandler.ListCheckpoints)
+		}
+
+		// Organizations are visible to their members; member management is checked per organization role
+		organizations := v1.Group("/organizations", authenticateAPI, apiLimit)
+		{
+			readOrganizations := requirePermission(rbac_model.PermissionOrganizationsRead)
+			manageOrganizations := requirePermission(rbac_model.PermissionOrganizationsManage)
+
+			organizations.POST("", manageOrganizations, s.orgHandler.CreateOrganization)
+			organizations.GET("", readOrganizations, s.orgHandler.ListOrganizations)
+			organizations.GET("/:id", readOrganizations, s.orgHandler.GetOrganization)
+			organizations.GET("/:id/members", readOrganizations, s.orgHandler.ListMembers)
+			organizations.PUT("/:id/members/:user_id", manageOrganizations, s.orgHandler.UpdateMemberRole)
+			organizations.DELETE("/:id/members/:user_id", manageOrganizations, s.orgHandler.RemoveMember)
+			organizations.POST("/:id/invitations", manageOrganizations, s.invitations.CreateInvitation)
+			organizations.GET("/:id/invitations", readOrganizations, s.invitations.ListInvitations)
+			organizations.DELETE("/:id/invitations/:invitation_id", manageOrganizations, s.invitations.RevokeInvitation)
+			organizations.POST("/:id/invitations/:invitation_id/resend", manageOrganizations, s.invitations.ResendInvitation)
+		}
+
+		// Invitations are answered with the token from the emailed link, possibly before the invitee has an account
+		invitations := v1.Group("/invitations", authLimit)
+		{
+			invitations.POST("/accept", s.invitations.AcceptInvitation)
+			invitations.POST("/decline", s.invitations.DeclineInvitation)
+		}
+
+		// OAuth clients are managed by the user who registered them
+		oauthClients := v1.Group("/oauth/clients", authenticate, apiLimit)
+		{
+			oauthClients.POST("", s.oauthClients.CreateClient)
+			oauthClients.GET("", s.oauthClients.ListClients)
+			oauthClients.GET("/:client_id", s.oauthClients.GetClient)
+			oauthClients.DELETE("/:client_id", s.oauthClients.DeleteClient)
 		}
 	}
 }
@@ -114,6 +461,9 @@ func (s *Server) Start(addr string) error {
 }
 
 func (s *Server) Close() error {
+	if s.stopBackground != nil {
+		s.stopBackground()
+	}
 	if s.database != nil {
 		return s.database.Close()
 	}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"go-backend-valos-id/core/audit"
+	"go-backend-valos-id/core/user/model"
+	"go-backend-valos-id/core/user/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ServiceAccountKey is the gin.Context key holding the *model.User loaded by LoadServiceAccount
+const ServiceAccountKey = "ServiceAccount"
+
+// ServiceAccountHandler lets administrators manage service accounts. Their credentials,
+// API keys and OAuth clients, are managed by the handlers of those under the same routes.
+type ServiceAccountHandler struct {
+	userRepo *repository.UserRepository
+	sessions SessionInvalidator
+}
+
+func NewServiceAccountHandler(userRepo *repository.UserRepository, sessions SessionInvalidator) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		userRepo: userRepo,
+		sessions: sessions,
+	}
+}
+
+// CreateServiceAccount creates a service account. It has no credentials until an API key or client is created for it.
+func (h *ServiceAccountHandler) CreateServiceAccount(c *gin.Context) {
+	var req model.ServiceAccountCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	account := &model.User{
+		Username: req.Username,
+	}
+	if err := h.userRepo.CreateServiceAccount(account, audit.OriginFromContext(c)); err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "User with this username already exists",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create service account",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Service account created successfully",
+		"data":    toUserResponse(account),
+	})
+}
+
+// ListServiceAccounts lists every service account
+func (h *ServiceAccountHandler) ListServiceAccounts(c *gin.Context) {
+	accounts, err := h.userRepo.ListServiceAccounts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve service accounts",
+		})
+		return
+	}
+
+	responses := make([]model.UserResponse, len(accounts))
+	for i := range accounts {
+		responses[i] = toUserResponse(&accounts[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  responses,
+		"count": len(responses),
+	})
+}
+
+// GetServiceAccount returns the service account loaded by LoadServiceAccount
+func (h *ServiceAccountHandler) GetServiceAccount(c *gin.Context) {
+	account, _ := ServiceAccountFromContext(c)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": toUserResponse(account),
+	})
+}
+
+// DeleteServiceAccount deletes the service account loaded by LoadServiceAccount. Its API keys and clients
+// stop working at once and are removed when the account is purged.
+func (h *ServiceAccountHandler) DeleteServiceAccount(c *gin.Context) {
+	account, _ := ServiceAccountFromContext(c)
+
+	if err := h.userRepo.DeleteUser(account.ID, audit.OriginFromContext(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete service account",
+		})
+		return
+	}
+	h.sessions.UserDeleted(account.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Service account deleted successfully",
+	})
+}
+
+// LoadServiceAccount middleware loads the service account named by the :id parameter into the context,
+// answering 404 for unknown IDs and for users that are not service accounts
+func (h *ServiceAccountHandler) LoadServiceAccount(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid service account ID",
+		})
+		return
+	}
+
+	account, err := h.userRepo.GetServiceAccount(int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"error": "Service account not found",
+			})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve service account",
+		})
+		return
+	}
+
+	c.Set(ServiceAccountKey, account)
+	c.Next()
+}
+
+// ServiceAccountFromContext returns the service account set by LoadServiceAccount
+func ServiceAccountFromContext(c *gin.Context) (*model.User, bool) {
+	value, exists := c.Get(ServiceAccountKey)
+	if !exists {
+		return nil, false
+	}
+	account, ok := value.(*model.User)
+	return account, ok
+}
@@ -2,10 +2,16 @@ package handler
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 
+	"go-backend-valos-id/core/audit"
+	"go-backend-valos-id/core/middleware"
+	"go-backend-valos-id/core/pagination"
+	rbac_model "go-backend-valos-id/core/rbac/model"
 	"go-backend-valos-id/core/user/model"
 	"go-backend-valos-id/core/user/repository"
 	"go-backend-valos-id/core/utils"
@@ -14,17 +20,47 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// EmailVerificationSender sends a verification link for a user's current email address
+type EmailVerificationSender interface {
+	SendVerification(user *model.User) error
+}
+
+// SessionInvalidator is told about deleted, restored and suspended users so that their tokens stop or resume working at once
+type SessionInvalidator interface {
+	UserDeleted(userID int32)
+	CredentialsChanged(userID, credentialVersion int32)
+}
+
 type UserHandler struct {
-	userRepo *repository.UserRepository
+	userRepo    *repository.UserRepository
+	verifier    EmailVerificationSender
+	sessions    SessionInvalidator
+	permissions middleware.PermissionChecker
+	cursors     *pagination.Signer
+	// requireVerifiedEmail makes registered users pending until they verify their email address
+	requireVerifiedEmail bool
 }
 
-func NewUserHandler(userRepo *repository.UserRepository) *UserHandler {
+func NewUserHandler(
+	userRepo *repository.UserRepository,
+	verifier EmailVerificationSender,
+	sessions SessionInvalidator,
+	permissions middleware.PermissionChecker,
+	cursors *pagination.Signer,
+	requireVerifiedEmail bool,
+) *UserHandler {
 	return &UserHandler{
-		userRepo: userRepo,
+		userRepo:             userRepo,
+		verifier:             verifier,
+		sessions:             sessions,
+		permissions:          permissions,
+		cursors:              cursors,
+		requireVerifiedEmail: requireVerifiedEmail,
 	}
 }
 
-// CreateUser handles the creation of a new user
+// CreateUser handles the creation of a new user. The user is pending until they verify their email
+// address if verification is required, and active otherwise.
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req model.UserCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -64,9 +100,13 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		Username: req.Username,
 		Email:    req.Email,
 		Password: hashedPassword,
+		Status:   model.StatusActive,
+	}
+	if h.requireVerifiedEmail {
+		user.Status = model.StatusPending
 	}
 
-	if err := h.userRepo.CreateUser(user); err != nil {
+	if err := h.userRepo.CreateUser(user, audit.OriginFromContext(c)); err != nil {
 		// Check for unique constraint violation
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
 			if pgErr.ConstraintName == "users_email_unique" || pgErr.ConstraintName == "users_email_key" {
@@ -88,15 +128,62 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	go h.sendVerification(*user)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "User created successfully",
-		"user":    h.toUserResponse(user),
+		"user":    toUserResponse(user),
 	})
 }
 
-// GetAllUsers retrieves all users
-func (h *UserHandler) GetAllUsers(c *gin.Context) {
-	users, err := h.userRepo.GetAllUsers()
+// ListUsers lists users a page at a time, newest first, or only the members of the organization the caller acts within.
+// Service accounts and deleted users are left out unless include_service_accounts=true or include_deleted=true;
+// status=<status> lists only the users with that status. A page holds up to limit users and carries next_cursor
+// and prev_cursor to pass as cursor for the following and preceding pages; include_total=true adds the number of
+// matching users.
+func (h *UserHandler) ListUsers(c *gin.Context) {
+	organizationID, ok := h.listScope(c)
+	if !ok {
+		return
+	}
+	filter, ok := userFilter(c)
+	if !ok {
+		return
+	}
+
+	limit, err := h.parseIntQuery(c.Query("limit"), 50, 1, 100)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid limit parameter",
+		})
+		return
+	}
+
+	// Cursors only continue the listing they were issued for
+	listFilter := pagination.FilterDigest(
+		strconv.FormatInt(int64(organizationID), 10),
+		strconv.FormatBool(filter.IncludeServiceAccounts),
+		strconv.FormatBool(filter.IncludeDeleted),
+		filter.Status,
+	)
+	var cursor *pagination.Cursor
+	if value := c.Query("cursor"); value != "" {
+		cursor, err = h.cursors.Decode(value, listFilter)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid cursor parameter",
+			})
+			return
+		}
+	}
+
+	var users []model.User
+	var more bool
+	if organizationID != 0 {
+		users, more, err = h.userRepo.ListOrganizationUsers(organizationID, filter.Status, cursor, int32(limit))
+	} else {
+		users, more, err = h.userRepo.ListUsers(filter, cursor, int32(limit))
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve users",
@@ -104,18 +191,43 @@ func (h *UserHandler) GetAllUsers(c *gin.Context) {
 		return
 	}
 
+	nextCursor, prevCursor := h.pageCursors(users, cursor, more, listFilter)
+	page := gin.H{
+		"limit":       limit,
+		"next_cursor": nextCursor,
+		"prev_cursor": prevCursor,
+	}
+
+	// Counting scans every matching user, so it is only done on request
+	if includeTotal, _ := strconv.ParseBool(c.Query("include_total")); includeTotal {
+		var total int
+		if organizationID != 0 {
+			total, err = h.userRepo.CountOrganizationUsers(organizationID, filter.Status)
+		} else {
+			total, err = h.userRepo.CountUsers(filter)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to count users",
+			})
+			return
+		}
+		page["total"] = total
+	}
+
 	userResponses := make([]model.UserResponse, len(users))
 	for i, user := range users {
-		userResponses[i] = h.toUserResponse(&user)
+		userResponses[i] = toUserResponse(&user)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"users": userResponses,
-		"count": len(userResponses),
+		"users":      userResponses,
+		"count":      len(userResponses),
+		"pagination": page,
 	})
 }
 
-// GetUserByID retrieves a user by ID
+// GetUserByID retrieves a user by ID. Users without users:read may only retrieve themselves.
 func (h *UserHandler) GetUserByID(c *gin.Context) {
 	userID, err := h.parseUserID(c.Param("id"))
 	if err != nil {
@@ -125,6 +237,10 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 		return
 	}
 
+	if !h.authorizeAccess(c, userID, rbac_model.PermissionUsersRead) {
+		return
+	}
+
 	user, err := h.userRepo.GetUserByID(userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -140,11 +256,11 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"user": h.toUserResponse(user),
+		"user": toUserResponse(user),
 	})
 }
 
-// UpdateUser handles updating an existing user
+// UpdateUser handles updating an existing user. Users without users:update may only update themselves.
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	userID, err := h.parseUserID(c.Param("id"))
 	if err != nil {
@@ -154,8 +270,12 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	if !h.authorizeAccess(c, userID, rbac_model.PermissionUsersUpdate) {
+		return
+	}
+
 	// Check if user exists first
-	_, err = h.userRepo.GetUserByID(userID)
+	existing, err := h.userRepo.GetUserByID(userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -168,6 +288,12 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		})
 		return
 	}
+	if existing.IsServiceAccount() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Service accounts are managed under /api/v1/service-accounts",
+		})
+		return
+	}
 
 	var req struct {
 		Username string `json:"username" binding:"required,min=3,max=50"`
@@ -188,7 +314,16 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		Email:    req.Email,
 	}
 
-	if err := h.userRepo.UpdateUser(user); err != nil {
+	// Changing the email resets its verification
+	emailChanged := existing.Email != req.Email
+
+	if err := h.userRepo.UpdateUser(user, audit.OriginFromContext(c)); err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
 		// Check for unique constraint violation
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
 			if pgErr.ConstraintName == "users_email_unique" || pgErr.ConstraintName == "users_email_key" {
@@ -210,9 +345,13 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	if emailChanged {
+		go h.sendVerification(*user)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User updated successfully",
-		"user":    h.toUserResponse(user),
+		"user":    toUserResponse(user),
 	})
 }
 
@@ -226,10 +365,8 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userRepo.DeleteUser(userID); err != nil {
-		// sqlc DeleteUser doesn't return an error for no rows affected
-		// We need to check if the user exists first
-		if _, checkErr := h.userRepo.GetUserByID(userID); checkErr == sql.ErrNoRows {
+	if err := h.userRepo.DeleteUser(userID, audit.OriginFromContext(c)); err != nil {
+		if err == sql.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "User not found",
 			})
@@ -240,14 +377,101 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		})
 		return
 	}
+	h.sessions.UserDeleted(userID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User deleted successfully",
 	})
 }
 
-// GetUsersWithPagination retrieves users with pagination
+// RestoreUser undoes the deletion of a user that has not been purged yet
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	userID, err := h.parseUserID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userRepo.RestoreUser(userID, audit.OriginFromContext(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Deleted user not found",
+			})
+			return
+		}
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Another user has taken this user's email or username",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to restore user",
+		})
+		return
+	}
+	h.sessions.CredentialsChanged(user.ID, user.CredentialVersion)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User restored successfully",
+		"user":    toUserResponse(user),
+	})
+}
+
+// GetUserStatus returns a user's status with the reason and actor of its last change
+func (h *UserHandler) GetUserStatus(c *gin.Context) {
+	userID, err := h.parseUserID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": toUserStatusResponse(user),
+	})
+}
+
+// ActivateUser activates a pending user, or reactivates a suspended or locked one
+func (h *UserHandler) ActivateUser(c *gin.Context) {
+	h.changeStatus(c, model.StatusActive)
+}
+
+// SuspendUser suspends an active user, ending their sessions until they are reactivated
+func (h *UserHandler) SuspendUser(c *gin.Context) {
+	h.changeStatus(c, model.StatusSuspended)
+}
+
+// GetUsersWithPagination retrieves users by limit and offset, scoped like ListUsers.
+// ListUsers pages through large listings faster and stays stable while users are added.
 func (h *UserHandler) GetUsersWithPagination(c *gin.Context) {
+	organizationID, ok := h.listScope(c)
+	if !ok {
+		return
+	}
+	filter, ok := userFilter(c)
+	if !ok {
+		return
+	}
+
 	limit, err := h.parseIntQuery(c.Query("limit"), 10, 1, 100)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -264,7 +488,12 @@ func (h *UserHandler) GetUsersWithPagination(c *gin.Context) {
 		return
 	}
 
-	users, err := h.userRepo.GetUsersWithPagination(int32(limit), int32(offset))
+	var users []model.User
+	if organizationID != 0 {
+		users, err = h.userRepo.GetOrganizationUsersWithPagination(organizationID, int32(limit), int32(offset), filter.Status)
+	} else {
+		users, err = h.userRepo.GetUsersWithPagination(int32(limit), int32(offset), filter)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve users",
@@ -272,7 +501,12 @@ func (h *UserHandler) GetUsersWithPagination(c *gin.Context) {
 		return
 	}
 
-	total, err := h.userRepo.CountUsers()
+	var total int
+	if organizationID != 0 {
+		total, err = h.userRepo.CountOrganizationUsers(organizationID, filter.Status)
+	} else {
+		total, err = h.userRepo.CountUsers(filter)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to count users",
@@ -282,7 +516,7 @@ func (h *UserHandler) GetUsersWithPagination(c *gin.Context) {
 
 	userResponses := make([]model.UserResponse, len(users))
 	for i, user := range users {
-		userResponses[i] = h.toUserResponse(&user)
+		userResponses[i] = toUserResponse(&user)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -297,6 +531,153 @@ func (h *UserHandler) GetUsersWithPagination(c *gin.Context) {
 
 // Helper methods
 
+// authorizeAccess lets callers act on their own account, and on other accounts only with permission.
+// API keys need the permission in their scopes either way. It writes the error response when access is denied.
+func (h *UserHandler) authorizeAccess(c *gin.Context, userID int32, permission string) bool {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		middleware.AbortUnauthorized(c, "Authentication required")
+		return false
+	}
+	if !principal.Allows(permission) {
+		middleware.AbortForbidden(c, "API key lacks the required scope")
+		return false
+	}
+	if principal.UserID == userID {
+		return true
+	}
+
+	allowed, err := h.permissions.HasPermission(principal.UserID, permission)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check permissions",
+		})
+		return false
+	}
+	if !allowed {
+		middleware.AbortForbidden(c, "You can only access your own account")
+		return false
+	}
+	return true
+}
+
+// listScope decides which users the caller may list. Callers acting within an organization list its
+// members, whose membership the session guard has already checked; listing every user needs users:read.
+// It returns the organization ID, or 0 for all users, and writes the error response when access is denied.
+func (h *UserHandler) listScope(c *gin.Context) (int32, bool) {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		middleware.AbortUnauthorized(c, "Authentication required")
+		return 0, false
+	}
+	if principal.OrganizationID != 0 {
+		return principal.OrganizationID, true
+	}
+	if !principal.Allows(rbac_model.PermissionUsersRead) {
+		middleware.AbortForbidden(c, "API key lacks the required scope")
+		return 0, false
+	}
+
+	allowed, err := h.permissions.HasPermission(principal.UserID, rbac_model.PermissionUsersRead)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check permissions",
+		})
+		return 0, false
+	}
+	if !allowed {
+		middleware.AbortForbidden(c, "Insufficient permissions")
+		return 0, false
+	}
+	return 0, true
+}
+
+// pageCursors returns the cursors of the pages after and before a page of users, nil where there is none.
+// more tells whether users lie beyond the page in the direction it was requested in; filter is the
+// FilterDigest of the listing.
+func (h *UserHandler) pageCursors(users []model.User, cursor *pagination.Cursor, more bool, filter string) (*string, *string) {
+	if len(users) == 0 {
+		return nil, nil
+	}
+	backward := cursor != nil && cursor.Backward
+
+	var next, prev *string
+	// A page before a cursor is followed by the user the cursor points at
+	if more || backward {
+		next = h.cursorAt(&users[len(users)-1], false, filter)
+	}
+	// A page after a cursor is preceded by the user the cursor points at
+	if (more && backward) || (cursor != nil && !backward) {
+		prev = h.cursorAt(&users[0], true, filter)
+	}
+	return next, prev
+}
+
+// cursorAt returns the opaque cursor of the page after a user, or before it if backward, in the listing filter
+func (h *UserHandler) cursorAt(user *model.User, backward bool, filter string) *string {
+	cursor := h.cursors.Encode(pagination.Cursor{
+		CreatedAt: user.CreatedAt.UnixMilli(),
+		ID:        user.ID,
+		Backward:  backward,
+		Filter:    filter,
+	})
+	return &cursor
+}
+
+// changeStatus moves the user named by the :id parameter to status for the reason given in the request.
+// Administrators cannot change their own status, so that they cannot suspend themselves by mistake.
+func (h *UserHandler) changeStatus(c *gin.Context, status string) {
+	userID, err := h.parseUserID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var req model.UserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if principal, ok := middleware.GetPrincipal(c); ok && principal.UserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "You cannot change the status of your own account",
+		})
+		return
+	}
+
+	user, err := h.userRepo.ChangeStatus(userID, status, req.Reason, audit.OriginFromContext(c))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "User not found",
+			})
+			return
+		}
+		if errors.Is(err, repository.ErrInvalidStatusTransition) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": fmt.Sprintf("User status cannot change to %s from its current status", status),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to change user status",
+		})
+		return
+	}
+	h.sessions.CredentialsChanged(user.ID, user.CredentialVersion)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User status changed successfully",
+		"data":    toUserStatusResponse(user),
+	})
+}
+
 func (h *UserHandler) parseUserID(idStr string) (int32, error) {
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
@@ -328,12 +709,52 @@ func (h *UserHandler) parseIntQuery(value string, defaultValue, min, max int) (i
 	return result, nil
 }
 
-func (h *UserHandler) toUserResponse(user *model.User) model.UserResponse {
+// userFilter reads which users a list asked for. It writes the error response for an unknown status.
+func userFilter(c *gin.Context) (*model.UserFilter, bool) {
+	includeServiceAccounts, _ := strconv.ParseBool(c.Query("include_service_accounts"))
+	includeDeleted, _ := strconv.ParseBool(c.Query("include_deleted"))
+
+	status := c.Query("status")
+	if status != "" && !model.IsValidStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid status parameter",
+		})
+		return nil, false
+	}
+
+	return &model.UserFilter{
+		IncludeServiceAccounts: includeServiceAccounts,
+		IncludeDeleted:         includeDeleted,
+		Status:                 status,
+	}, true
+}
+
+func (h *UserHandler) sendVerification(user model.User) {
+	if err := h.verifier.SendVerification(&user); err != nil {
+		log.Printf("Failed to send verification email: %v", err)
+	}
+}
+
+func toUserStatusResponse(user *model.User) model.UserStatusResponse {
+	return model.UserStatusResponse{
+		Status:    user.Status,
+		Reason:    user.StatusReason,
+		ChangedAt: user.StatusChangedAt,
+		ChangedBy: user.StatusChangedBy,
+	}
+}
+
+func toUserResponse(user *model.User) model.UserResponse {
 	return model.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:              user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		EmailVerified:   user.EmailVerified(),
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		AccountType:     user.AccountType,
+		Status:          user.Status,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+		DeletedAt:       user.DeletedAt,
 	}
 }
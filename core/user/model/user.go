@@ -1,9 +1,41 @@
 package model
 
 import (
+	"slices"
 	"time"
 )
 
+// Account types. Service accounts are non-human users for automation; they have no password
+// or email address and authenticate only with API keys and the secrets of OAuth clients they own.
+const (
+	AccountTypeUser    = "user"
+	AccountTypeService = "service"
+)
+
+// Account statuses. Only active users can authenticate. Users are pending until they verify their
+// email address when verification is required, administrators suspend and reactivate users, and
+// security systems lock users that keep failing to log in.
+const (
+	StatusPending   = "pending"
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusLocked    = "locked"
+)
+
+// statusTransitions lists the statuses each status may change to
+var statusTransitions = map[string][]string{
+	StatusPending:   {StatusActive, StatusLocked},
+	StatusActive:    {StatusSuspended, StatusLocked},
+	StatusSuspended: {StatusActive, StatusLocked},
+	StatusLocked:    {StatusActive},
+}
+
+// IsValidStatus reports whether status is a known account status
+func IsValidStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
 type User struct {
 	ID        int32     `json:"id" db:"id"`
 	Username  string    `json:"username" db:"username"`
@@ -11,6 +43,47 @@ type User struct {
 	Password  string    `json:"-" db:"password"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	// CredentialVersion is bumped on every password change
+	CredentialVersion int32 `json:"-" db:"credential_version"`
+	// EmailVerifiedAt is nil until the current email address has been verified
+	EmailVerifiedAt *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	AccountType     string     `json:"account_type" db:"account_type"`
+	// DeletedAt is set once the user is deleted; the row is purged after the retention period
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	Status    string     `json:"status" db:"status"`
+	// StatusReason, StatusChangedAt and StatusChangedBy describe the last status change;
+	// StatusChangedBy is nil for changes made by the system
+	StatusReason    string     `json:"status_reason,omitempty" db:"status_reason"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" db:"status_changed_at"`
+	StatusChangedBy *int32     `json:"status_changed_by,omitempty" db:"status_changed_by"`
+}
+
+// IsServiceAccount reports whether the user is a service account
+func (u *User) IsServiceAccount() bool {
+	return u.AccountType == AccountTypeService
+}
+
+// EmailVerified reports whether the current email address has been verified
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsActive reports whether the user's status allows them to authenticate
+func (u *User) IsActive() bool {
+	return u.Status == StatusActive
+}
+
+// CanChangeStatusTo reports whether the user's status may change to status
+func (u *User) CanChangeStatusTo(status string) bool {
+	return slices.Contains(statusTransitions[u.Status], status)
+}
+
+// UserFilter selects the users of a listing
+type UserFilter struct {
+	IncludeServiceAccounts bool
+	IncludeDeleted         bool
+	// Status restricts the listing to users with this status; empty lists every status
+	Status string
 }
 
 type UserCreateRequest struct {
@@ -20,9 +93,36 @@ type UserCreateRequest struct {
 }
 
 type UserResponse struct {
-	ID        int32     `json:"id" db:"id"`
-	Username  string    `json:"username" db:"username"`
-	Email     string    `json:"email" db:"email"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID              int32      `json:"id" db:"id"`
+	Username        string     `json:"username" db:"username"`
+	Email           string     `json:"email" db:"email"`
+	EmailVerified   bool       `json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	AccountType     string     `json:"account_type" db:"account_type"`
+	Status          string     `json:"status" db:"status"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// UserStatusRequest carries the reason for an administrator's status change
+type UserStatusRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// UserStatusResponse describes a user's status and its last change
+type UserStatusResponse struct {
+	Status    string     `json:"status"`
+	Reason    string     `json:"reason,omitempty"`
+	ChangedAt *time.Time `json:"changed_at,omitempty"`
+	ChangedBy *int32     `json:"changed_by,omitempty"`
+}
+
+type ServiceAccountCreateRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+}
+
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
 }
@@ -0,0 +1,58 @@
+// Package purge permanently removes deleted users once they can no longer be restored
+package purge
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go-backend-valos-id/core/config"
+	"go-backend-valos-id/core/user/repository"
+)
+
+// Purger hard deletes users that have been soft deleted for longer than the retention period
+type Purger struct {
+	userRepo  *repository.UserRepository
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPurger(userRepo *repository.UserRepository, retention, interval time.Duration) *Purger {
+	return &Purger{
+		userRepo:  userRepo,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+func NewPurgerFromConfig(cfg *config.UserConfig, userRepo *repository.UserRepository) *Purger {
+	return NewPurger(userRepo, cfg.DeletedRetention, cfg.PurgeInterval)
+}
+
+// Purge hard deletes the users deleted more than the retention period ago and returns how many there were
+func (p *Purger) Purge() (int, error) {
+	return p.userRepo.PurgeDeletedUsers(time.Now().Add(-p.retention))
+}
+
+// Run purges every interval until ctx is cancelled
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		purged, err := p.Purge()
+		if err != nil {
+			log.Printf("Failed to purge deleted users: %v", err)
+			continue
+		}
+		if purged > 0 {
+			log.Printf("Purged %d deleted users", purged)
+		}
+	}
+}
@@ -3,16 +3,28 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
 	"time"
 
+	audit_model "go-backend-valos-id/core/audit/model"
+	audit_repository "go-backend-valos-id/core/audit/repository"
 	"go-backend-valos-id/core/internal/repository"
+	"go-backend-valos-id/core/pagination"
+	rbac_model "go-backend-valos-id/core/rbac/model"
 	"go-backend-valos-id/core/user/model"
+	"go-backend-valos-id/core/utils"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrInvalidStatusTransition is returned when a user's status may not change to the requested status
+var ErrInvalidStatusTransition = errors.New("invalid status transition")
+
 type UserRepository struct {
 	pool    *pgxpool.Pool
 	queries *repository.Queries
@@ -25,26 +37,37 @@ func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
 	}
 }
 
-// CreateUser creates a new user in the database
-func (r *UserRepository) CreateUser(user *model.User) error {
+// CreateUser creates a new user in the database with the default role, recording it in the audit log.
+// The user starts with user.Status, or active if it is empty.
+func (r *UserRepository) CreateUser(user *model.User, origin *audit_model.Origin) error {
 	ctx := context.Background()
 	now := time.Now()
 
-	// Convert time.Time to pgtype.Timestamptz for pgx
-	timestamptz := pgtype.Timestamptz{
-		Time:  now,
-		Valid: true,
+	status := user.Status
+	if status == "" {
+		status = model.StatusActive
 	}
 
+	// Timestamps are stored as epoch milliseconds
+	timestamp := utils.ToEpochMillis(now)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
 	params := repository.CreateUserParams{
 		Username:  user.Username,
-		Email:     user.Email,
-		Password:  user.Password,
-		CreatedAt: timestamptz,
-		UpdatedAt: timestamptz,
+		Email:     pgtype.Text{String: user.Email, Valid: true},
+		Password:  pgtype.Text{String: user.Password, Valid: true},
+		Status:    status,
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
 	}
 
-	result, err := r.queries.CreateUser(ctx, params)
+	result, err := qtx.CreateUser(ctx, params)
 	if err != nil {
 		// Check for unique constraint violation
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
@@ -64,10 +87,70 @@ func (r *UserRepository) CreateUser(user *model.User) error {
 		return err
 	}
 
-	user.ID = result.ID
-	user.CreatedAt = now
-	user.UpdatedAt = now
+	err = qtx.AssignUserRoleByName(ctx, repository.AssignUserRoleByNameParams{
+		UserID:    result.ID,
+		CreatedAt: timestamp.Int64,
+		Name:      rbac_model.RoleUser,
+	})
+	if err != nil {
+		return err
+	}
+
+	created := r.sqlcUserToModelUser(&result)
+	if err := r.recordChange(ctx, qtx, origin, audit_model.ActionUserCreated, created.ID, nil, created); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	*user = *created
+	return nil
+}
+
+// CreateServiceAccount creates a service account. Service accounts get no role by default;
+// they only hold the roles an administrator assigns them.
+func (r *UserRepository) CreateServiceAccount(user *model.User, origin *audit_model.Origin) error {
+	ctx := context.Background()
+	now := time.Now()
+
+	// Timestamps are stored as epoch milliseconds
+	timestamp := utils.ToEpochMillis(now)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	result, err := qtx.CreateServiceAccount(ctx, repository.CreateServiceAccountParams{
+		Username:  user.Username,
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+	})
+	if err != nil {
+		// Check for unique constraint violation
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			if pgErr.ConstraintName == "users_username_unique" || pgErr.ConstraintName == "users_username_key" {
+				return &pgconn.PgError{
+					Code:    "23505",
+					Message: "user with this username already exists",
+				}
+			}
+		}
+		return err
+	}
+
+	created := r.sqlcUserToModelUser(&result)
+	if err := r.recordChange(ctx, qtx, origin, audit_model.ActionUserCreated, created.ID, nil, created); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
 
+	*user = *created
 	return nil
 }
 
@@ -77,7 +160,7 @@ func (r *UserRepository) GetUserByID(id int32) (*model.User, error) {
 
 	result, err := r.queries.GetUserByID(ctx, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, sql.ErrNoRows
 		}
 		return nil, err
@@ -90,9 +173,9 @@ func (r *UserRepository) GetUserByID(id int32) (*model.User, error) {
 func (r *UserRepository) GetUserByEmail(email string) (*model.User, error) {
 	ctx := context.Background()
 
-	result, err := r.queries.GetUserByEmail(ctx, email)
+	result, err := r.queries.GetUserByEmail(ctx, pgtype.Text{String: email, Valid: true})
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, sql.ErrNoRows
 		}
 		return nil, err
@@ -101,58 +184,157 @@ func (r *UserRepository) GetUserByEmail(email string) (*model.User, error) {
 	return r.sqlcUserToModelUser(&result), nil
 }
 
-// GetAllUsers retrieves all users from the database
-func (r *UserRepository) GetAllUsers() ([]model.User, error) {
+// GetUserByUsername retrieves a user by their username
+func (r *UserRepository) GetUserByUsername(username string) (*model.User, error) {
 	ctx := context.Background()
 
-	results, err := r.queries.GetAllUsers(ctx)
+	result, err := r.queries.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+
+	return r.sqlcUserToModelUser(&result), nil
+}
+
+// GetUserByIdentifier looks a user up by email if the identifier contains "@", otherwise by username
+func (r *UserRepository) GetUserByIdentifier(identifier string) (*model.User, error) {
+	identifier = strings.TrimSpace(identifier)
+	if strings.Contains(identifier, "@") {
+		return r.GetUserByEmail(identifier)
+	}
+	return r.GetUserByUsername(identifier)
+}
+
+// GetServiceAccount retrieves a service account by its ID. Users that are not service accounts are not found.
+func (r *UserRepository) GetServiceAccount(id int32) (*model.User, error) {
+	user, err := r.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsServiceAccount() {
+		return nil, sql.ErrNoRows
+	}
+
+	return user, nil
+}
+
+// ListServiceAccounts retrieves all service accounts
+func (r *UserRepository) ListServiceAccounts() ([]model.User, error) {
+	ctx := context.Background()
+
+	results, err := r.queries.ListServiceAccounts(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	users := make([]model.User, len(results))
-	for i, result := range results {
-		createdAt := time.Time{}
-		if result.CreatedAt.Valid {
-			createdAt = result.CreatedAt.Time
-		}
+	for i := range results {
+		users[i] = *r.sqlcUserToModelUser(&results[i])
+	}
+
+	return users, nil
+}
 
-		updatedAt := time.Time{}
-		if result.UpdatedAt.Valid {
-			updatedAt = result.UpdatedAt.Time
+// ListUsers returns a page of up to limit users matching the filter, newest first. The page starts after the
+// cursor, or ends before it if the cursor is backward; a nil cursor starts with the newest user.
+// more reports whether further users lie beyond the page in the direction of paging.
+func (r *UserRepository) ListUsers(filter *model.UserFilter, cursor *pagination.Cursor, limit int32) ([]model.User, bool, error) {
+	ctx := context.Background()
+
+	// One user more than the page holds tells whether more follow
+	var results []repository.ListUsersRow
+	if cursor != nil && cursor.Backward {
+		before, err := r.queries.ListUsersBefore(ctx, repository.ListUsersBeforeParams{
+			IncludeServiceAccounts: filter.IncludeServiceAccounts,
+			IncludeDeleted:         filter.IncludeDeleted,
+			Status:                 nullableStatus(filter.Status),
+			BeforeCreatedAt:        cursor.CreatedAt,
+			BeforeID:               cursor.ID,
+			Limit:                  limit + 1,
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		// Users before the cursor come oldest first
+		results = make([]repository.ListUsersRow, len(before))
+		for i, result := range before {
+			results[len(before)-1-i] = repository.ListUsersRow(result)
+		}
+	} else {
+		params := repository.ListUsersParams{
+			IncludeServiceAccounts: filter.IncludeServiceAccounts,
+			IncludeDeleted:         filter.IncludeDeleted,
+			Status:                 nullableStatus(filter.Status),
+			Limit:                  limit + 1,
 		}
+		if cursor != nil {
+			params.AfterCreatedAt = pgtype.Int8{Int64: cursor.CreatedAt, Valid: true}
+			params.AfterID = pgtype.Int4{Int32: cursor.ID, Valid: true}
+		}
+		var err error
+		results, err = r.queries.ListUsers(ctx, params)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	users := make([]model.User, len(results))
+	for i, result := range results {
+		createdAt := utils.FromEpochMillis(result.CreatedAt)
+		updatedAt := utils.FromEpochMillis(result.UpdatedAt)
 
 		users[i] = model.User{
-			ID:        result.ID,
-			Username:  result.Username,
-			Email:     result.Email,
-			CreatedAt: createdAt,
-			UpdatedAt: updatedAt,
+			ID:              result.ID,
+			Username:        result.Username,
+			Email:           result.Email.String,
+			CreatedAt:       createdAt,
+			UpdatedAt:       updatedAt,
+			EmailVerifiedAt: utils.NullableFromEpochMillis(result.EmailVerifiedAt),
+			AccountType:     result.AccountType,
+			DeletedAt:       utils.NullableFromEpochMillis(result.DeletedAt),
+			Status:          result.Status,
 		}
 	}
 
-	return users, nil
+	users, more := trimPage(users, limit, cursor)
+	return users, more, nil
 }
 
-// UpdateUser updates an existing user
-func (r *UserRepository) UpdateUser(user *model.User) error {
+// UpdateUser updates the username and email of an existing user, recording the change in the audit log.
+// On success user holds the updated row.
+func (r *UserRepository) UpdateUser(user *model.User, origin *audit_model.Origin) error {
 	ctx := context.Background()
 	now := time.Now()
 
-	// Convert time.Time to pgtype.Timestamptz for pgx
-	timestamptz := pgtype.Timestamptz{
-		Time:  now,
-		Valid: true,
+	// Timestamps are stored as epoch milliseconds
+	timestamp := utils.ToEpochMillis(now)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	before, err := qtx.GetUserByIDForUpdate(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sql.ErrNoRows
+		}
+		return err
 	}
 
 	params := repository.UpdateUserParams{
 		ID:        user.ID,
 		Username:  user.Username,
-		Email:     user.Email,
-		UpdatedAt: timestamptz,
+		Email:     pgtype.Text{String: user.Email, Valid: true},
+		UpdatedAt: timestamp,
 	}
 
-	err := r.queries.UpdateUser(ctx, params)
+	result, err := qtx.UpdateUser(ctx, params)
 	if err != nil {
 		// Check for unique constraint violation
 		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
@@ -172,52 +354,281 @@ func (r *UserRepository) UpdateUser(user *model.User) error {
 		return err
 	}
 
-	user.UpdatedAt = now
+	updated := r.sqlcUserToModelUser(&result)
+	if err := r.recordChange(ctx, qtx, origin, audit_model.ActionUserUpdated, user.ID, r.sqlcUserToModelUser(&before), updated); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	*user = *updated
 	return nil
 }
 
-// UpdatePassword updates a user's password
-func (r *UserRepository) UpdatePassword(userID int32, hashedPassword string) error {
+// UpdatePassword updates a user's password, recording it in the audit log, and returns the user's new credential version
+func (r *UserRepository) UpdatePassword(userID int32, hashedPassword string, origin *audit_model.Origin) (int32, error) {
 	ctx := context.Background()
 	now := time.Now()
 
-	// Convert time.Time to pgtype.Timestamptz for pgx
-	timestamptz := pgtype.Timestamptz{
-		Time:  now,
-		Valid: true,
+	// Timestamps are stored as epoch milliseconds
+	timestamp := utils.ToEpochMillis(now)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
 	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
 
 	params := repository.UpdatePasswordParams{
 		ID:        userID,
-		Password:  hashedPassword,
-		UpdatedAt: timestamptz,
+		Password:  pgtype.Text{String: hashedPassword, Valid: true},
+		UpdatedAt: timestamp,
 	}
 
-	err := r.queries.UpdatePassword(ctx, params)
+	credentialVersion, err := qtx.UpdatePassword(ctx, params)
 	if err != nil {
-		return err
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, sql.ErrNoRows
+		}
+		return 0, err
 	}
 
-	return nil
+	// The password itself is never recorded, only the credential version it moved the user to
+	before := map[string]any{"credential_version": credentialVersion - 1}
+	after := map[string]any{"credential_version": credentialVersion}
+	err = audit_repository.Record(ctx, qtx, origin, audit_model.ActionUserPasswordChanged, audit_model.TargetUser, strconv.FormatInt(int64(userID), 10), before, after)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return credentialVersion, nil
+}
+
+// GetCredentialVersion returns the current credential version of a user
+func (r *UserRepository) GetCredentialVersion(userID int32) (int32, error) {
+	ctx := context.Background()
+
+	credentialVersion, err := r.queries.GetUserCredentialVersion(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, sql.ErrNoRows
+		}
+		return 0, err
+	}
+
+	return credentialVersion, nil
 }
 
-// DeleteUser deletes a user by their ID
-func (r *UserRepository) DeleteUser(id int32) error {
+// DeleteUser soft deletes a user by their ID, recording it in the audit log. The user disappears from
+// every query until restored or purged; their credential version is bumped and their sessions revoked,
+// so tokens issued before the deletion stay invalid after a restore.
+func (r *UserRepository) DeleteUser(id int32, origin *audit_model.Origin) error {
 	ctx := context.Background()
+	now := utils.ToEpochMillis(time.Now())
 
-	err := r.queries.DeleteUser(ctx, id)
+	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
 
-	return nil
+	before, err := qtx.GetUserByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return sql.ErrNoRows
+		}
+		return err
+	}
+
+	result, err := qtx.SoftDeleteUser(ctx, repository.SoftDeleteUserParams{
+		ID:        id,
+		DeletedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = qtx.RevokeUserSessions(ctx, repository.RevokeUserSessionsParams{
+		UserID:    id,
+		RevokedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	err = qtx.RevokeUserRefreshTokens(ctx, repository.RevokeUserRefreshTokensParams{
+		UserID:    id,
+		RevokedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := r.recordChange(ctx, qtx, origin, audit_model.ActionUserDeleted, id, r.sqlcUserToModelUser(&before), r.sqlcUserToModelUser(&result)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// RestoreUser undoes the deletion of a user that has not been purged yet, recording it in the audit log.
+// It returns sql.ErrNoRows if there is no such deleted user, and a unique violation if the email or
+// username has been taken since.
+func (r *UserRepository) RestoreUser(id int32, origin *audit_model.Origin) (*model.User, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	before, err := qtx.GetDeletedUserByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+
+	result, err := qtx.RestoreUser(ctx, repository.RestoreUserParams{
+		ID:        id,
+		UpdatedAt: utils.ToEpochMillis(time.Now()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	restored := r.sqlcUserToModelUser(&result)
+	if err := r.recordChange(ctx, qtx, origin, audit_model.ActionUserRestored, id, r.sqlcUserToModelUser(&before), restored); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// PurgeDeletedUsers permanently deletes the users deleted before the given time, with everything that
+// references them, and returns how many were purged. Each purge is recorded in the audit log.
+func (r *UserRepository) PurgeDeletedUsers(deletedBefore time.Time) (int, error) {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	ids, err := qtx.PurgeDeletedUsers(ctx, utils.ToEpochMillis(deletedBefore))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := r.recordChange(ctx, qtx, nil, audit_model.ActionUserPurged, id, nil, nil); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// ChangeStatus moves a user to another status, recording the reason, the actor and an audit event.
+// Leaving the active status bumps the user's credential version and revokes their sessions, so that
+// existing tokens stop working. It returns sql.ErrNoRows if there is no such user and
+// ErrInvalidStatusTransition if the user's current status may not change to status.
+func (r *UserRepository) ChangeStatus(id int32, status, reason string, origin *audit_model.Origin) (*model.User, error) {
+	ctx := context.Background()
+	now := utils.ToEpochMillis(time.Now())
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := r.queries.WithTx(tx)
+
+	result, err := qtx.GetUserByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, err
+	}
+	before := r.sqlcUserToModelUser(&result)
+	if !before.CanChangeStatusTo(status) {
+		return nil, ErrInvalidStatusTransition
+	}
+
+	var changedBy pgtype.Int4
+	if origin != nil && origin.ActorID != nil {
+		changedBy = pgtype.Int4{Int32: *origin.ActorID, Valid: true}
+	}
+
+	result, err = qtx.SetUserStatus(ctx, repository.SetUserStatusParams{
+		ID:              id,
+		Status:          status,
+		StatusReason:    pgtype.Text{String: reason, Valid: reason != ""},
+		StatusChangedAt: now,
+		StatusChangedBy: changedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if before.IsActive() {
+		_, err = qtx.RevokeUserSessions(ctx, repository.RevokeUserSessionsParams{
+			UserID:    id,
+			RevokedAt: now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = qtx.RevokeUserRefreshTokens(ctx, repository.RevokeUserRefreshTokensParams{
+			UserID:    id,
+			RevokedAt: now,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	changed := r.sqlcUserToModelUser(&result)
+	if err := r.recordChange(ctx, qtx, origin, audit_model.ActionUserStatusChanged, id, before, changed); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// LockUser locks a user on behalf of a security system. Locking a user that is already locked does nothing.
+func (r *UserRepository) LockUser(id int32, reason string) error {
+	_, err := r.ChangeStatus(id, model.StatusLocked, reason, nil)
+	if errors.Is(err, ErrInvalidStatusTransition) {
+		return nil
+	}
+	return err
 }
 
 // UserExists checks if a user exists by email
 func (r *UserRepository) UserExists(email string) (bool, error) {
 	ctx := context.Background()
 
-	exists, err := r.queries.UserExists(ctx, email)
+	exists, err := r.queries.UserExists(ctx, pgtype.Text{String: email, Valid: true})
 	if err != nil {
 		return false, err
 	}
@@ -225,13 +636,16 @@ func (r *UserRepository) UserExists(email string) (bool, error) {
 	return exists, nil
 }
 
-// GetUsersWithPagination retrieves users with pagination
-func (r *UserRepository) GetUsersWithPagination(limit, offset int32) ([]model.User, error) {
+// GetUsersWithPagination retrieves users with pagination, leaving out service accounts and deleted users unless the filter asks for them
+func (r *UserRepository) GetUsersWithPagination(limit, offset int32, filter *model.UserFilter) ([]model.User, error) {
 	ctx := context.Background()
 
 	params := repository.GetUsersWithPaginationParams{
-		Limit:  limit,
-		Offset: offset,
+		IncludeServiceAccounts: filter.IncludeServiceAccounts,
+		IncludeDeleted:         filter.IncludeDeleted,
+		Status:                 nullableStatus(filter.Status),
+		Limit:                  limit,
+		Offset:                 offset,
 	}
 
 	results, err := r.queries.GetUsersWithPagination(ctx, params)
@@ -241,33 +655,146 @@ func (r *UserRepository) GetUsersWithPagination(limit, offset int32) ([]model.Us
 
 	users := make([]model.User, len(results))
 	for i, result := range results {
-		createdAt := time.Time{}
-		if result.CreatedAt.Valid {
-			createdAt = result.CreatedAt.Time
+		createdAt := utils.FromEpochMillis(result.CreatedAt)
+		updatedAt := utils.FromEpochMillis(result.UpdatedAt)
+
+		users[i] = model.User{
+			ID:              result.ID,
+			Username:        result.Username,
+			Email:           result.Email.String,
+			CreatedAt:       createdAt,
+			UpdatedAt:       updatedAt,
+			EmailVerifiedAt: utils.NullableFromEpochMillis(result.EmailVerifiedAt),
+			AccountType:     result.AccountType,
+			DeletedAt:       utils.NullableFromEpochMillis(result.DeletedAt),
+			Status:          result.Status,
 		}
+	}
 
-		updatedAt := time.Time{}
-		if result.UpdatedAt.Valid {
-			updatedAt = result.UpdatedAt.Time
+	return users, nil
+}
+
+// CountUsers returns the total number of users matching the filter
+func (r *UserRepository) CountUsers(filter *model.UserFilter) (int, error) {
+	ctx := context.Background()
+
+	count, err := r.queries.CountUsers(ctx, repository.CountUsersParams{
+		IncludeServiceAccounts: filter.IncludeServiceAccounts,
+		IncludeDeleted:         filter.IncludeDeleted,
+		Status:                 nullableStatus(filter.Status),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// ListOrganizationUsers returns a page of up to limit members of an organization, newest first, paged like ListUsers.
+// Only members with the given status are listed unless it is empty.
+func (r *UserRepository) ListOrganizationUsers(organizationID int32, status string, cursor *pagination.Cursor, limit int32) ([]model.User, bool, error) {
+	ctx := context.Background()
+
+	// One user more than the page holds tells whether more follow
+	var results []repository.ListOrganizationUsersRow
+	if cursor != nil && cursor.Backward {
+		before, err := r.queries.ListOrganizationUsersBefore(ctx, repository.ListOrganizationUsersBeforeParams{
+			OrganizationID:  organizationID,
+			Status:          nullableStatus(status),
+			BeforeCreatedAt: cursor.CreatedAt,
+			BeforeID:        cursor.ID,
+			Limit:           limit + 1,
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		// Users before the cursor come oldest first
+		results = make([]repository.ListOrganizationUsersRow, len(before))
+		for i, result := range before {
+			results[len(before)-1-i] = repository.ListOrganizationUsersRow(result)
+		}
+	} else {
+		params := repository.ListOrganizationUsersParams{
+			OrganizationID: organizationID,
+			Status:         nullableStatus(status),
+			Limit:          limit + 1,
+		}
+		if cursor != nil {
+			params.AfterCreatedAt = pgtype.Int8{Int64: cursor.CreatedAt, Valid: true}
+			params.AfterID = pgtype.Int4{Int32: cursor.ID, Valid: true}
+		}
+		var err error
+		results, err = r.queries.ListOrganizationUsers(ctx, params)
+		if err != nil {
+			return nil, false, err
 		}
+	}
+
+	users := make([]model.User, len(results))
+	for i, result := range results {
+		createdAt := utils.FromEpochMillis(result.CreatedAt)
+		updatedAt := utils.FromEpochMillis(result.UpdatedAt)
+
+		users[i] = model.User{
+			ID:              result.ID,
+			Username:        result.Username,
+			Email:           result.Email.String,
+			CreatedAt:       createdAt,
+			UpdatedAt:       updatedAt,
+			EmailVerifiedAt: utils.NullableFromEpochMillis(result.EmailVerifiedAt),
+			AccountType:     result.AccountType,
+			Status:          result.Status,
+		}
+	}
+
+	users, more := trimPage(users, limit, cursor)
+	return users, more, nil
+}
+
+// GetOrganizationUsersWithPagination retrieves members of an organization with pagination, filtered by status like GetAllOrganizationUsers
+func (r *UserRepository) GetOrganizationUsersWithPagination(organizationID, limit, offset int32, status string) ([]model.User, error) {
+	ctx := context.Background()
+
+	params := repository.GetOrganizationUsersWithPaginationParams{
+		OrganizationID: organizationID,
+		Status:         nullableStatus(status),
+		Limit:          limit,
+		Offset:         offset,
+	}
+
+	results, err := r.queries.GetOrganizationUsersWithPagination(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]model.User, len(results))
+	for i, result := range results {
+		createdAt := utils.FromEpochMillis(result.CreatedAt)
+		updatedAt := utils.FromEpochMillis(result.UpdatedAt)
 
 		users[i] = model.User{
-			ID:        result.ID,
-			Username:  result.Username,
-			Email:     result.Email,
-			CreatedAt: createdAt,
-			UpdatedAt: updatedAt,
+			ID:              result.ID,
+			Username:        result.Username,
+			Email:           result.Email.String,
+			CreatedAt:       createdAt,
+			UpdatedAt:       updatedAt,
+			EmailVerifiedAt: utils.NullableFromEpochMillis(result.EmailVerifiedAt),
+			AccountType:     result.AccountType,
+			Status:          result.Status,
 		}
 	}
 
 	return users, nil
 }
 
-// CountUsers returns the total number of users
-func (r *UserRepository) CountUsers() (int, error) {
+// CountOrganizationUsers returns the number of members of an organization, only those with the given status unless it is empty
+func (r *UserRepository) CountOrganizationUsers(organizationID int32, status string) (int, error) {
 	ctx := context.Background()
 
-	count, err := r.queries.CountUsers(ctx)
+	count, err := r.queries.CountOrganizationUsers(ctx, repository.CountOrganizationUsersParams{
+		OrganizationID: organizationID,
+		Status:         nullableStatus(status),
+	})
 	if err != nil {
 		return 0, err
 	}
@@ -275,24 +802,50 @@ func (r *UserRepository) CountUsers() (int, error) {
 	return int(count), nil
 }
 
-// Helper method to convert sqlc User to model User
-func (r *UserRepository) sqlcUserToModelUser(sqlcUser *repository.User) *model.User {
-	createdAt := time.Time{}
-	if sqlcUser.CreatedAt.Valid {
-		createdAt = sqlcUser.CreatedAt.Time
+// trimPage drops the user fetched beyond limit to tell whether more follow: the first user of a page
+// before a backward cursor, the last one otherwise
+func trimPage(users []model.User, limit int32, cursor *pagination.Cursor) ([]model.User, bool) {
+	if len(users) <= int(limit) {
+		return users, false
 	}
-
-	updatedAt := time.Time{}
-	if sqlcUser.UpdatedAt.Valid {
-		updatedAt = sqlcUser.UpdatedAt.Time
+	if cursor != nil && cursor.Backward {
+		return users[1:], true
 	}
+	return users[:limit], true
+}
+
+// nullableStatus turns an empty status filter into NULL, which matches every status
+func nullableStatus(status string) pgtype.Text {
+	return pgtype.Text{String: status, Valid: status != ""}
+}
+
+// recordChange writes an audit event about a user in the transaction of qtx
+func (r *UserRepository) recordChange(ctx context.Context, qtx *repository.Queries, origin *audit_model.Origin, action string, userID int32, before, after *model.User) error {
+	return audit_repository.Record(ctx, qtx, origin, action, audit_model.TargetUser, strconv.FormatInt(int64(userID), 10), before, after)
+}
 
-	return &model.User{
-		ID:        sqlcUser.ID,
-		Username:  sqlcUser.Username,
-		Email:     sqlcUser.Email,
-		Password:  sqlcUser.Password,
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
+// Helper method to convert sqlc User to model User
+func (r *UserRepository) sqlcUserToModelUser(sqlcUser *repository.User) *model.User {
+	createdAt := utils.FromEpochMillis(sqlcUser.CreatedAt)
+	updatedAt := utils.FromEpochMillis(sqlcUser.UpdatedAt)
+
+	user := &model.User{
+		ID:                sqlcUser.ID,
+		Username:          sqlcUser.Username,
+		Email:             sqlcUser.Email.String,
+		Password:          sqlcUser.Password.String,
+		CreatedAt:         createdAt,
+		UpdatedAt:         updatedAt,
+		CredentialVersion: sqlcUser.CredentialVersion,
+		EmailVerifiedAt:   utils.NullableFromEpochMillis(sqlcUser.EmailVerifiedAt),
+		AccountType:       sqlcUser.AccountType,
+		DeletedAt:         utils.NullableFromEpochMillis(sqlcUser.DeletedAt),
+		Status:            sqlcUser.Status,
+		StatusReason:      sqlcUser.StatusReason.String,
+		StatusChangedAt:   utils.NullableFromEpochMillis(sqlcUser.StatusChangedAt),
+	}
+	if sqlcUser.StatusChangedBy.Valid {
+		user.StatusChangedBy = &sqlcUser.StatusChangedBy.Int32
 	}
+	return user
 }
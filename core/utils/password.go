@@ -1,9 +1,23 @@
 package utils
 
 import (
+	"errors"
+	"strings"
+	"unicode"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	MinPasswordLength = 8
+	// bcrypt ignores everything after 72 bytes
+	MaxPasswordLength = 72
+)
+
+// DummyPasswordHash is compared against when no user matches a login,
+// so unknown accounts take as long to reject as wrong passwords
+const DummyPasswordHash = "$2a$10$YarfigENxilgCtYsQww/ue4bcH47jggq.56NM4s5jEF9uQ/bmCBVa"
+
 // HashPassword hashes a password using bcrypt
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -18,3 +32,52 @@ func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// ValidatePasswordStrength enforces the minimum password policy.
+// Passwords must be 8 to 72 bytes, mix at least three of lowercase, uppercase, digits and symbols,
+// and must not contain any of the given identifiers such as the username or email.
+func ValidatePasswordStrength(password string, identifiers ...string) error {
+	if len(password) < MinPasswordLength {
+		return errors.New("password must be at least 8 characters long")
+	}
+	if len(password) > MaxPasswordLength {
+		return errors.New("password must be at most 72 bytes long")
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	if classes < 3 {
+		return errors.New("password must contain at least three of lowercase letters, uppercase letters, digits and symbols")
+	}
+
+	lowered := strings.ToLower(password)
+	for _, identifier := range identifiers {
+		identifier = strings.ToLower(strings.TrimSpace(identifier))
+		if local, _, found := strings.Cut(identifier, "@"); found {
+			identifier = local
+		}
+		if len(identifier) >= 3 && strings.Contains(lowered, identifier) {
+			return errors.New("password must not contain your username or email")
+		}
+	}
+
+	return nil
+}
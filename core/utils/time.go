@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ToEpochMillis converts a time.Time to the epoch millisecond representation used by the schema
+func ToEpochMillis(t time.Time) pgtype.Int8 {
+	return pgtype.Int8{
+		Int64: t.UnixMilli(),
+		Valid: true,
+	}
+}
+
+// FromEpochMillis converts an epoch millisecond column back to time.Time
+func FromEpochMillis(v pgtype.Int8) time.Time {
+	if !v.Valid {
+		return time.Time{}
+	}
+	return time.UnixMilli(v.Int64)
+}
+
+// NullableFromEpochMillis converts a nullable epoch millisecond column to a *time.Time
+func NullableFromEpochMillis(v pgtype.Int8) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := time.UnixMilli(v.Int64)
+	return &t
+}
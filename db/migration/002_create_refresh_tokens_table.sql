@@ -0,0 +1,16 @@
+-- Create refresh_tokens table
+-- Tokens are stored as SHA-256 hashes; every rotation of a login shares the same family_id
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    family_id VARCHAR(64) NOT NULL,
+    expires_at int8 NOT NULL,
+    used_at int8,
+    revoked_at int8,
+    created_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000)
+);
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
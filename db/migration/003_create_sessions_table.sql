@@ -0,0 +1,14 @@
+-- Create sessions table
+-- A session is created per login; its id is also the family_id of the session's refresh tokens
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    last_seen_at int8 NOT NULL,
+    revoked_at int8,
+    created_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000)
+);
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
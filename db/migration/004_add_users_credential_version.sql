@@ -0,0 +1,3 @@
+-- Add credential_version to users
+-- Bumped whenever the password changes so tokens and sessions issued earlier can be rejected
+ALTER TABLE users ADD COLUMN IF NOT EXISTS credential_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,13 @@
+-- Create password_reset_tokens table
+-- Tokens are single-use and stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at int8 NOT NULL,
+    used_at int8,
+    created_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000)
+);
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
@@ -0,0 +1,17 @@
+-- Track whether a user's email address has been proven
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at int8;
+
+-- Create email_verification_tokens table
+-- A token verifies the exact address it was sent to, so changing the email invalidates it
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at int8 NOT NULL,
+    used_at int8,
+    created_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000)
+);
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens(user_id);
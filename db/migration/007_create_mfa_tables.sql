@@ -0,0 +1,21 @@
+-- Create user_totp table
+-- Secrets are AES-GCM encrypted; confirmed_at stays NULL until the first code is verified
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret_encrypted TEXT NOT NULL,
+    confirmed_at int8,
+    -- Highest accepted time step, so a code cannot be replayed
+    last_used_step int8 NOT NULL DEFAULT 0,
+    created_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000)
+);
+
+-- Create mfa_recovery_codes table
+-- Codes are single-use and stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at int8,
+    created_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000),
+    UNIQUE (user_id, code_hash)
+);
@@ -0,0 +1,29 @@
+-- Create webauthn_credentials table
+-- public_key holds the COSE_Key reported by the authenticator at registration
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id BYTEA NOT NULL UNIQUE,
+    public_key BYTEA NOT NULL,
+    sign_count int8 NOT NULL DEFAULT 0,
+    aaguid BYTEA,
+    transports TEXT NOT NULL DEFAULT '',
+    name VARCHAR(100) NOT NULL,
+    last_used_at int8,
+    created_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000)
+);
+
+-- Create webauthn_challenges table
+-- Challenges are single-use; user_id is NULL for logins with discoverable credentials
+CREATE TABLE IF NOT EXISTS webauthn_challenges (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    ceremony VARCHAR(16) NOT NULL,
+    challenge BYTEA NOT NULL,
+    expires_at int8 NOT NULL,
+    created_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000)
+);
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials(user_id);
+CREATE INDEX IF NOT EXISTS idx_webauthn_challenges_expires_at ON webauthn_challenges(expires_at);
@@ -0,0 +1,2 @@
+-- Carry the OpenID Connect nonce from the authorization request into the ID token
+ALTER TABLE oauth_authorization_codes ADD COLUMN IF NOT EXISTS nonce TEXT NOT NULL DEFAULT '';
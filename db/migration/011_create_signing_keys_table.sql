@@ -0,0 +1,19 @@
+-- Create signing_keys table
+-- Private keys are PKCS#8 encrypted with AES-GCM under JWT_MASTER_KEY; public_key is PKIX DER.
+-- Exactly one key is active; retired keys stay published until expires_at so their tokens still verify.
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id SERIAL PRIMARY KEY,
+    kid VARCHAR(64) NOT NULL UNIQUE,
+    algorithm VARCHAR(16) NOT NULL,
+    private_key_encrypted TEXT NOT NULL,
+    public_key BYTEA NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    activated_at int8 NOT NULL,
+    retired_at int8,
+    expires_at int8,
+    created_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000)
+);
+
+-- Create indexes for better performance
+CREATE UNIQUE INDEX IF NOT EXISTS idx_signing_keys_single_active ON signing_keys(status) WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS idx_signing_keys_expires_at ON signing_keys(expires_at);
@@ -0,0 +1,11 @@
+-- Create oauth_revoked_access_tokens table
+-- Access tokens are self-contained JWTs, so revoking one records its jti until the token would have expired
+CREATE TABLE IF NOT EXISTS oauth_revoked_access_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+    expires_at int8 NOT NULL,
+    revoked_at int8 NOT NULL
+);
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_oauth_revoked_access_tokens_expires_at ON oauth_revoked_access_tokens(expires_at);
@@ -0,0 +1,27 @@
+-- Create organizations table
+-- Each customer organisation is a tenant; slug is its stable, URL-safe handle
+CREATE TABLE IF NOT EXISTS organizations (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    created_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000),
+    updated_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000)
+);
+
+-- Create organization_members table
+-- role is the member's role within the organization: owner, admin or member
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL,
+    created_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000),
+    updated_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+-- Add organization_id to sessions
+-- The organization the session acts within; access tokens of the session carry it as the org claim
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL;
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
@@ -0,0 +1,20 @@
+-- Create api_keys table
+-- Personal API keys authenticate scripts as their owner. Only a hash of the key is stored;
+-- prefix is the visible start of the key so owners can tell their keys apart.
+-- scopes names the permissions the key may exercise on top of acting on the owner's own account.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL UNIQUE,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    expires_at int8,
+    last_used_at int8,
+    last_used_ip VARCHAR(45),
+    revoked_at int8,
+    created_at int8 DEFAULT FLOOR(EXTRACT (EPOCH FROM now())*1000)
+);
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
@@ -0,0 +1,24 @@
+-- Add service accounts to users table
+-- Service accounts are non-human users for automation. They have no password and no email address;
+-- they authenticate only with API keys and with the secrets of OAuth clients they own.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS account_type VARCHAR(16) NOT NULL DEFAULT 'user';
+ALTER TABLE users ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE users ALTER COLUMN password DROP NOT NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_account_type_check;
+ALTER TABLE users ADD CONSTRAINT users_account_type_check CHECK (
+    (account_type = 'user' AND email IS NOT NULL AND password IS NOT NULL)
+    OR (account_type = 'service' AND password IS NULL)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('service_accounts:manage', 'Manage service accounts and their credentials')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin' AND p.name = 'service_accounts:manage'
+ON CONFLICT DO NOTHING;
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_users_account_type ON users(account_type);
@@ -0,0 +1,12 @@
+-- Create login_attempts table
+-- Counts recent failed password and second factor checks per subject, such as "account:42" or "ip:192.0.2.1",
+-- for brute-force protection. A subject is blocked until blocked_until once it fails too often.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    subject VARCHAR(320) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at int8 NOT NULL,
+    blocked_until int8
+);
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_login_attempts_last_failure_at ON login_attempts(last_failure_at);
@@ -0,0 +1,13 @@
+-- Create rate_limit_counters table
+-- Counts requests per rate limit key and fixed window, so that every instance enforces the same limits.
+-- A counter is needed for two windows: its own, and the next one, which weighs it in as the previous window.
+CREATE TABLE IF NOT EXISTS rate_limit_counters (
+    key VARCHAR(255) NOT NULL,
+    window_start int8 NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    expires_at int8 NOT NULL,
+    PRIMARY KEY (key, window_start)
+);
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_rate_limit_counters_expires_at ON rate_limit_counters(expires_at);
@@ -0,0 +1,36 @@
+-- Chain audit events by hash
+-- Each event stores the hash of the previous event and a SHA-256 hash over its own content and that
+-- previous hash, so editing, inserting or deleting an event breaks the chain from that point on.
+-- Events recorded before this migration keep NULL hashes; the chain starts after them.
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64);
+ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS hash VARCHAR(64);
+
+-- Create audit_checkpoints table
+-- A checkpoint is a signed statement of the hash of the chain up to an event, for archiving outside the database
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    id BIGSERIAL PRIMARY KEY,
+    event_id BIGINT NOT NULL,
+    event_hash VARCHAR(64) NOT NULL,
+    signature TEXT NOT NULL,
+    created_at int8 NOT NULL
+);
+
+-- Name the table in the error so the function serves both append-only tables
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_checkpoints_no_change ON audit_checkpoints;
+CREATE TRIGGER audit_checkpoints_no_change
+    BEFORE UPDATE OR DELETE ON audit_checkpoints
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+DROP TRIGGER IF EXISTS audit_checkpoints_no_truncate ON audit_checkpoints;
+CREATE TRIGGER audit_checkpoints_no_truncate
+    BEFORE TRUNCATE ON audit_checkpoints
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_audit_checkpoints_event_id ON audit_checkpoints(event_id);
@@ -0,0 +1,12 @@
+-- Add soft delete to users table
+-- Deleted users keep their row, hidden from every query, until the purger removes them after the retention period.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at int8;
+
+-- Emails and usernames only need to be unique among users that are not deleted, so they can be reused
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_unique ON users(email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_unique ON users(username) WHERE deleted_at IS NULL;
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
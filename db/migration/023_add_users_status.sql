@@ -0,0 +1,14 @@
+-- Add account status to users table
+-- Only active users can authenticate. Users are pending until they verify their email address when
+-- verification is required, suspended and reactivated by administrators, and locked by security systems.
+-- Every change records its reason and the user who made it, NULL for changes made by the system.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_reason TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_changed_at int8;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users ADD CONSTRAINT users_status_check CHECK (status IN ('pending', 'active', 'suspended', 'locked'));
+
+-- Create indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_users_status ON users(status);
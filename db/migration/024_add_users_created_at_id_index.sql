@@ -0,0 +1,3 @@
+-- Index users for keyset pagination
+-- Listings page through users ordered by (created_at, id); the ID breaks ties between users created in the same millisecond.
+CREATE INDEX IF NOT EXISTS idx_users_created_at_id ON users(created_at, id);
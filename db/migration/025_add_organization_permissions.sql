@@ -0,0 +1,12 @@
+-- Add organization permissions
+-- Access within an organization is decided by the member's organization role. These permissions gate the
+-- organization routes as a whole, so that API keys reach them only when given the matching scope.
+INSERT INTO permissions (name, description) VALUES
+    ('organizations:read', 'Read your organizations, their members and invitations'),
+    ('organizations:manage', 'Create organizations and manage their members and invitations')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name IN ('admin', 'user') AND p.name IN ('organizations:read', 'organizations:manage')
+ON CONFLICT DO NOTHING;
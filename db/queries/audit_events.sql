@@ -0,0 +1,46 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (actor_id, actor_api_key_id, action, target_type, target_id, request_id, ip_address, user_agent, changes, created_at, prev_hash, hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: LockAuditChain :exec
+-- Serializes appends to the hash chain until the end of the transaction
+SELECT pg_advisory_xact_lock(hashtext('audit_events'));
+
+-- name: GetLastAuditEvent :one
+SELECT id, hash FROM audit_events ORDER BY id DESC LIMIT 1;
+
+-- name: ListAuditEvents :many
+SELECT id, actor_id, actor_api_key_id, action, target_type, target_id, request_id, ip_address, user_agent, changes, created_at, prev_hash, hash
+FROM audit_events
+WHERE (sqlc.narg(actor_id)::int4 IS NULL OR actor_id = sqlc.narg(actor_id))
+  AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(target_type)::text IS NULL OR target_type = sqlc.narg(target_type))
+  AND (sqlc.narg(target_id)::text IS NULL OR target_id = sqlc.narg(target_id))
+  AND (sqlc.narg(created_from)::int8 IS NULL OR created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::int8 IS NULL OR created_at < sqlc.narg(created_to))
+  AND (sqlc.narg(before_id)::int8 IS NULL OR id < sqlc.narg(before_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListAuditEventsAfter :many
+SELECT id, actor_id, actor_api_key_id, action, target_type, target_id, request_id, ip_address, user_agent, changes, created_at, prev_hash, hash
+FROM audit_events
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: CreateAuditCheckpoint :one
+INSERT INTO audit_checkpoints (event_id, event_hash, signature, created_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, event_id, event_hash, signature, created_at;
+
+-- name: GetLatestAuditCheckpoint :one
+SELECT id, event_id, event_hash, signature, created_at
+FROM audit_checkpoints
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListAuditCheckpoints :many
+SELECT id, event_id, event_hash, signature, created_at
+FROM audit_checkpoints
+ORDER BY id;
@@ -0,0 +1,19 @@
+-- name: CreateEmailVerificationToken :one
+INSERT INTO email_verification_tokens (user_id, email, token_hash, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, email, token_hash, expires_at, used_at, created_at;
+
+-- name: GetEmailVerificationTokenByHash :one
+SELECT id, user_id, email, token_hash, expires_at, used_at, created_at
+FROM email_verification_tokens
+WHERE token_hash = $1;
+
+-- name: ConsumeEmailVerificationToken :execrows
+UPDATE email_verification_tokens
+SET used_at = $2
+WHERE id = $1 AND used_at IS NULL;
+
+-- name: InvalidateUserEmailVerificationTokens :exec
+UPDATE email_verification_tokens
+SET used_at = $2
+WHERE user_id = $1 AND used_at IS NULL;
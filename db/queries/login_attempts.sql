@@ -0,0 +1,24 @@
+-- name: GetLoginAttempt :one
+SELECT subject, failures, last_failure_at, blocked_until
+FROM login_attempts
+WHERE subject = $1;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_attempts (subject, failures, last_failure_at)
+VALUES (sqlc.arg(subject), 1, sqlc.arg(failed_at))
+ON CONFLICT (subject) DO UPDATE
+SET failures = CASE WHEN login_attempts.last_failure_at < sqlc.arg(reset_before) THEN 1 ELSE login_attempts.failures + 1 END,
+    last_failure_at = sqlc.arg(failed_at)
+RETURNING failures;
+
+-- name: BlockLoginSubject :exec
+UPDATE login_attempts
+SET blocked_until = GREATEST(COALESCE(blocked_until, 0), sqlc.arg(blocked_until)::int8)
+WHERE subject = sqlc.arg(subject);
+
+-- name: DeleteLoginAttempt :exec
+DELETE FROM login_attempts WHERE subject = $1;
+
+-- name: DeleteStaleLoginAttempts :execrows
+DELETE FROM login_attempts
+WHERE last_failure_at < sqlc.arg(reset_before) AND (blocked_until IS NULL OR blocked_until < sqlc.arg(now)::int8);
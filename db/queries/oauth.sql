@@ -0,0 +1,69 @@
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (client_id, client_secret_hash, name, client_type, redirect_uris, grant_types, scopes, owner_id, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, client_id, client_secret_hash, name, client_type, redirect_uris, grant_types, scopes, owner_id, created_at, updated_at;
+
+-- name: GetOAuthClientByClientID :one
+SELECT id, client_id, client_secret_hash, name, client_type, redirect_uris, grant_types, scopes, owner_id, created_at, updated_at
+FROM oauth_clients
+WHERE client_id = $1
+  AND NOT EXISTS (SELECT 1 FROM users WHERE users.id = oauth_clients.owner_id AND users.deleted_at IS NOT NULL);
+
+-- name: ListOAuthClientsByOwner :many
+SELECT id, client_id, client_secret_hash, name, client_type, redirect_uris, grant_types, scopes, owner_id, created_at, updated_at
+FROM oauth_clients
+WHERE owner_id = $1
+ORDER BY created_at;
+
+-- name: DeleteOAuthClient :one
+DELETE FROM oauth_clients
+WHERE client_id = $1 AND owner_id = $2
+RETURNING id, client_id, client_secret_hash, name, client_type, redirect_uris, grant_types, scopes, owner_id, created_at, updated_at;
+
+-- name: CreateOAuthAuthorizationCode :exec
+INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scope, code_challenge, nonce, family_id, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: GetOAuthAuthorizationCodeByHash :one
+SELECT id, code_hash, client_id, user_id, redirect_uri, scope, code_challenge, family_id, expires_at, used_at, created_at, nonce
+FROM oauth_authorization_codes
+WHERE code_hash = $1;
+
+-- name: ConsumeOAuthAuthorizationCode :execrows
+UPDATE oauth_authorization_codes
+SET used_at = $2
+WHERE id = $1 AND used_at IS NULL;
+
+-- name: CreateOAuthRefreshToken :exec
+INSERT INTO oauth_refresh_tokens (token_hash, family_id, client_id, user_id, scope, credential_version, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: GetOAuthRefreshTokenByHash :one
+SELECT id, token_hash, family_id, client_id, user_id, scope, credential_version, expires_at, used_at, revoked_at, created_at
+FROM oauth_refresh_tokens
+WHERE token_hash = $1;
+
+-- name: MarkOAuthRefreshTokenUsed :execrows
+UPDATE oauth_refresh_tokens
+SET used_at = $2
+WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL;
+
+-- name: RevokeOAuthRefreshTokenFamily :exec
+UPDATE oauth_refresh_tokens
+SET revoked_at = $2
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: IsOAuthRefreshTokenFamilyRevoked :one
+SELECT EXISTS(SELECT 1 FROM oauth_refresh_tokens WHERE family_id = $1 AND revoked_at IS NOT NULL);
+
+-- name: RevokeOAuthAccessToken :exec
+INSERT INTO oauth_revoked_access_tokens (jti, client_id, expires_at, revoked_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: IsOAuthAccessTokenRevoked :one
+SELECT EXISTS(SELECT 1 FROM oauth_revoked_access_tokens WHERE jti = $1);
+
+-- name: DeleteExpiredOAuthRevokedAccessTokens :exec
+DELETE FROM oauth_revoked_access_tokens
+WHERE expires_at < $1;
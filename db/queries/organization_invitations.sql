@@ -0,0 +1,30 @@
+-- name: CreateOrganizationInvitation :one
+INSERT INTO organization_invitations (organization_id, email, role, token_hash, invited_by, expires_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, organization_id, email, role, token_hash, invited_by, status, expires_at, responded_at, created_at, updated_at;
+
+-- name: GetOrganizationInvitationByID :one
+SELECT id, organization_id, email, role, token_hash, invited_by, status, expires_at, responded_at, created_at, updated_at
+FROM organization_invitations
+WHERE id = $1;
+
+-- name: GetOrganizationInvitationByTokenHash :one
+SELECT id, organization_id, email, role, token_hash, invited_by, status, expires_at, responded_at, created_at, updated_at
+FROM organization_invitations
+WHERE token_hash = $1;
+
+-- name: ListPendingOrganizationInvitations :many
+SELECT id, organization_id, email, role, token_hash, invited_by, status, expires_at, responded_at, created_at, updated_at
+FROM organization_invitations
+WHERE organization_id = $1 AND status = 'pending'
+ORDER BY created_at DESC;
+
+-- name: SetOrganizationInvitationStatus :execrows
+UPDATE organization_invitations
+SET status = $2, responded_at = $3, updated_at = $3
+WHERE id = $1 AND status = 'pending';
+
+-- name: RenewOrganizationInvitation :execrows
+UPDATE organization_invitations
+SET token_hash = $2, expires_at = $3, updated_at = $4
+WHERE id = $1 AND status = 'pending';
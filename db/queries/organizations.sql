@@ -0,0 +1,54 @@
+-- name: CreateOrganization :one
+INSERT INTO organizations (slug, name, created_at, updated_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, slug, name, created_at, updated_at;
+
+-- name: GetOrganizationByID :one
+SELECT id, slug, name, created_at, updated_at
+FROM organizations
+WHERE id = $1;
+
+-- name: GetOrganizationByIDForUpdate :one
+SELECT id, slug, name, created_at, updated_at
+FROM organizations
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListOrganizationsByUser :many
+SELECT o.id, o.slug, o.name, o.created_at, o.updated_at, m.role
+FROM organizations o
+JOIN organization_members m ON m.organization_id = o.id
+WHERE m.user_id = $1
+ORDER BY o.name;
+
+-- name: AddOrganizationMember :exec
+INSERT INTO organization_members (organization_id, user_id, role, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetOrganizationMember :one
+SELECT organization_id, user_id, role, created_at, updated_at
+FROM organization_members
+WHERE organization_id = $1 AND user_id = $2;
+
+-- name: IsOrganizationMember :one
+SELECT EXISTS(SELECT 1 FROM organization_members WHERE organization_id = $1 AND user_id = $2);
+
+-- name: ListOrganizationMembers :many
+SELECT m.organization_id, m.user_id, u.username, u.email, m.role, m.created_at, m.updated_at
+FROM organization_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.organization_id = $1 AND u.deleted_at IS NULL
+ORDER BY u.username;
+
+-- name: UpdateOrganizationMemberRole :execrows
+UPDATE organization_members
+SET role = $3, updated_at = $4
+WHERE organization_id = $1 AND user_id = $2;
+
+-- name: DeleteOrganizationMember :execrows
+DELETE FROM organization_members
+WHERE organization_id = $1 AND user_id = $2;
+
+-- name: CountOrganizationOwners :one
+SELECT COUNT(*) FROM organization_members
+WHERE organization_id = $1 AND role = 'owner';
@@ -0,0 +1,17 @@
+-- name: IncrementRateLimitCounter :one
+WITH current_window AS (
+    INSERT INTO rate_limit_counters (key, window_start, count, expires_at)
+    VALUES (sqlc.arg(key), sqlc.arg(window_start), 1, sqlc.arg(expires_at))
+    ON CONFLICT (key, window_start) DO UPDATE
+    SET count = rate_limit_counters.count + 1
+    RETURNING count
+)
+SELECT current_window.count AS current_count,
+       COALESCE((
+           SELECT previous_window.count FROM rate_limit_counters previous_window
+           WHERE previous_window.key = sqlc.arg(key) AND previous_window.window_start = sqlc.arg(previous_window_start)
+       ), 0)::int4 AS previous_count
+FROM current_window;
+
+-- name: DeleteExpiredRateLimitCounters :execrows
+DELETE FROM rate_limit_counters WHERE expires_at < $1;
@@ -0,0 +1,96 @@
+-- name: CreateRole :one
+INSERT INTO roles (name, description, created_at, updated_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, name, description, created_at, updated_at;
+
+-- name: GetRoleByID :one
+SELECT id, name, description, created_at, updated_at
+FROM roles
+WHERE id = $1;
+
+-- name: GetRoleByName :one
+SELECT id, name, description, created_at, updated_at
+FROM roles
+WHERE name = $1;
+
+-- name: GetRoleByIDForUpdate :one
+SELECT id, name, description, created_at, updated_at
+FROM roles
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListRoles :many
+SELECT id, name, description, created_at, updated_at
+FROM roles
+ORDER BY name;
+
+-- name: UpdateRole :exec
+UPDATE roles
+SET name = $2, description = $3, updated_at = $4
+WHERE id = $1;
+
+-- name: DeleteRole :exec
+DELETE FROM roles WHERE id = $1;
+
+-- name: ListPermissions :many
+SELECT id, name, description, created_at
+FROM permissions
+ORDER BY name;
+
+-- name: CountPermissionsByName :one
+SELECT COUNT(*) FROM permissions WHERE name = ANY(sqlc.arg(names)::text[]);
+
+-- name: ListRolePermissionNames :many
+SELECT rp.role_id, p.name
+FROM role_permissions rp
+JOIN permissions p ON p.id = rp.permission_id
+ORDER BY rp.role_id, p.name;
+
+-- name: ListRolePermissionNamesByRole :many
+SELECT p.name
+FROM role_permissions rp
+JOIN permissions p ON p.id = rp.permission_id
+WHERE rp.role_id = $1
+ORDER BY p.name;
+
+-- name: AddRolePermissions :exec
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT sqlc.arg(role_id)::int, id FROM permissions WHERE name = ANY(sqlc.arg(names)::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteRolePermissions :exec
+DELETE FROM role_permissions WHERE role_id = $1;
+
+-- name: ListUserRoles :many
+SELECT r.id, r.name, r.description, r.created_at, r.updated_at
+FROM roles r
+JOIN user_roles ur ON ur.role_id = r.id
+WHERE ur.user_id = $1
+ORDER BY r.name;
+
+-- name: ListUserPermissionNames :many
+SELECT DISTINCT p.name
+FROM permissions p
+JOIN role_permissions rp ON rp.permission_id = p.id
+JOIN user_roles ur ON ur.role_id = rp.role_id
+WHERE ur.user_id = $1
+ORDER BY p.name;
+
+-- name: AssignUserRole :execrows
+INSERT INTO user_roles (user_id, role_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: AssignUserRoleByName :exec
+INSERT INTO user_roles (user_id, role_id, created_at)
+SELECT sqlc.arg(user_id)::int, id, sqlc.arg(created_at)::int8 FROM roles WHERE name = sqlc.arg(name)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveUserRole :execrows
+DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2;
+
+-- name: CountUsersWithRole :one
+SELECT COUNT(*)
+FROM user_roles ur
+JOIN users u ON u.id = ur.user_id
+WHERE ur.role_id = $1 AND u.deleted_at IS NULL;
@@ -0,0 +1,51 @@
+-- name: CreateSession :one
+INSERT INTO sessions (id, user_id, user_agent, ip_address, last_seen_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, user_agent, ip_address, last_seen_at, revoked_at, created_at, organization_id;
+
+-- name: ListActiveSessionsByUser :many
+SELECT id, user_id, user_agent, ip_address, last_seen_at, revoked_at, created_at, organization_id
+FROM sessions
+WHERE user_id = $1 AND revoked_at IS NULL
+ORDER BY last_seen_at DESC;
+
+-- name: TouchSession :one
+UPDATE sessions
+SET last_seen_at = $2, user_agent = $3, ip_address = $4
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING id, user_id, user_agent, ip_address, last_seen_at, revoked_at, created_at, organization_id;
+
+-- name: IsSessionActive :one
+SELECT EXISTS(SELECT 1 FROM sessions WHERE id = $1 AND revoked_at IS NULL);
+
+-- name: RevokeSession :execrows
+UPDATE sessions
+SET revoked_at = $3
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeSessionByID :exec
+UPDATE sessions
+SET revoked_at = $2
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :many
+UPDATE sessions
+SET revoked_at = $2
+WHERE user_id = $1 AND revoked_at IS NULL
+RETURNING id;
+
+-- name: RevokeOtherUserSessions :many
+UPDATE sessions
+SET revoked_at = $3
+WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL
+RETURNING id;
+
+-- name: SetSessionOrganization :execrows
+UPDATE sessions
+SET organization_id = $3
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: ClearSessionsOrganization :exec
+UPDATE sessions
+SET organization_id = NULL
+WHERE user_id = $1 AND organization_id = $2;
@@ -0,0 +1,25 @@
+-- name: CreateSigningKey :one
+INSERT INTO signing_keys (kid, algorithm, private_key_encrypted, public_key, status, activated_at, created_at)
+VALUES ($1, $2, $3, $4, 'active', $5, $6)
+RETURNING id, kid, algorithm, private_key_encrypted, public_key, status, activated_at, retired_at, expires_at, created_at;
+
+-- name: GetActiveSigningKeyForUpdate :one
+SELECT id, kid, algorithm, private_key_encrypted, public_key, status, activated_at, retired_at, expires_at, created_at
+FROM signing_keys
+WHERE status = 'active'
+FOR UPDATE;
+
+-- name: ListPublishedSigningKeys :many
+SELECT id, kid, algorithm, private_key_encrypted, public_key, status, activated_at, retired_at, expires_at, created_at
+FROM signing_keys
+WHERE status = 'active' OR expires_at > $1
+ORDER BY activated_at DESC;
+
+-- name: RetireSigningKey :exec
+UPDATE signing_keys
+SET status = 'retired', retired_at = $2, expires_at = $3
+WHERE id = $1;
+
+-- name: DeleteExpiredSigningKeys :execrows
+DELETE FROM signing_keys
+WHERE status = 'retired' AND expires_at <= $1;
@@ -1,44 +1,161 @@
 -- name: CreateUser :one
-INSERT INTO users (username, email, password, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, username, email, password, created_at, updated_at;
+INSERT INTO users (username, email, password, status, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by;
+
+-- name: CreateServiceAccount :one
+INSERT INTO users (username, account_type, created_at, updated_at)
+VALUES ($1, 'service', $2, $3)
+RETURNING id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by;
 
 -- name: GetUserByID :one
-SELECT id, username, email, password, created_at, updated_at
+SELECT id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
+FROM users
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetUserByIDForUpdate :one
+SELECT id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
 FROM users
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: GetDeletedUserByIDForUpdate :one
+SELECT id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
+FROM users
+WHERE id = $1 AND deleted_at IS NOT NULL
+FOR UPDATE;
 
 -- name: GetUserByEmail :one
-SELECT id, username, email, password, created_at, updated_at
+SELECT id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
+FROM users
+WHERE email = $1 AND deleted_at IS NULL;
+
+-- name: GetUserByUsername :one
+SELECT id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
 FROM users
-WHERE email = $1;
+WHERE username = $1 AND deleted_at IS NULL;
 
--- name: GetAllUsers :many
-SELECT id, username, email, created_at, updated_at
+-- name: ListServiceAccounts :many
+SELECT id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by
 FROM users
+WHERE account_type = 'service' AND deleted_at IS NULL
 ORDER BY created_at DESC;
 
--- name: UpdateUser :exec
+-- name: UpdateUser :one
+UPDATE users
+SET username = $2, email = $3, updated_at = $4,
+    email_verified_at = CASE WHEN email = $3 THEN email_verified_at ELSE NULL END
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by;
+
+-- name: UpdatePassword :one
+UPDATE users
+SET password = $2, updated_at = $3, credential_version = credential_version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING credential_version;
+
+-- name: GetUserCredentialVersion :one
+SELECT credential_version FROM users WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: SetUserEmailVerified :execrows
 UPDATE users
-SET username = $2, email = $3, updated_at = $4
-WHERE id = $1;
+SET email_verified_at = $2
+WHERE id = $1 AND email = $3 AND deleted_at IS NULL;
 
--- name: UpdatePassword :exec
+-- name: SoftDeleteUser :one
 UPDATE users
-SET password = $2, updated_at = $3
-WHERE id = $1;
+SET deleted_at = $2, updated_at = $2, credential_version = credential_version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by;
 
--- name: DeleteUser :exec
-DELETE FROM users WHERE id = $1;
+-- name: RestoreUser :one
+UPDATE users
+SET deleted_at = NULL, updated_at = $2
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by;
+
+-- name: SetUserStatus :one
+UPDATE users
+SET status = $2, status_reason = $3, status_changed_at = $4, status_changed_by = $5, updated_at = $4,
+    credential_version = CASE WHEN status = 'active' THEN credential_version + 1 ELSE credential_version END
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, username, email, password, created_at, updated_at, credential_version, email_verified_at, account_type, deleted_at, status, status_reason, status_changed_at, status_changed_by;
+
+-- name: PurgeDeletedUsers :many
+DELETE FROM users
+WHERE deleted_at < $1
+RETURNING id;
 
 -- name: UserExists :one
-SELECT EXISTS(SELECT 1 FROM users WHERE email = $1);
+SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND deleted_at IS NULL);
+
+-- name: ListUsers :many
+SELECT id, username, email, created_at, updated_at, email_verified_at, account_type, deleted_at, status
+FROM users
+WHERE (account_type = 'user' OR sqlc.arg(include_service_accounts)::boolean)
+  AND (deleted_at IS NULL OR sqlc.arg(include_deleted)::boolean)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(after_created_at)::int8 IS NULL OR (created_at, id) < (sqlc.narg(after_created_at)::int8, sqlc.narg(after_id)::int4))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListUsersBefore :many
+SELECT id, username, email, created_at, updated_at, email_verified_at, account_type, deleted_at, status
+FROM users
+WHERE (account_type = 'user' OR sqlc.arg(include_service_accounts)::boolean)
+  AND (deleted_at IS NULL OR sqlc.arg(include_deleted)::boolean)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+  AND (created_at, id) > (sqlc.arg(before_created_at)::int8, sqlc.arg(before_id)::int4)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ListOrganizationUsers :many
+SELECT u.id, u.username, u.email, u.created_at, u.updated_at, u.email_verified_at, u.account_type, u.status
+FROM users u
+JOIN organization_members m ON m.user_id = u.id
+WHERE m.organization_id = sqlc.arg(organization_id) AND u.deleted_at IS NULL
+  AND (sqlc.narg(status)::text IS NULL OR u.status = sqlc.narg(status))
+  AND (sqlc.narg(after_created_at)::int8 IS NULL OR (u.created_at, u.id) < (sqlc.narg(after_created_at)::int8, sqlc.narg(after_id)::int4))
+ORDER BY u.created_at DESC, u.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListOrganizationUsersBefore :many
+SELECT u.id, u.username, u.email, u.created_at, u.updated_at, u.email_verified_at, u.account_type, u.status
+FROM users u
+JOIN organization_members m ON m.user_id = u.id
+WHERE m.organization_id = sqlc.arg(organization_id) AND u.deleted_at IS NULL
+  AND (sqlc.narg(status)::text IS NULL OR u.status = sqlc.narg(status))
+  AND (u.created_at, u.id) > (sqlc.arg(before_created_at)::int8, sqlc.arg(before_id)::int4)
+ORDER BY u.created_at ASC, u.id ASC
+LIMIT sqlc.arg('limit');
 
 -- name: GetUsersWithPagination :many
-SELECT id, username, email, created_at, updated_at
+SELECT id, username, email, created_at, updated_at, email_verified_at, account_type, deleted_at, status
 FROM users
+WHERE (account_type = 'user' OR sqlc.arg(include_service_accounts)::boolean)
+  AND (deleted_at IS NULL OR sqlc.arg(include_deleted)::boolean)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
 ORDER BY created_at DESC
-LIMIT $1 OFFSET $2;
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: CountUsers :one
-SELECT COUNT(*) FROM users;
+SELECT COUNT(*) FROM users
+WHERE (account_type = 'user' OR sqlc.arg(include_service_accounts)::boolean)
+  AND (deleted_at IS NULL OR sqlc.arg(include_deleted)::boolean)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status));
+
+-- name: GetOrganizationUsersWithPagination :many
+SELECT u.id, u.username, u.email, u.created_at, u.updated_at, u.email_verified_at, u.account_type, u.status
+FROM users u
+JOIN organization_members m ON m.user_id = u.id
+WHERE m.organization_id = sqlc.arg(organization_id) AND u.deleted_at IS NULL
+  AND (sqlc.narg(status)::text IS NULL OR u.status = sqlc.narg(status))
+ORDER BY u.created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountOrganizationUsers :one
+SELECT COUNT(*)
+FROM organization_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.organization_id = sqlc.arg(organization_id) AND u.deleted_at IS NULL
+  AND (sqlc.narg(status)::text IS NULL OR u.status = sqlc.narg(status));
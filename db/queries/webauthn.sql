@@ -0,0 +1,37 @@
+-- name: CreateWebAuthnCredential :one
+INSERT INTO webauthn_credentials (user_id, credential_id, public_key, sign_count, aaguid, transports, name, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, user_id, credential_id, public_key, sign_count, aaguid, transports, name, last_used_at, created_at;
+
+-- name: ListWebAuthnCredentialsByUser :many
+SELECT id, user_id, credential_id, public_key, sign_count, aaguid, transports, name, last_used_at, created_at
+FROM webauthn_credentials
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetWebAuthnCredentialByCredentialID :one
+SELECT id, user_id, credential_id, public_key, sign_count, aaguid, transports, name, last_used_at, created_at
+FROM webauthn_credentials
+WHERE credential_id = $1;
+
+-- name: UpdateWebAuthnCredentialSignCount :execrows
+UPDATE webauthn_credentials
+SET sign_count = @new_sign_count, last_used_at = @last_used_at
+WHERE id = @id AND sign_count = @old_sign_count;
+
+-- name: DeleteWebAuthnCredential :execrows
+DELETE FROM webauthn_credentials
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateWebAuthnChallenge :exec
+INSERT INTO webauthn_challenges (id, user_id, ceremony, challenge, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ConsumeWebAuthnChallenge :one
+DELETE FROM webauthn_challenges
+WHERE id = $1 AND ceremony = $2
+RETURNING id, user_id, ceremony, challenge, expires_at, created_at;
+
+-- name: DeleteExpiredWebAuthnChallenges :exec
+DELETE FROM webauthn_challenges
+WHERE expires_at < $1;
@@ -8,6 +8,14 @@ import (
 )
 
 func main() {
+	// Administrative commands, such as "keys rotate", run instead of the server
+	if len(os.Args) > 1 {
+		if err := server.RunCommand(os.Args[1:]); err != nil {
+			log.Fatalf("Command failed: %v", err)
+		}
+		return
+	}
+
 	app := server.NewApp()
 
 	if err := app.Initialize(); err != nil {